@@ -2,12 +2,14 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/andrescosta/goico/pkg/database"
 	"github.com/andrescosta/goico/pkg/env"
 	"github.com/andrescosta/goico/pkg/service"
 	"github.com/andrescosta/goico/pkg/service/grpc"
 	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/ctl/controller"
 	"github.com/andrescosta/jobico/internal/ctl/server"
 )
 
@@ -50,7 +52,11 @@ func New(ctx context.Context, ops ...Setter) (*Service, error) {
 		grpc.WithNewServiceFn(func(ctx context.Context) (any, error) {
 			dir := env.String("ctl.dir", "ctl")
 			dir = env.WorkdirPlus(dir, "db")
-			return server.New(ctx, dir, s.dbOption)
+			retention := controller.WorkflowRetention{
+				Retention: *env.Duration("ctl.workflow.retention", 24*time.Hour),
+				Interval:  *env.Duration("ctl.workflow.retention.interval", time.Minute),
+			}
+			return server.New(ctx, dir, s.dbOption, retention)
 		}),
 	)
 	if err != nil {
@@ -41,6 +41,11 @@ func New(ctx context.Context, ops ...Setter) (*Service, error) {
 	}
 	s.delay = *env.Duration("executor.delay", 0)
 	s.option.MaxProc = env.Int("executor.maxproc", 0)
+	s.option.Polling = env.Bool("executor.polling", false)
+	s.option.MaxInFlight = uint32(env.Int("executor.maxinflight", 0))
+	s.option.BatchSize = uint32(env.Int("executor.batchsize", 0))
+	s.option.WaitTime = *env.Duration("executor.wait.time", 0)
+	s.option.Workers = env.Int("executor.workers", 0)
 	executor, err := executor.New(ctx, s.dialer, s.option)
 	if err != nil {
 		return nil, err
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
 
 	"github.com/andrescosta/goico/pkg/context"
+	gservice "github.com/andrescosta/goico/pkg/service"
 	"github.com/andrescosta/jobico/cmd/queue/service"
 	_ "github.com/tprasadtp/go-autotune"
 )
@@ -11,6 +14,16 @@ import (
 func main() {
 	ctx, cancel := context.ForEndSignals()
 	defer cancel()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		ms, err := service.Migrate(ctx, gservice.DefaultGrpcDialer, os.Args[2:])
+		for _, m := range ms {
+			fmt.Printf("%s/%s: %d items (%d copied by a previous run)\n", m.Tenant, m.Queue, m.Items, m.Resumed)
+		}
+		if err != nil {
+			log.Panicf("error migrating the queues: %s", err)
+		}
+		return
+	}
 	svc, err := service.New(ctx)
 	if err != nil {
 		log.Panicf("error creating queue service: %s", err)
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/andrescosta/goico/pkg/env"
+	"github.com/andrescosta/goico/pkg/service"
+	"github.com/andrescosta/jobico/internal/queue/controller"
+)
+
+// Migrate copies the queues from a storage provider to another, while the service is stopped. The
+// providers are set by args:
+//
+//	-from file|log|pebble -to file|log|pebble [-from.dir dir] [-to.dir dir] [-state file]
+//
+// The rest of the configuration of both is read from the environment, as the service does.
+func Migrate(ctx context.Context, d service.GrpcDialer, args []string) ([]controller.Migrated, error) {
+	if _, _, err := env.Load(name); err != nil {
+		return nil, err
+	}
+	option, err := optionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	from := flags.String("from", "", "provider the queues are stored in: file, log or pebble")
+	to := flags.String("to", "", "provider the queues are copied to: file, log or pebble")
+	fromDir := flags.String("from.dir", option.Dir, "directory of the source")
+	toDir := flags.String("to.dir", option.Dir, "directory of the target")
+	state := flags.String("state", "", "file that records the progress (default [to.dir]/migration.json)")
+	// the --env: arguments are handled by env.Load.
+	fargs := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--env:") {
+			fargs = append(fargs, arg)
+		}
+	}
+	if err := flags.Parse(fargs); err != nil {
+		return nil, err
+	}
+	o := controller.MigrationOption{From: option, To: option, State: *state}
+	o.From.Dir, o.To.Dir = *fromDir, *toDir
+	if err := setProvider(&o.From, *from); err != nil {
+		return nil, err
+	}
+	if err := setProvider(&o.To, *to); err != nil {
+		return nil, err
+	}
+	return controller.Migrate(ctx, d, o)
+}
+
+func setProvider(o *controller.Option, provider string) error {
+	o.InMemory = false
+	o.Log = provider == "log"
+	o.Pebble = provider == "pebble"
+	switch provider {
+	case "file", "log", "pebble":
+		return nil
+	default:
+		return fmt.Errorf("unknown provider %q", provider)
+	}
+}
@@ -2,12 +2,17 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/andrescosta/goico/pkg/env"
 	"github.com/andrescosta/goico/pkg/service"
 	"github.com/andrescosta/goico/pkg/service/grpc"
 	pb "github.com/andrescosta/jobico/internal/api/types"
 	"github.com/andrescosta/jobico/internal/queue/controller"
+	"github.com/andrescosta/jobico/internal/queue/provider"
 	"github.com/andrescosta/jobico/internal/queue/server"
 )
 
@@ -18,6 +23,7 @@ type Setter func(*Service)
 type Service struct {
 	grpc.Container
 	option controller.Option
+	addr   string
 }
 
 func New(ctx context.Context, ops ...Setter) (*Service, error) {
@@ -35,14 +41,26 @@ func New(ctx context.Context, ops ...Setter) (*Service, error) {
 	if err != nil {
 		return nil, err
 	}
-	s.option.Dir = env.WorkdirPlus(env.String("queue.dir", "queue"))
+	option, err := optionFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	s.option = option
+	s.addr = s.Addr()
 	for _, op := range ops {
 		op(s)
 	}
+	if s.addr == "" {
+		s.addr = s.AddrOrPanic()
+	}
+	if s.option.Node == "" {
+		// the node is named by its address, unless it is listening on an address not known by the rest.
+		s.option.Node = env.String("queue.cluster.node", s.addr)
+	}
 	svc, err := grpc.New(
 		grpc.WithListener(s.Listener),
 		grpc.WithName(s.Name),
-		grpc.WithAddr(s.AddrOrPanic()),
+		grpc.WithAddr(s.addr),
 		grpc.WithContext(ctx),
 		grpc.WithServiceDesc(&pb.Queue_ServiceDesc),
 		grpc.WithProfilingEnabled(env.Bool("prof.enabled", false)),
@@ -59,6 +77,44 @@ func New(ctx context.Context, ops ...Setter) (*Service, error) {
 	return s, nil
 }
 
+// optionFromEnv returns the configuration of the controller set by the environment variables.
+func optionFromEnv() (controller.Option, error) {
+	option := controller.Option{}
+	option.Dir = env.WorkdirPlus(env.String("queue.dir", "queue"))
+	option.VisibilityTimeout = *env.Duration("queue.visibility.timeout", controller.DefaultVisibilityTimeout)
+	option.PriorityAging = *env.Duration("queue.priority.aging", controller.DefaultPriorityAging)
+	option.Log = env.Bool("queue.log", false)
+	option.Pebble = env.Bool("queue.pebble", false)
+	option.LogOption.SegmentSize = int64(env.Int("queue.log.segment.size", provider.DefaultSegmentSize))
+	option.LogOption.SyncInterval = *env.Duration("queue.log.sync.interval", 1*time.Second)
+	sync, err := provider.ParseSyncPolicy(env.String("queue.log.sync", "interval"))
+	if err != nil {
+		return controller.Option{}, err
+	}
+	option.LogOption.Sync = sync
+	option.Cluster = env.Bool("queue.cluster", false)
+	option.RebalanceInterval = *env.Duration("queue.cluster.rebalance.interval", controller.DefaultRebalanceInterval)
+	if replicas := env.String("queue.replication.replicas", ""); replicas != "" {
+		option.Replication.Replicas = strings.Split(replicas, ",")
+	}
+	option.Replication.Sync = env.Bool("queue.replication.sync", false)
+	option.Replication.Replica = env.Bool("queue.replication.replica", false)
+	option.Replication.FailoverTimeout = *env.Duration("queue.replication.failover.timeout", 0)
+	option.Replication.Heartbeat = *env.Duration("queue.replication.heartbeat", controller.DefaultHeartbeat)
+	option.Replication.SyncTimeout = *env.Duration("queue.replication.sync.timeout", controller.DefaultSyncTimeout)
+	option.DrainInterval = *env.Duration("queue.drain.interval", controller.DefaultDrainInterval)
+	option.ExpirationInterval = *env.Duration("queue.expiration.interval", controller.DefaultExpirationInterval)
+	option.RetryAfter = *env.Duration("queue.backpressure.retryafter", controller.DefaultRetryAfter)
+	option.Compression = env.Bool("queue.compression", false)
+	if option.Encryption.MasterKey, err = base64.StdEncoding.DecodeString(env.String("queue.encryption.key", "")); err != nil {
+		return controller.Option{}, fmt.Errorf("queue.encryption.key: %w", err)
+	}
+	if option.Encryption.PreviousKey, err = base64.StdEncoding.DecodeString(env.String("queue.encryption.key.previous", "")); err != nil {
+		return controller.Option{}, fmt.Errorf("queue.encryption.key.previous: %w", err)
+	}
+	return option, nil
+}
+
 func (s *Service) Start() error {
 	return s.Svc.Serve()
 }
@@ -77,6 +133,13 @@ func WithOption(o controller.Option) Setter {
 	}
 }
 
+// WithAddr sets the address the service listens on, instead of queue.addr.
+func WithAddr(addr string) Setter {
+	return func(s *Service) {
+		s.addr = addr
+	}
+}
+
 func WithGrpcConn(g service.GrpcConn) Setter {
 	return func(s *Service) {
 		s.Container.GrpcConn = g
@@ -152,6 +152,27 @@ func (c *Ctl) DeletePackage(ctx context.Context, pkg *pb.JobPackage) error {
 	return nil
 }
 
+// AdvanceWorkflow records that a step of a workflow instance completed and returns the steps to queue.
+func (c *Ctl) AdvanceWorkflow(ctx context.Context, in *pb.AdvanceWorkflowRequest) ([]*pb.StepStart, error) {
+	r, err := c.cli.AdvanceWorkflow(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return r.Starts, nil
+}
+
+func (c *Ctl) WorkflowInstance(ctx context.Context, tenant string, workflow string, instance string) (*pb.WorkflowInstance, error) {
+	r, err := c.cli.WorkflowInstance(ctx, &pb.WorkflowInstanceRequest{
+		Tenant:   tenant,
+		Workflow: workflow,
+		Instance: instance,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Instance, nil
+}
+
 func (c *Ctl) ListenerForEnvironmentUpdates(ctx context.Context) (*broadcaster.Listener[*pb.UpdateToEnvironmentStrReply], error) {
 	if c.bcEnvUpdates == nil {
 		if err := c.startListenEnvironmentUpdates(ctx); err != nil {
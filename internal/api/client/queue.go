@@ -2,52 +2,686 @@ package client
 
 import (
 	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/andrescosta/goico/pkg/env"
 	"github.com/andrescosta/goico/pkg/service"
 	pb "github.com/andrescosta/jobico/internal/api/types"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	rpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
+// Queue is a client of the queue service. If queue.cluster is set, the queues are spread across the nodes
+// declared by the queue service of the environment, and every request is routed to the node that owns
+// its queue. Otherwise, all the requests go to queue.host.
 type Queue struct {
-	addr string
+	ctx    context.Context
+	cancel context.CancelFunc
+	dialer service.GrpcDialer
+	addr   string
+	mu     sync.Mutex
+	nodes  map[string]*queueNode
+	// cluster is set when the requests are routed with ring, which is loaded on the first request.
+	cluster       bool
+	ctl           *Ctl
+	ring          *Ring
+	subscriptions map[*Subscription]struct{}
+}
+
+type queueNode struct {
 	conn *rpc.ClientConn
 	cli  pb.QueueClient
 }
 
+// receiptSeparator separates the node that leased an item from its receipt, so the acknowledgements of a
+// cluster reach that node even after the queue moved to another one.
+const receiptSeparator = "|"
+
 func NewQueue(ctx context.Context, d service.GrpcDialer) (*Queue, error) {
 	addr := env.String("queue.host")
-	conn, err := d.Dial(ctx, addr)
+	if !env.Bool("queue.cluster", false) {
+		return NewQueueForNode(ctx, d, addr)
+	}
+	ctl, err := NewCtl(ctx, d)
 	if err != nil {
 		return nil, err
 	}
-	cli := pb.NewQueueClient(conn)
+	c := newQueue(ctx, d, addr)
+	c.cluster = true
+	c.ctl = ctl
+	return c, nil
+}
+
+// NewQueueForNode returns a client that sends every request to the node at addr.
+func NewQueueForNode(ctx context.Context, d service.GrpcDialer, addr string) (*Queue, error) {
+	c := newQueue(ctx, d, addr)
+	if _, err := c.node(addr); err != nil {
+		c.cancel()
+		return nil, err
+	}
+	return c, nil
+}
+
+func newQueue(ctx context.Context, d service.GrpcDialer, addr string) *Queue {
+	ctx, cancel := context.WithCancel(ctx)
 	return &Queue{
-		addr: addr,
-		conn: conn,
-		cli:  cli,
-	}, nil
+		ctx:           ctx,
+		cancel:        cancel,
+		dialer:        d,
+		addr:          addr,
+		nodes:         make(map[string]*queueNode),
+		subscriptions: make(map[*Subscription]struct{}),
+	}
 }
 
 func (c *Queue) Close() error {
-	return c.conn.Close()
+	c.cancel()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var err error
+	for addr, n := range c.nodes {
+		err = errors.Join(err, n.conn.Close())
+		delete(c.nodes, addr)
+	}
+	if c.ctl != nil {
+		err = errors.Join(err, c.ctl.Close())
+	}
+	return err
+}
+
+// node returns the connection to a node, dialing it if needed. It must be called with mu held.
+func (c *Queue) node(addr string) (*queueNode, error) {
+	if n, ok := c.nodes[addr]; ok {
+		return n, nil
+	}
+	conn, err := c.dialer.Dial(c.ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	n := &queueNode{
+		conn: conn,
+		cli:  pb.NewQueueClient(conn),
+	}
+	c.nodes[addr] = n
+	return n, nil
+}
+
+// route returns the node that owns the queue and its address, which is empty if the client is not routing.
+func (c *Queue) route(ctx context.Context, tenant string, queue string) (*queueNode, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.cluster {
+		n, err := c.node(c.addr)
+		return n, "", err
+	}
+	if err := c.loadRing(ctx); err != nil {
+		return nil, "", err
+	}
+	addr := c.ring.Node(tenant, queue)
+	n, err := c.node(addr)
+	return n, addr, err
+}
+
+// all returns the addresses of every node. It is the queue.host if the client is not routing.
+func (c *Queue) all(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.cluster {
+		return []string{c.addr}, nil
+	}
+	if err := c.loadRing(ctx); err != nil {
+		return nil, err
+	}
+	return c.ring.Nodes(), nil
+}
+
+func (c *Queue) nodeFor(addr string) (*queueNode, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.node(addr)
+}
+
+// loadRing builds the ring from the environment and keeps it up to date. It must be called with mu held,
+// and it is retried by the next request if it fails.
+func (c *Queue) loadRing(ctx context.Context) error {
+	if c.ring != nil {
+		return nil
+	}
+	l, err := c.ctl.ListenerForEnvironmentUpdates(c.ctx)
+	if err != nil {
+		return err
+	}
+	environment, err := c.ctl.Environment(ctx)
+	if err != nil {
+		return err
+	}
+	c.setRing(QueueNodes(environment))
+	go func() {
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case u, ok := <-l.C:
+				if !ok {
+					return
+				}
+				c.mu.Lock()
+				c.setRing(QueueNodes(u.Object))
+				c.mu.Unlock()
+			}
+		}
+	}()
+	return nil
+}
+
+// setRing replaces the ring and ends the subscriptions to the queues that moved to another node, so they
+// are started again on the new one. It must be called with mu held.
+func (c *Queue) setRing(nodes []string) {
+	if len(nodes) == 0 {
+		// the environment does not declare the cluster yet.
+		nodes = []string{c.addr}
+	}
+	c.ring = NewRing(nodes)
+	for addr, n := range c.nodes {
+		if !slices.Contains(c.ring.Nodes(), addr) {
+			// the node is dialed again if it still has to receive acknowledgements.
+			_ = n.conn.Close()
+			delete(c.nodes, addr)
+		}
+	}
+	for s := range c.subscriptions {
+		if c.ring.Node(s.tenant, s.queue) != s.node {
+			s.cancel()
+			delete(c.subscriptions, s)
+		}
+	}
+}
+
+// byNode groups the receipts by the node that leased their items. The receipts that do not name a node are
+// sent to the owner of the queue.
+func (c *Queue) byNode(ctx context.Context, tenant string, queue string, receipts []string) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	if !c.cluster {
+		groups[c.addr] = receipts
+		return groups, nil
+	}
+	for _, r := range receipts {
+		addr, receipt, ok := strings.Cut(r, receiptSeparator)
+		if !ok {
+			_, owner, err := c.route(ctx, tenant, queue)
+			if err != nil {
+				return nil, err
+			}
+			addr, receipt = owner, r
+		}
+		groups[addr] = append(groups[addr], receipt)
+	}
+	return groups, nil
+}
+
+func nodeReceipt(addr string, receipt string) string {
+	if addr == "" {
+		return receipt
+	}
+	return addr + receiptSeparator + receipt
 }
 
-func (c *Queue) Dequeue(ctx context.Context, tenant string, queue string) ([]*pb.QueueItem, error) {
+func (c *Queue) Dequeue(ctx context.Context, tenant string, queue string) ([]*pb.DequeuedItem, error) {
+	return c.DequeueBatch(ctx, tenant, queue, 0, 0)
+}
+
+// DequeueBatch returns up to maxItems items, or the server limit if it is zero. If the queue is empty,
+// the server waits up to waitTime for items to arrive.
+func (c *Queue) DequeueBatch(ctx context.Context, tenant string, queue string, maxItems uint32, waitTime time.Duration) ([]*pb.DequeuedItem, error) {
+	n, addr, err := c.route(ctx, tenant, queue)
+	if err != nil {
+		return nil, err
+	}
 	request := pb.DequeueRequest{
 		Queue:  queue,
 		Tenant: tenant,
 	}
-	r, err := c.cli.Dequeue(ctx, &request)
+	if maxItems > 0 {
+		request.MaxItems = &maxItems
+	}
+	if waitTime > 0 {
+		request.WaitTime = durationpb.New(waitTime)
+	}
+	r, err := n.cli.Dequeue(ctx, &request)
 	if err != nil {
 		return nil, err
 	}
+	for _, item := range r.Items {
+		item.Receipt = nodeReceipt(addr, item.Receipt)
+	}
 	return r.Items, nil
 }
 
-func (c *Queue) Queue(ctx context.Context, queueRequest *pb.QueueRequest) error {
-	if _, err := c.cli.Queue(ctx, queueRequest); err != nil {
+// Subscription receives the items pushed by the queue. In a cluster, it ends when the queue moves to
+// another node.
+type Subscription struct {
+	stream pb.Queue_SubscribeClient
+	tenant string
+	queue  string
+	node   string
+	cancel context.CancelFunc
+}
+
+// Subscribe returns once the queue accepted the subscription. At most maxInFlight items are
+// pushed until they are acknowledged or rejected.
+func (c *Queue) Subscribe(ctx context.Context, tenant string, queue string, maxInFlight uint32) (*Subscription, error) {
+	n, addr, err := c.route(ctx, tenant, queue)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s, err := n.cli.Subscribe(ctx, &pb.SubscribeRequest{
+		Queue:       queue,
+		Tenant:      tenant,
+		MaxInFlight: maxInFlight,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	md, err := s.Header()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if md == nil {
+		defer cancel()
+		// the stream ended without headers, so Recv returns the reason.
+		if _, err := s.Recv(); err != nil {
+			return nil, err
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	sub := &Subscription{
+		stream: s,
+		tenant: tenant,
+		queue:  queue,
+		node:   addr,
+		cancel: cancel,
+	}
+	if c.cluster {
+		c.mu.Lock()
+		c.subscriptions[sub] = struct{}{}
+		c.mu.Unlock()
+		go func() {
+			<-ctx.Done()
+			c.mu.Lock()
+			delete(c.subscriptions, sub)
+			c.mu.Unlock()
+		}()
+	}
+	return sub, nil
+}
+
+func (s *Subscription) Recv() (*pb.DequeuedItem, error) {
+	item, err := s.stream.Recv()
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	item.Receipt = nodeReceipt(s.node, item.Receipt)
+	return item, nil
+}
+
+// Queue returns the IDs assigned to the items. A duplicate gets the ID of the item it duplicates.
+func (c *Queue) Queue(ctx context.Context, queueRequest *pb.QueueRequest) ([]string, error) {
+	n, _, err := c.route(ctx, queueRequest.Tenant, queueRequest.Queue)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.cli.Queue(ctx, queueRequest)
+	if err != nil {
+		return nil, err
+	}
+	return r.Ids, nil
+}
+
+// Backpressure reports whether the queue rejected the items because it reached its high watermark, and
+// how long the sender should wait before retrying. The wait is zero if the queue did not say.
+func Backpressure(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return 0, false
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			return info.RetryDelay.AsDuration(), true
+		}
+	}
+	return 0, true
+}
+
+func (c *Queue) Ack(ctx context.Context, tenant string, queue string, receipts ...string) error {
+	groups, err := c.byNode(ctx, tenant, queue, receipts)
+	if err != nil {
+		return err
+	}
+	for addr, rs := range groups {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return err
+		}
+		request := pb.AckRequest{
+			Queue:    queue,
+			Tenant:   tenant,
+			Receipts: rs,
+		}
+		if _, err := n.cli.Ack(ctx, &request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Queue) Nack(ctx context.Context, tenant string, queue string, reason string, receipts ...string) error {
+	return c.NackAfter(ctx, tenant, queue, reason, 0, receipts...)
+}
+
+// NackAfter releases the items once delay has elapsed. Until then they are not delivered, and neither are
+// the items that follow them with the same partition key.
+func (c *Queue) NackAfter(ctx context.Context, tenant string, queue string, reason string, delay time.Duration, receipts ...string) error {
+	groups, err := c.byNode(ctx, tenant, queue, receipts)
+	if err != nil {
 		return err
 	}
+	for addr, rs := range groups {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return err
+		}
+		request := pb.NackRequest{
+			Queue:    queue,
+			Tenant:   tenant,
+			Receipts: rs,
+			Reason:   &reason,
+		}
+		if delay > 0 {
+			request.Delay = durationpb.New(delay)
+		}
+		if _, err := n.cli.Nack(ctx, &request); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// DeadLetters returns up to limit dead-lettered items. In a cluster, the items stay in the dead-letter queue
+// of the node that moved them there, so every node is queried.
+func (c *Queue) DeadLetters(ctx context.Context, tenant string, queue string, limit uint32) ([]*pb.DeadLetter, error) {
+	addrs, err := c.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var items []*pb.DeadLetter
+	for _, addr := range addrs {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return nil, err
+		}
+		left := limit
+		if limit > 0 {
+			left = limit - uint32(len(items))
+		}
+		r, err := n.cli.DeadLetters(ctx, &pb.DeadLettersRequest{
+			Queue:  queue,
+			Tenant: tenant,
+			Limit:  &left,
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, r.Items...)
+		if limit > 0 && uint32(len(items)) >= limit {
+			break
+		}
+	}
+	return items, nil
+}
+
+func (c *Queue) RequeueDeadLetters(ctx context.Context, tenant string, queue string, limit *uint32) (uint32, error) {
+	addrs, err := c.all(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var requeued uint32
+	for _, addr := range addrs {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return requeued, err
+		}
+		var left *uint32
+		if limit != nil {
+			l := *limit - requeued
+			left = &l
+		}
+		r, err := n.cli.RequeueDeadLetters(ctx, &pb.RequeueDeadLettersRequest{
+			Queue:  queue,
+			Tenant: tenant,
+			Limit:  left,
+		})
+		if err != nil {
+			return requeued, err
+		}
+		requeued += r.Requeued
+		if limit != nil && requeued >= *limit {
+			break
+		}
+	}
+	return requeued, nil
+}
+
+func (c *Queue) PurgeDeadLetters(ctx context.Context, tenant string, queue string) (uint32, error) {
+	addrs, err := c.all(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var purged uint32
+	for _, addr := range addrs {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return purged, err
+		}
+		r, err := n.cli.PurgeDeadLetters(ctx, &pb.PurgeDeadLettersRequest{
+			Queue:  queue,
+			Tenant: tenant,
+		})
+		if err != nil {
+			return purged, err
+		}
+		purged += r.Purged
+	}
+	return purged, nil
+}
+
+// Stats returns the statistics of a queue, or of every queue of the tenant if queue is empty. In a cluster,
+// they add up the items of every node, because a queue that is moving has items in more than one.
+func (c *Queue) Stats(ctx context.Context, tenant string, queue string) ([]*pb.QueueStats, error) {
+	addrs, err := c.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var stats []*pb.QueueStats
+	byQueue := make(map[string]*pb.QueueStats)
+	for _, addr := range addrs {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return nil, err
+		}
+		r, err := n.cli.Stats(ctx, &pb.StatsRequest{
+			Queue:  queue,
+			Tenant: tenant,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, qs := range r.Queues {
+			total, ok := byQueue[qs.Queue]
+			if !ok {
+				byQueue[qs.Queue] = qs
+				stats = append(stats, qs)
+				continue
+			}
+			total.Depth += qs.Depth
+			total.Visible += qs.Visible
+			total.Delayed += qs.Delayed
+			total.Leased += qs.Leased
+			total.Bytes += qs.Bytes
+			total.Quarantined += qs.Quarantined
+			total.Backpressure = total.Backpressure || qs.Backpressure
+			if qs.OldestAge != nil && (total.OldestAge == nil || qs.OldestAge.AsDuration() > total.OldestAge.AsDuration()) {
+				total.OldestAge = qs.OldestAge
+			}
+		}
+	}
+	return stats, nil
+}
+
+func (c *Queue) Peek(ctx context.Context, tenant string, queue string, limit uint32) ([]*pb.PeekedItem, error) {
+	n, _, err := c.route(ctx, tenant, queue)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.cli.Peek(ctx, &pb.PeekRequest{
+		Queue:  queue,
+		Tenant: tenant,
+		Limit:  &limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Items, nil
+}
+
+// Purge removes every item of the queue, from every node in a cluster. confirm must be the name of the queue.
+func (c *Queue) Purge(ctx context.Context, tenant string, queue string, confirm string) (uint32, error) {
+	addrs, err := c.all(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var purged uint32
+	for _, addr := range addrs {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return purged, err
+		}
+		r, err := n.cli.Purge(ctx, &pb.PurgeRequest{
+			Queue:   queue,
+			Tenant:  tenant,
+			Confirm: confirm,
+		})
+		if err != nil {
+			return purged, err
+		}
+		purged += r.Purged
+	}
+	return purged, nil
+}
+
+// Quarantined returns the items of a queue that could not be decoded, from every node.
+func (c *Queue) Quarantined(ctx context.Context, tenant string, queue string) ([]*pb.QuarantinedItem, error) {
+	addrs, err := c.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var items []*pb.QuarantinedItem
+	for _, addr := range addrs {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return nil, err
+		}
+		r, err := n.cli.Quarantined(ctx, &pb.QuarantinedRequest{
+			Queue:  queue,
+			Tenant: tenant,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// the IDs carry the node, like the receipts, so the requests that follow reach it.
+		for _, item := range r.Items {
+			if c.cluster {
+				item.ID = nodeReceipt(addr, item.ID)
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// RequeueQuarantined queues a quarantined item again, replaced by item if it is not nil.
+func (c *Queue) RequeueQuarantined(ctx context.Context, tenant string, queue string, id string, item *pb.QueueItem) error {
+	groups, err := c.byNode(ctx, tenant, queue, []string{id})
+	if err != nil {
+		return err
+	}
+	for addr, ids := range groups {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return err
+		}
+		request := pb.RequeueQuarantinedRequest{
+			Queue:  queue,
+			Tenant: tenant,
+			ID:     ids[0],
+			Item:   item,
+		}
+		if _, err := n.cli.RequeueQuarantined(ctx, &request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Queue) DeleteQuarantined(ctx context.Context, tenant string, queue string, id string) error {
+	groups, err := c.byNode(ctx, tenant, queue, []string{id})
+	if err != nil {
+		return err
+	}
+	for addr, ids := range groups {
+		n, err := c.nodeFor(addr)
+		if err != nil {
+			return err
+		}
+		request := pb.DeleteQuarantinedRequest{
+			Queue:  queue,
+			Tenant: tenant,
+			ID:     ids[0],
+		}
+		if _, err := n.cli.DeleteQuarantined(ctx, &request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replicate sends changes of the primary to the replica at queue.host, or the node of the client.
+func (c *Queue) Replicate(ctx context.Context, in *pb.ReplicateRequest) error {
+	n, err := c.nodeFor(c.addr)
+	if err != nil {
+		return err
+	}
+	_, err = n.cli.Replicate(ctx, in)
+	return err
+}
+
+// Promote turns the replica at queue.host, or the node of the client, into the primary.
+func (c *Queue) Promote(ctx context.Context) error {
+	n, err := c.nodeFor(c.addr)
+	if err != nil {
+		return err
+	}
+	_, err = n.cli.Promote(ctx, &pb.Void{})
+	return err
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"fmt"
+	"hash/crc32"
+	"slices"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+)
+
+// QueueService is the ID of the environment service that lists the nodes of the queue cluster.
+const QueueService = "queue"
+
+// ringReplicas is the number of points of each node in the ring, so the queues are spread evenly.
+const ringReplicas = 128
+
+// Ring assigns the queues to the nodes of the cluster with consistent hashing, so when a node joins
+// or leaves only the queues of that node move.
+type Ring struct {
+	points []uint32
+	owners map[uint32]string
+	nodes  []string
+}
+
+func NewRing(nodes []string) *Ring {
+	r := &Ring{
+		owners: make(map[uint32]string),
+	}
+	for _, node := range nodes {
+		if slices.Contains(r.nodes, node) {
+			continue
+		}
+		r.nodes = append(r.nodes, node)
+		for i := 0; i < ringReplicas; i++ {
+			point := crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s#%d", node, i)))
+			if _, ok := r.owners[point]; ok {
+				continue
+			}
+			r.owners[point] = node
+			r.points = append(r.points, point)
+		}
+	}
+	slices.Sort(r.points)
+	slices.Sort(r.nodes)
+	return r
+}
+
+// Node returns the node that owns the queue, or an empty string if the ring has no nodes.
+func (r *Ring) Node(tenant string, queue string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := crc32.ChecksumIEEE([]byte(tenant + "/" + queue))
+	i, _ := slices.BinarySearch(r.points, h)
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// Nodes returns the nodes of the ring, sorted.
+func (r *Ring) Nodes() []string {
+	return r.nodes
+}
+
+// QueueNodes returns the addresses of the servers of the queue service declared by the environment.
+func QueueNodes(environment *pb.Environment) []string {
+	var nodes []string
+	for _, s := range environment.GetServices() {
+		if s.ID != QueueService {
+			continue
+		}
+		for _, h := range s.Servers {
+			nodes = append(nodes, fmt.Sprintf("%s:%d", h.Ip, h.Port))
+		}
+	}
+	return nodes
+}
@@ -9,6 +9,7 @@ package types
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -195,6 +196,212 @@ func (DataType) EnumDescriptor() ([]byte, []int) {
 	return file_control_proto_rawDescGZIP(), []int{3}
 }
 
+// What happens to the items of the queue when its package is deleted.
+type QueueDef_Lifecycle int32
+
+const (
+	// The items are written to a tarball, and the storage of the queue is deleted.
+	QueueDef_Archive QueueDef_Lifecycle = 0
+	// The queue rejects new items, and its storage is deleted once the consumers empty it.
+	QueueDef_Drain QueueDef_Lifecycle = 1
+	// The storage of the queue is deleted.
+	QueueDef_Delete QueueDef_Lifecycle = 2
+)
+
+// Enum value maps for QueueDef_Lifecycle.
+var (
+	QueueDef_Lifecycle_name = map[int32]string{
+		0: "Archive",
+		1: "Drain",
+		2: "Delete",
+	}
+	QueueDef_Lifecycle_value = map[string]int32{
+		"Archive": 0,
+		"Drain":   1,
+		"Delete":  2,
+	}
+)
+
+func (x QueueDef_Lifecycle) Enum() *QueueDef_Lifecycle {
+	p := new(QueueDef_Lifecycle)
+	*p = x
+	return p
+}
+
+func (x QueueDef_Lifecycle) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QueueDef_Lifecycle) Descriptor() protoreflect.EnumDescriptor {
+	return file_control_proto_enumTypes[4].Descriptor()
+}
+
+func (QueueDef_Lifecycle) Type() protoreflect.EnumType {
+	return &file_control_proto_enumTypes[4]
+}
+
+func (x QueueDef_Lifecycle) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QueueDef_Lifecycle.Descriptor instead.
+func (QueueDef_Lifecycle) EnumDescriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{27, 0}
+}
+
+// What happens when new items exceed maxLength or maxBytes.
+type QueueDef_Overflow int32
+
+const (
+	// The new items are rejected.
+	QueueDef_Reject QueueDef_Overflow = 0
+	// The next items to be delivered are dropped to make room, which are the oldest ones when the queue
+	// has no priorities. The new items are rejected if the room cannot be made.
+	QueueDef_DropOldest QueueDef_Overflow = 1
+)
+
+// Enum value maps for QueueDef_Overflow.
+var (
+	QueueDef_Overflow_name = map[int32]string{
+		0: "Reject",
+		1: "DropOldest",
+	}
+	QueueDef_Overflow_value = map[string]int32{
+		"Reject":     0,
+		"DropOldest": 1,
+	}
+)
+
+func (x QueueDef_Overflow) Enum() *QueueDef_Overflow {
+	p := new(QueueDef_Overflow)
+	*p = x
+	return p
+}
+
+func (x QueueDef_Overflow) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QueueDef_Overflow) Descriptor() protoreflect.EnumDescriptor {
+	return file_control_proto_enumTypes[5].Descriptor()
+}
+
+func (QueueDef_Overflow) Type() protoreflect.EnumType {
+	return &file_control_proto_enumTypes[5]
+}
+
+func (x QueueDef_Overflow) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QueueDef_Overflow.Descriptor instead.
+func (QueueDef_Overflow) EnumDescriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{27, 1}
+}
+
+type WorkflowInstance_Status int32
+
+const (
+	WorkflowInstance_Running WorkflowInstance_Status = 0
+	// No step is running. Joins missing a branch that was not routed are not run.
+	WorkflowInstance_Completed WorkflowInstance_Status = 1
+)
+
+// Enum value maps for WorkflowInstance_Status.
+var (
+	WorkflowInstance_Status_name = map[int32]string{
+		0: "Running",
+		1: "Completed",
+	}
+	WorkflowInstance_Status_value = map[string]int32{
+		"Running":   0,
+		"Completed": 1,
+	}
+)
+
+func (x WorkflowInstance_Status) Enum() *WorkflowInstance_Status {
+	p := new(WorkflowInstance_Status)
+	*p = x
+	return p
+}
+
+func (x WorkflowInstance_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WorkflowInstance_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_control_proto_enumTypes[6].Descriptor()
+}
+
+func (WorkflowInstance_Status) Type() protoreflect.EnumType {
+	return &file_control_proto_enumTypes[6]
+}
+
+func (x WorkflowInstance_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WorkflowInstance_Status.Descriptor instead.
+func (WorkflowInstance_Status) EnumDescriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{35, 0}
+}
+
+type ResultDef_Payload int32
+
+const (
+	// JobResult with the code returned by the Jobicolet.
+	ResultDef_Code ResultDef_Payload = 0
+	// String returned by the Jobicolet.
+	ResultDef_Result ResultDef_Payload = 1
+	// Data of the event that was processed.
+	ResultDef_Input ResultDef_Payload = 2
+	// JSON object with the result, the input and the metadata of the execution.
+	ResultDef_Envelope ResultDef_Payload = 3
+)
+
+// Enum value maps for ResultDef_Payload.
+var (
+	ResultDef_Payload_name = map[int32]string{
+		0: "Code",
+		1: "Result",
+		2: "Input",
+		3: "Envelope",
+	}
+	ResultDef_Payload_value = map[string]int32{
+		"Code":     0,
+		"Result":   1,
+		"Input":    2,
+		"Envelope": 3,
+	}
+)
+
+func (x ResultDef_Payload) Enum() *ResultDef_Payload {
+	p := new(ResultDef_Payload)
+	*p = x
+	return p
+}
+
+func (x ResultDef_Payload) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResultDef_Payload) Descriptor() protoreflect.EnumDescriptor {
+	return file_control_proto_enumTypes[7].Descriptor()
+}
+
+func (ResultDef_Payload) Type() protoreflect.EnumType {
+	return &file_control_proto_enumTypes[7]
+}
+
+func (x ResultDef_Payload) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResultDef_Payload.Descriptor instead.
+func (ResultDef_Payload) EnumDescriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{38, 0}
+}
+
 type UpdateToEnvironmentStrReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -689,16 +896,25 @@ func (x *DeletePackageRequest) GetPackage() *JobPackage {
 	return nil
 }
 
-type EnvironmentReply struct {
+// Records that a step of a workflow instance completed. It can be sent again for the same step, and the
+// same steps are returned.
+type AdvanceWorkflowRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Environment *Environment `protobuf:"bytes,1,opt,name=environment,proto3,oneof" json:"environment,omitempty"`
+	Tenant   string `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Package  string `protobuf:"bytes,2,opt,name=package,proto3" json:"package,omitempty"`
+	Workflow string `protobuf:"bytes,3,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	// ID of the item of the first step.
+	Instance string `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"`
+	Step     string `protobuf:"bytes,5,opt,name=step,proto3" json:"step,omitempty"`
+	Code     uint64 `protobuf:"varint,6,opt,name=code,proto3" json:"code,omitempty"`
+	Result   string `protobuf:"bytes,7,opt,name=result,proto3" json:"result,omitempty"`
 }
 
-func (x *EnvironmentReply) Reset() {
-	*x = EnvironmentReply{}
+func (x *AdvanceWorkflowRequest) Reset() {
+	*x = AdvanceWorkflowRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_control_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -706,13 +922,13 @@ func (x *EnvironmentReply) Reset() {
 	}
 }
 
-func (x *EnvironmentReply) String() string {
+func (x *AdvanceWorkflowRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EnvironmentReply) ProtoMessage() {}
+func (*AdvanceWorkflowRequest) ProtoMessage() {}
 
-func (x *EnvironmentReply) ProtoReflect() protoreflect.Message {
+func (x *AdvanceWorkflowRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_control_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -724,28 +940,71 @@ func (x *EnvironmentReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EnvironmentReply.ProtoReflect.Descriptor instead.
-func (*EnvironmentReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use AdvanceWorkflowRequest.ProtoReflect.Descriptor instead.
+func (*AdvanceWorkflowRequest) Descriptor() ([]byte, []int) {
 	return file_control_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *EnvironmentReply) GetEnvironment() *Environment {
+func (x *AdvanceWorkflowRequest) GetTenant() string {
 	if x != nil {
-		return x.Environment
+		return x.Tenant
 	}
-	return nil
+	return ""
 }
 
-type AddEnvironmentRequest struct {
+func (x *AdvanceWorkflowRequest) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *AdvanceWorkflowRequest) GetWorkflow() string {
+	if x != nil {
+		return x.Workflow
+	}
+	return ""
+}
+
+func (x *AdvanceWorkflowRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *AdvanceWorkflowRequest) GetStep() string {
+	if x != nil {
+		return x.Step
+	}
+	return ""
+}
+
+func (x *AdvanceWorkflowRequest) GetCode() uint64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *AdvanceWorkflowRequest) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+type AdvanceWorkflowReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Environment *Environment `protobuf:"bytes,1,opt,name=environment,proto3" json:"environment,omitempty"`
+	// Steps to queue.
+	Starts []*StepStart `protobuf:"bytes,1,rep,name=starts,proto3" json:"starts,omitempty"`
 }
 
-func (x *AddEnvironmentRequest) Reset() {
-	*x = AddEnvironmentRequest{}
+func (x *AdvanceWorkflowReply) Reset() {
+	*x = AdvanceWorkflowReply{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_control_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -753,13 +1012,13 @@ func (x *AddEnvironmentRequest) Reset() {
 	}
 }
 
-func (x *AddEnvironmentRequest) String() string {
+func (x *AdvanceWorkflowReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddEnvironmentRequest) ProtoMessage() {}
+func (*AdvanceWorkflowReply) ProtoMessage() {}
 
-func (x *AddEnvironmentRequest) ProtoReflect() protoreflect.Message {
+func (x *AdvanceWorkflowReply) ProtoReflect() protoreflect.Message {
 	mi := &file_control_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -771,28 +1030,30 @@ func (x *AddEnvironmentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddEnvironmentRequest.ProtoReflect.Descriptor instead.
-func (*AddEnvironmentRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AdvanceWorkflowReply.ProtoReflect.Descriptor instead.
+func (*AdvanceWorkflowReply) Descriptor() ([]byte, []int) {
 	return file_control_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *AddEnvironmentRequest) GetEnvironment() *Environment {
+func (x *AdvanceWorkflowReply) GetStarts() []*StepStart {
 	if x != nil {
-		return x.Environment
+		return x.Starts
 	}
 	return nil
 }
 
-type UpdateEnvironmentRequest struct {
+type WorkflowInstanceRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Environment *Environment `protobuf:"bytes,1,opt,name=environment,proto3" json:"environment,omitempty"`
+	Tenant   string `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Workflow string `protobuf:"bytes,2,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	Instance string `protobuf:"bytes,3,opt,name=instance,proto3" json:"instance,omitempty"`
 }
 
-func (x *UpdateEnvironmentRequest) Reset() {
-	*x = UpdateEnvironmentRequest{}
+func (x *WorkflowInstanceRequest) Reset() {
+	*x = WorkflowInstanceRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_control_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -800,13 +1061,13 @@ func (x *UpdateEnvironmentRequest) Reset() {
 	}
 }
 
-func (x *UpdateEnvironmentRequest) String() string {
+func (x *WorkflowInstanceRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateEnvironmentRequest) ProtoMessage() {}
+func (*WorkflowInstanceRequest) ProtoMessage() {}
 
-func (x *UpdateEnvironmentRequest) ProtoReflect() protoreflect.Message {
+func (x *WorkflowInstanceRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_control_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -818,28 +1079,42 @@ func (x *UpdateEnvironmentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateEnvironmentRequest.ProtoReflect.Descriptor instead.
-func (*UpdateEnvironmentRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use WorkflowInstanceRequest.ProtoReflect.Descriptor instead.
+func (*WorkflowInstanceRequest) Descriptor() ([]byte, []int) {
 	return file_control_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *UpdateEnvironmentRequest) GetEnvironment() *Environment {
+func (x *WorkflowInstanceRequest) GetTenant() string {
 	if x != nil {
-		return x.Environment
+		return x.Tenant
 	}
-	return nil
+	return ""
 }
 
-type AddEnvironmentReply struct {
+func (x *WorkflowInstanceRequest) GetWorkflow() string {
+	if x != nil {
+		return x.Workflow
+	}
+	return ""
+}
+
+func (x *WorkflowInstanceRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+type WorkflowInstanceReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Environment *Environment `protobuf:"bytes,1,opt,name=environment,proto3" json:"environment,omitempty"`
+	Instance *WorkflowInstance `protobuf:"bytes,1,opt,name=instance,proto3,oneof" json:"instance,omitempty"`
 }
 
-func (x *AddEnvironmentReply) Reset() {
-	*x = AddEnvironmentReply{}
+func (x *WorkflowInstanceReply) Reset() {
+	*x = WorkflowInstanceReply{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_control_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -847,13 +1122,13 @@ func (x *AddEnvironmentReply) Reset() {
 	}
 }
 
-func (x *AddEnvironmentReply) String() string {
+func (x *WorkflowInstanceReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddEnvironmentReply) ProtoMessage() {}
+func (*WorkflowInstanceReply) ProtoMessage() {}
 
-func (x *AddEnvironmentReply) ProtoReflect() protoreflect.Message {
+func (x *WorkflowInstanceReply) ProtoReflect() protoreflect.Message {
 	mi := &file_control_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -865,29 +1140,28 @@ func (x *AddEnvironmentReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddEnvironmentReply.ProtoReflect.Descriptor instead.
-func (*AddEnvironmentReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use WorkflowInstanceReply.ProtoReflect.Descriptor instead.
+func (*WorkflowInstanceReply) Descriptor() ([]byte, []int) {
 	return file_control_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *AddEnvironmentReply) GetEnvironment() *Environment {
+func (x *WorkflowInstanceReply) GetInstance() *WorkflowInstance {
 	if x != nil {
-		return x.Environment
+		return x.Instance
 	}
 	return nil
 }
 
-type TenantsRequest struct {
+type EnvironmentReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ID     *string `protobuf:"bytes,1,opt,name=ID,proto3,oneof" json:"ID,omitempty"`
-	Tenant *string `protobuf:"bytes,2,opt,name=tenant,proto3,oneof" json:"tenant,omitempty"`
+	Environment *Environment `protobuf:"bytes,1,opt,name=environment,proto3,oneof" json:"environment,omitempty"`
 }
 
-func (x *TenantsRequest) Reset() {
-	*x = TenantsRequest{}
+func (x *EnvironmentReply) Reset() {
+	*x = EnvironmentReply{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_control_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -895,13 +1169,13 @@ func (x *TenantsRequest) Reset() {
 	}
 }
 
-func (x *TenantsRequest) String() string {
+func (x *EnvironmentReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TenantsRequest) ProtoMessage() {}
+func (*EnvironmentReply) ProtoMessage() {}
 
-func (x *TenantsRequest) ProtoReflect() protoreflect.Message {
+func (x *EnvironmentReply) ProtoReflect() protoreflect.Message {
 	mi := &file_control_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -913,35 +1187,28 @@ func (x *TenantsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TenantsRequest.ProtoReflect.Descriptor instead.
-func (*TenantsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use EnvironmentReply.ProtoReflect.Descriptor instead.
+func (*EnvironmentReply) Descriptor() ([]byte, []int) {
 	return file_control_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *TenantsRequest) GetID() string {
-	if x != nil && x.ID != nil {
-		return *x.ID
-	}
-	return ""
-}
-
-func (x *TenantsRequest) GetTenant() string {
-	if x != nil && x.Tenant != nil {
-		return *x.Tenant
+func (x *EnvironmentReply) GetEnvironment() *Environment {
+	if x != nil {
+		return x.Environment
 	}
-	return ""
+	return nil
 }
 
-type TenantsReply struct {
+type AddEnvironmentRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Tenants []*Tenant `protobuf:"bytes,1,rep,name=Tenants,proto3" json:"Tenants,omitempty"`
+	Environment *Environment `protobuf:"bytes,1,opt,name=environment,proto3" json:"environment,omitempty"`
 }
 
-func (x *TenantsReply) Reset() {
-	*x = TenantsReply{}
+func (x *AddEnvironmentRequest) Reset() {
+	*x = AddEnvironmentRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_control_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -949,13 +1216,13 @@ func (x *TenantsReply) Reset() {
 	}
 }
 
-func (x *TenantsReply) String() string {
+func (x *AddEnvironmentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TenantsReply) ProtoMessage() {}
+func (*AddEnvironmentRequest) ProtoMessage() {}
 
-func (x *TenantsReply) ProtoReflect() protoreflect.Message {
+func (x *AddEnvironmentRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_control_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -967,28 +1234,28 @@ func (x *TenantsReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TenantsReply.ProtoReflect.Descriptor instead.
-func (*TenantsReply) Descriptor() ([]byte, []int) {
+// Deprecated: Use AddEnvironmentRequest.ProtoReflect.Descriptor instead.
+func (*AddEnvironmentRequest) Descriptor() ([]byte, []int) {
 	return file_control_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *TenantsReply) GetTenants() []*Tenant {
+func (x *AddEnvironmentRequest) GetEnvironment() *Environment {
 	if x != nil {
-		return x.Tenants
+		return x.Environment
 	}
 	return nil
 }
 
-type AddTenantRequest struct {
+type UpdateEnvironmentRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Tenant *Tenant `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Environment *Environment `protobuf:"bytes,1,opt,name=environment,proto3" json:"environment,omitempty"`
 }
 
-func (x *AddTenantRequest) Reset() {
-	*x = AddTenantRequest{}
+func (x *UpdateEnvironmentRequest) Reset() {
+	*x = UpdateEnvironmentRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_control_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -996,14 +1263,210 @@ func (x *AddTenantRequest) Reset() {
 	}
 }
 
-func (x *AddTenantRequest) String() string {
+func (x *UpdateEnvironmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEnvironmentRequest) ProtoMessage() {}
+
+func (x *UpdateEnvironmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEnvironmentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEnvironmentRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UpdateEnvironmentRequest) GetEnvironment() *Environment {
+	if x != nil {
+		return x.Environment
+	}
+	return nil
+}
+
+type AddEnvironmentReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Environment *Environment `protobuf:"bytes,1,opt,name=environment,proto3" json:"environment,omitempty"`
+}
+
+func (x *AddEnvironmentReply) Reset() {
+	*x = AddEnvironmentReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddEnvironmentReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddEnvironmentReply) ProtoMessage() {}
+
+func (x *AddEnvironmentReply) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddEnvironmentReply.ProtoReflect.Descriptor instead.
+func (*AddEnvironmentReply) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AddEnvironmentReply) GetEnvironment() *Environment {
+	if x != nil {
+		return x.Environment
+	}
+	return nil
+}
+
+type TenantsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID     *string `protobuf:"bytes,1,opt,name=ID,proto3,oneof" json:"ID,omitempty"`
+	Tenant *string `protobuf:"bytes,2,opt,name=tenant,proto3,oneof" json:"tenant,omitempty"`
+}
+
+func (x *TenantsRequest) Reset() {
+	*x = TenantsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TenantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TenantsRequest) ProtoMessage() {}
+
+func (x *TenantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TenantsRequest.ProtoReflect.Descriptor instead.
+func (*TenantsRequest) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TenantsRequest) GetID() string {
+	if x != nil && x.ID != nil {
+		return *x.ID
+	}
+	return ""
+}
+
+func (x *TenantsRequest) GetTenant() string {
+	if x != nil && x.Tenant != nil {
+		return *x.Tenant
+	}
+	return ""
+}
+
+type TenantsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tenants []*Tenant `protobuf:"bytes,1,rep,name=Tenants,proto3" json:"Tenants,omitempty"`
+}
+
+func (x *TenantsReply) Reset() {
+	*x = TenantsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TenantsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TenantsReply) ProtoMessage() {}
+
+func (x *TenantsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TenantsReply.ProtoReflect.Descriptor instead.
+func (*TenantsReply) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TenantsReply) GetTenants() []*Tenant {
+	if x != nil {
+		return x.Tenants
+	}
+	return nil
+}
+
+type AddTenantRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tenant *Tenant `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
+}
+
+func (x *AddTenantRequest) Reset() {
+	*x = AddTenantRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddTenantRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
 func (*AddTenantRequest) ProtoMessage() {}
 
 func (x *AddTenantRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[16]
+	mi := &file_control_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1016,7 +1479,7 @@ func (x *AddTenantRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddTenantRequest.ProtoReflect.Descriptor instead.
 func (*AddTenantRequest) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{16}
+	return file_control_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *AddTenantRequest) GetTenant() *Tenant {
@@ -1037,7 +1500,7 @@ type AddTenantReply struct {
 func (x *AddTenantReply) Reset() {
 	*x = AddTenantReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[17]
+		mi := &file_control_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1050,7 +1513,7 @@ func (x *AddTenantReply) String() string {
 func (*AddTenantReply) ProtoMessage() {}
 
 func (x *AddTenantReply) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[17]
+	mi := &file_control_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1063,7 +1526,7 @@ func (x *AddTenantReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddTenantReply.ProtoReflect.Descriptor instead.
 func (*AddTenantReply) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{17}
+	return file_control_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *AddTenantReply) GetTenant() *Tenant {
@@ -1086,7 +1549,7 @@ type Environment struct {
 func (x *Environment) Reset() {
 	*x = Environment{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[18]
+		mi := &file_control_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1099,7 +1562,7 @@ func (x *Environment) String() string {
 func (*Environment) ProtoMessage() {}
 
 func (x *Environment) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[18]
+	mi := &file_control_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1112,7 +1575,7 @@ func (x *Environment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Environment.ProtoReflect.Descriptor instead.
 func (*Environment) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{18}
+	return file_control_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *Environment) GetID() string {
@@ -1150,7 +1613,7 @@ type Service struct {
 func (x *Service) Reset() {
 	*x = Service{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[19]
+		mi := &file_control_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1163,7 +1626,7 @@ func (x *Service) String() string {
 func (*Service) ProtoMessage() {}
 
 func (x *Service) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[19]
+	mi := &file_control_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1176,7 +1639,7 @@ func (x *Service) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Service.ProtoReflect.Descriptor instead.
 func (*Service) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{19}
+	return file_control_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *Service) GetID() string {
@@ -1221,7 +1684,7 @@ type Storage struct {
 func (x *Storage) Reset() {
 	*x = Storage{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[20]
+		mi := &file_control_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1234,7 +1697,7 @@ func (x *Storage) String() string {
 func (*Storage) ProtoMessage() {}
 
 func (x *Storage) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[20]
+	mi := &file_control_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1247,7 +1710,7 @@ func (x *Storage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Storage.ProtoReflect.Descriptor instead.
 func (*Storage) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{20}
+	return file_control_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *Storage) GetID() string {
@@ -1283,18 +1746,19 @@ type JobPackage struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ID       string        `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Name     *string       `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
-	Tenant   string        `protobuf:"bytes,3,opt,name=tenant,proto3" json:"tenant,omitempty"`
-	Queues   []*QueueDef   `protobuf:"bytes,4,rep,name=queues,proto3" json:"queues,omitempty"`
-	Jobs     []*JobDef     `protobuf:"bytes,5,rep,name=jobs,proto3" json:"jobs,omitempty"`
-	Runtimes []*RuntimeDef `protobuf:"bytes,6,rep,name=runtimes,proto3" json:"runtimes,omitempty"`
+	ID        string         `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name      *string        `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Tenant    string         `protobuf:"bytes,3,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Queues    []*QueueDef    `protobuf:"bytes,4,rep,name=queues,proto3" json:"queues,omitempty"`
+	Jobs      []*JobDef      `protobuf:"bytes,5,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	Runtimes  []*RuntimeDef  `protobuf:"bytes,6,rep,name=runtimes,proto3" json:"runtimes,omitempty"`
+	Workflows []*WorkflowDef `protobuf:"bytes,7,rep,name=workflows,proto3" json:"workflows,omitempty"`
 }
 
 func (x *JobPackage) Reset() {
 	*x = JobPackage{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[21]
+		mi := &file_control_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1307,7 +1771,7 @@ func (x *JobPackage) String() string {
 func (*JobPackage) ProtoMessage() {}
 
 func (x *JobPackage) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[21]
+	mi := &file_control_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1320,7 +1784,7 @@ func (x *JobPackage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JobPackage.ProtoReflect.Descriptor instead.
 func (*JobPackage) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{21}
+	return file_control_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *JobPackage) GetID() string {
@@ -1365,6 +1829,13 @@ func (x *JobPackage) GetRuntimes() []*RuntimeDef {
 	return nil
 }
 
+func (x *JobPackage) GetWorkflows() []*WorkflowDef {
+	if x != nil {
+		return x.Workflows
+	}
+	return nil
+}
+
 type Tenant struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1377,7 +1848,7 @@ type Tenant struct {
 func (x *Tenant) Reset() {
 	*x = Tenant{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[22]
+		mi := &file_control_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1390,7 +1861,7 @@ func (x *Tenant) String() string {
 func (*Tenant) ProtoMessage() {}
 
 func (x *Tenant) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[22]
+	mi := &file_control_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1403,7 +1874,7 @@ func (x *Tenant) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tenant.ProtoReflect.Descriptor instead.
 func (*Tenant) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{22}
+	return file_control_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *Tenant) GetID() string {
@@ -1427,12 +1898,37 @@ type QueueDef struct {
 
 	ID   string  `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
 	Name *string `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	// Number of deliveries after which an item is moved to the dead-letter queue. Zero means unlimited.
+	MaxAttempts *uint32 `protobuf:"varint,3,opt,name=maxAttempts,proto3,oneof" json:"maxAttempts,omitempty"`
+	// ID of a queue of the same package that receives the items that exceeded maxAttempts.
+	DeadLetterQueue *string `protobuf:"bytes,4,opt,name=deadLetterQueue,proto3,oneof" json:"deadLetterQueue,omitempty"`
+	// Number of priority levels served by the queue, from 0 (the lowest) to priorityLevels-1. Zero or one means no priorities.
+	PriorityLevels *uint32 `protobuf:"varint,5,opt,name=priorityLevels,proto3,oneof" json:"priorityLevels,omitempty"`
+	// Number of items the executor dequeues, or holds from its subscription, at once. The executor default is used when empty.
+	BatchSize *uint32 `protobuf:"varint,6,opt,name=batchSize,proto3,oneof" json:"batchSize,omitempty"`
+	// Seconds during which an item with the dedupKey of a previous one is dropped. Zero disables the deduplication.
+	DedupWindowSeconds *uint32             `protobuf:"varint,7,opt,name=dedupWindowSeconds,proto3,oneof" json:"dedupWindowSeconds,omitempty"`
+	OnDelete           *QueueDef_Lifecycle `protobuf:"varint,8,opt,name=onDelete,proto3,enum=QueueDef_Lifecycle,oneof" json:"onDelete,omitempty"`
+	// Maximum number of items of the queue, either visible, delayed or leased. Zero means unlimited.
+	MaxLength *uint32 `protobuf:"varint,9,opt,name=maxLength,proto3,oneof" json:"maxLength,omitempty"`
+	// Maximum size in bytes of the items of the queue. Zero means unlimited.
+	MaxBytes *uint64 `protobuf:"varint,10,opt,name=maxBytes,proto3,oneof" json:"maxBytes,omitempty"`
+	// Seconds after which an item that was not delivered is removed from the queue. Zero means never.
+	MessageTTLSeconds *uint32            `protobuf:"varint,11,opt,name=messageTTLSeconds,proto3,oneof" json:"messageTTLSeconds,omitempty"`
+	Overflow          *QueueDef_Overflow `protobuf:"varint,12,opt,name=overflow,proto3,enum=QueueDef_Overflow,oneof" json:"overflow,omitempty"`
+	// Moves the expired items to the dead-letter queue instead of dropping them.
+	DeadLetterExpired *bool `protobuf:"varint,13,opt,name=deadLetterExpired,proto3,oneof" json:"deadLetterExpired,omitempty"`
+	// Depth above which the queue rejects new items with RESOURCE_EXHAUSTED. Zero means never.
+	HighWatermark *uint32 `protobuf:"varint,14,opt,name=highWatermark,proto3,oneof" json:"highWatermark,omitempty"`
+	// Depth the queue must drain to before it accepts new items again, once it reached highWatermark.
+	// It is highWatermark when empty.
+	LowWatermark *uint32 `protobuf:"varint,15,opt,name=lowWatermark,proto3,oneof" json:"lowWatermark,omitempty"`
 }
 
 func (x *QueueDef) Reset() {
 	*x = QueueDef{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[23]
+		mi := &file_control_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1445,7 +1941,7 @@ func (x *QueueDef) String() string {
 func (*QueueDef) ProtoMessage() {}
 
 func (x *QueueDef) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[23]
+	mi := &file_control_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1458,53 +1954,779 @@ func (x *QueueDef) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueueDef.ProtoReflect.Descriptor instead.
 func (*QueueDef) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{23}
+	return file_control_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *QueueDef) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+func (x *QueueDef) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *QueueDef) GetMaxAttempts() uint32 {
+	if x != nil && x.MaxAttempts != nil {
+		return *x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *QueueDef) GetDeadLetterQueue() string {
+	if x != nil && x.DeadLetterQueue != nil {
+		return *x.DeadLetterQueue
+	}
+	return ""
+}
+
+func (x *QueueDef) GetPriorityLevels() uint32 {
+	if x != nil && x.PriorityLevels != nil {
+		return *x.PriorityLevels
+	}
+	return 0
+}
+
+func (x *QueueDef) GetBatchSize() uint32 {
+	if x != nil && x.BatchSize != nil {
+		return *x.BatchSize
+	}
+	return 0
+}
+
+func (x *QueueDef) GetDedupWindowSeconds() uint32 {
+	if x != nil && x.DedupWindowSeconds != nil {
+		return *x.DedupWindowSeconds
+	}
+	return 0
+}
+
+func (x *QueueDef) GetOnDelete() QueueDef_Lifecycle {
+	if x != nil && x.OnDelete != nil {
+		return *x.OnDelete
+	}
+	return QueueDef_Archive
+}
+
+func (x *QueueDef) GetMaxLength() uint32 {
+	if x != nil && x.MaxLength != nil {
+		return *x.MaxLength
+	}
+	return 0
+}
+
+func (x *QueueDef) GetMaxBytes() uint64 {
+	if x != nil && x.MaxBytes != nil {
+		return *x.MaxBytes
+	}
+	return 0
+}
+
+func (x *QueueDef) GetMessageTTLSeconds() uint32 {
+	if x != nil && x.MessageTTLSeconds != nil {
+		return *x.MessageTTLSeconds
+	}
+	return 0
+}
+
+func (x *QueueDef) GetOverflow() QueueDef_Overflow {
+	if x != nil && x.Overflow != nil {
+		return *x.Overflow
+	}
+	return QueueDef_Reject
+}
+
+func (x *QueueDef) GetDeadLetterExpired() bool {
+	if x != nil && x.DeadLetterExpired != nil {
+		return *x.DeadLetterExpired
+	}
+	return false
+}
+
+func (x *QueueDef) GetHighWatermark() uint32 {
+	if x != nil && x.HighWatermark != nil {
+		return *x.HighWatermark
+	}
+	return 0
+}
+
+func (x *QueueDef) GetLowWatermark() uint32 {
+	if x != nil && x.LowWatermark != nil {
+		return *x.LowWatermark
+	}
+	return 0
+}
+
+type RuntimeDef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID           string         `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name         *string        `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	ModuleRef    string         `protobuf:"bytes,3,opt,name=moduleRef,proto3" json:"moduleRef,omitempty"`
+	MainFuncName *string        `protobuf:"bytes,4,opt,name=mainFuncName,proto3,oneof" json:"mainFuncName,omitempty"`
+	Type         RuntimeType    `protobuf:"varint,5,opt,name=type,proto3,enum=RuntimeType" json:"type,omitempty"`
+	Platform     *Platform      `protobuf:"varint,6,opt,name=platform,proto3,enum=Platform,oneof" json:"platform,omitempty"`
+	Limits       *RuntimeLimits `protobuf:"bytes,7,opt,name=limits,proto3,oneof" json:"limits,omitempty"`
+}
+
+func (x *RuntimeDef) Reset() {
+	*x = RuntimeDef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuntimeDef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuntimeDef) ProtoMessage() {}
+
+func (x *RuntimeDef) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuntimeDef.ProtoReflect.Descriptor instead.
+func (*RuntimeDef) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RuntimeDef) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+func (x *RuntimeDef) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *RuntimeDef) GetModuleRef() string {
+	if x != nil {
+		return x.ModuleRef
+	}
+	return ""
+}
+
+func (x *RuntimeDef) GetMainFuncName() string {
+	if x != nil && x.MainFuncName != nil {
+		return *x.MainFuncName
+	}
+	return ""
+}
+
+func (x *RuntimeDef) GetType() RuntimeType {
+	if x != nil {
+		return x.Type
+	}
+	return RuntimeType_Wasm10
+}
+
+func (x *RuntimeDef) GetPlatform() Platform {
+	if x != nil && x.Platform != nil {
+		return *x.Platform
+	}
+	return Platform_TinyGO
+}
+
+func (x *RuntimeDef) GetLimits() *RuntimeLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+// Resources a run of the module can use. The runs that exceed them are recorded as Limit results.
+// Only the time and the memory are limited: wazero, which runs the modules, does not meter instructions,
+// so there is no instruction budget.
+type RuntimeLimits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Zero means the executor's wasm.exec.timeout.
+	TimeoutMillis uint32 `protobuf:"varint,1,opt,name=timeoutMillis,proto3" json:"timeoutMillis,omitempty"`
+	// Pages of 64KiB the linear memory can grow to. Zero means the WASM limit of 4GiB.
+	MaxMemoryPages uint32 `protobuf:"varint,2,opt,name=maxMemoryPages,proto3" json:"maxMemoryPages,omitempty"`
+}
+
+func (x *RuntimeLimits) Reset() {
+	*x = RuntimeLimits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuntimeLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuntimeLimits) ProtoMessage() {}
+
+func (x *RuntimeLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuntimeLimits.ProtoReflect.Descriptor instead.
+func (*RuntimeLimits) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RuntimeLimits) GetTimeoutMillis() uint32 {
+	if x != nil {
+		return x.TimeoutMillis
+	}
+	return 0
+}
+
+func (x *RuntimeLimits) GetMaxMemoryPages() uint32 {
+	if x != nil {
+		return x.MaxMemoryPages
+	}
+	return 0
+}
+
+type JobDef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event  *EventDef  `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Result *ResultDef `protobuf:"bytes,2,opt,name=result,proto3,oneof" json:"result,omitempty"`
+	// Runs the event again when the Jobicolet fails with a retryable code.
+	Retry *RetryPolicy `protobuf:"bytes,3,opt,name=retry,proto3,oneof" json:"retry,omitempty"`
+}
+
+func (x *JobDef) Reset() {
+	*x = JobDef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobDef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobDef) ProtoMessage() {}
+
+func (x *JobDef) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobDef.ProtoReflect.Descriptor instead.
+func (*JobDef) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *JobDef) GetEvent() *EventDef {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *JobDef) GetResult() *ResultDef {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *JobDef) GetRetry() *RetryPolicy {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+// The event is scheduled again after a backoff of initialBackoffMillis * multiplier^(attempt-1),
+// capped to maxBackoffMillis. The error result is only sent once the attempts are exhausted.
+type RetryPolicy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of runs of the event, including the first one.
+	MaxAttempts          uint32 `protobuf:"varint,1,opt,name=maxAttempts,proto3" json:"maxAttempts,omitempty"`
+	InitialBackoffMillis uint32 `protobuf:"varint,2,opt,name=initialBackoffMillis,proto3" json:"initialBackoffMillis,omitempty"`
+	// Two when empty.
+	Multiplier *float64 `protobuf:"fixed64,3,opt,name=multiplier,proto3,oneof" json:"multiplier,omitempty"`
+	// Zero means no cap.
+	MaxBackoffMillis uint32 `protobuf:"varint,4,opt,name=maxBackoffMillis,proto3" json:"maxBackoffMillis,omitempty"`
+	// Codes returned by the Jobicolet that are retried. Every error code is retried when empty.
+	RetryableCodes []uint64 `protobuf:"varint,5,rep,packed,name=retryableCodes,proto3" json:"retryableCodes,omitempty"`
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RetryPolicy) GetMaxAttempts() uint32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetInitialBackoffMillis() uint32 {
+	if x != nil {
+		return x.InitialBackoffMillis
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMultiplier() float64 {
+	if x != nil && x.Multiplier != nil {
+		return *x.Multiplier
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMaxBackoffMillis() uint32 {
+	if x != nil {
+		return x.MaxBackoffMillis
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetRetryableCodes() []uint64 {
+	if x != nil {
+		return x.RetryableCodes
+	}
+	return nil
+}
+
+// A workflow runs the jobs of its steps as a DAG. An instance starts every time the event of its first
+// step is processed.
+type WorkflowDef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID    string     `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Name  *string    `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Steps []*StepDef `protobuf:"bytes,3,rep,name=steps,proto3" json:"steps,omitempty"`
+}
+
+func (x *WorkflowDef) Reset() {
+	*x = WorkflowDef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WorkflowDef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkflowDef) ProtoMessage() {}
+
+func (x *WorkflowDef) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkflowDef.ProtoReflect.Descriptor instead.
+func (*WorkflowDef) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *WorkflowDef) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+func (x *WorkflowDef) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *WorkflowDef) GetSteps() []*StepDef {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+type StepDef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ID string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	// Event processed by the step. It must be declared by a job of the package.
+	Event string `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	// Every route that matches the result is followed, so a step can fan out to several steps.
+	Routes []*RouteDef `protobuf:"bytes,3,rep,name=routes,proto3" json:"routes,omitempty"`
+	// The step waits for every step that routes to it, and receives their results in a JSON object
+	// keyed by step ID. Only joins can be routed from more than one step.
+	Join bool `protobuf:"varint,4,opt,name=join,proto3" json:"join,omitempty"`
+}
+
+func (x *StepDef) Reset() {
+	*x = StepDef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StepDef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepDef) ProtoMessage() {}
+
+func (x *StepDef) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepDef.ProtoReflect.Descriptor instead.
+func (*StepDef) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *StepDef) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+func (x *StepDef) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *StepDef) GetRoutes() []*RouteDef {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+func (x *StepDef) GetJoin() bool {
+	if x != nil {
+		return x.Join
+	}
+	return false
+}
+
+// A route matches when all its conditions match.
+type RouteDef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Inclusive range of the codes returned by the Jobicolet.
+	MinCode *uint64 `protobuf:"varint,1,opt,name=minCode,proto3,oneof" json:"minCode,omitempty"`
+	MaxCode *uint64 `protobuf:"varint,2,opt,name=maxCode,proto3,oneof" json:"maxCode,omitempty"`
+	// JSONPath (e.g. $.customer.tiers[0]) evaluated on the result. It matches when it selects a value
+	// equal to equals, or any value when equals is empty.
+	JsonPath *string `protobuf:"bytes,3,opt,name=jsonPath,proto3,oneof" json:"jsonPath,omitempty"`
+	Equals   *string `protobuf:"bytes,4,opt,name=equals,proto3,oneof" json:"equals,omitempty"`
+	// Steps that run next.
+	Next []string `protobuf:"bytes,5,rep,name=next,proto3" json:"next,omitempty"`
+}
+
+func (x *RouteDef) Reset() {
+	*x = RouteDef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RouteDef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteDef) ProtoMessage() {}
+
+func (x *RouteDef) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteDef.ProtoReflect.Descriptor instead.
+func (*RouteDef) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RouteDef) GetMinCode() uint64 {
+	if x != nil && x.MinCode != nil {
+		return *x.MinCode
+	}
+	return 0
+}
+
+func (x *RouteDef) GetMaxCode() uint64 {
+	if x != nil && x.MaxCode != nil {
+		return *x.MaxCode
+	}
+	return 0
+}
+
+func (x *RouteDef) GetJsonPath() string {
+	if x != nil && x.JsonPath != nil {
+		return *x.JsonPath
+	}
+	return ""
+}
+
+func (x *RouteDef) GetEquals() string {
+	if x != nil && x.Equals != nil {
+		return *x.Equals
+	}
+	return ""
+}
+
+func (x *RouteDef) GetNext() []string {
+	if x != nil {
+		return x.Next
+	}
+	return nil
+}
+
+type WorkflowInstance struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// workflow/instance
+	ID       string                  `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Package  string                  `protobuf:"bytes,2,opt,name=package,proto3" json:"package,omitempty"`
+	Workflow string                  `protobuf:"bytes,3,opt,name=workflow,proto3" json:"workflow,omitempty"`
+	Instance string                  `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"`
+	Status   WorkflowInstance_Status `protobuf:"varint,5,opt,name=status,proto3,enum=WorkflowInstance_Status" json:"status,omitempty"`
+	Steps    []*StepRun              `protobuf:"bytes,6,rep,name=steps,proto3" json:"steps,omitempty"`
+	// Steps queued or running.
+	Active uint32 `protobuf:"varint,7,opt,name=active,proto3" json:"active,omitempty"`
+	// Time the instance completed. Completed instances are removed once ctl.workflow.retention elapses.
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=completedAt,proto3,oneof" json:"completedAt,omitempty"`
+}
+
+func (x *WorkflowInstance) Reset() {
+	*x = WorkflowInstance{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_control_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WorkflowInstance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkflowInstance) ProtoMessage() {}
+
+func (x *WorkflowInstance) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkflowInstance.ProtoReflect.Descriptor instead.
+func (*WorkflowInstance) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *WorkflowInstance) GetID() string {
+	if x != nil {
+		return x.ID
+	}
+	return ""
+}
+
+func (x *WorkflowInstance) GetPackage() string {
+	if x != nil {
+		return x.Package
+	}
+	return ""
+}
+
+func (x *WorkflowInstance) GetWorkflow() string {
+	if x != nil {
+		return x.Workflow
+	}
+	return ""
+}
+
+func (x *WorkflowInstance) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *WorkflowInstance) GetStatus() WorkflowInstance_Status {
+	if x != nil {
+		return x.Status
+	}
+	return WorkflowInstance_Running
+}
+
+func (x *WorkflowInstance) GetSteps() []*StepRun {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
 }
 
-func (x *QueueDef) GetID() string {
+func (x *WorkflowInstance) GetActive() uint32 {
 	if x != nil {
-		return x.ID
+		return x.Active
 	}
-	return ""
+	return 0
 }
 
-func (x *QueueDef) GetName() string {
-	if x != nil && x.Name != nil {
-		return *x.Name
+func (x *WorkflowInstance) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
 	}
-	return ""
+	return nil
 }
 
-type RuntimeDef struct {
+type StepRun struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ID           string      `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Name         *string     `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
-	ModuleRef    string      `protobuf:"bytes,3,opt,name=moduleRef,proto3" json:"moduleRef,omitempty"`
-	MainFuncName *string     `protobuf:"bytes,4,opt,name=mainFuncName,proto3,oneof" json:"mainFuncName,omitempty"`
-	Type         RuntimeType `protobuf:"varint,5,opt,name=type,proto3,enum=RuntimeType" json:"type,omitempty"`
-	Platform     *Platform   `protobuf:"varint,6,opt,name=platform,proto3,enum=Platform,oneof" json:"platform,omitempty"`
+	Step   string `protobuf:"bytes,1,opt,name=step,proto3" json:"step,omitempty"`
+	Code   uint64 `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Result string `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	// Steps the result was routed to.
+	Next []string `protobuf:"bytes,4,rep,name=next,proto3" json:"next,omitempty"`
+	// Steps queued when this one completed.
+	Starts []*StepStart `protobuf:"bytes,5,rep,name=starts,proto3" json:"starts,omitempty"`
 }
 
-func (x *RuntimeDef) Reset() {
-	*x = RuntimeDef{}
+func (x *StepRun) Reset() {
+	*x = StepRun{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[24]
+		mi := &file_control_proto_msgTypes[36]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RuntimeDef) String() string {
+func (x *StepRun) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RuntimeDef) ProtoMessage() {}
+func (*StepRun) ProtoMessage() {}
 
-func (x *RuntimeDef) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[24]
+func (x *StepRun) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[36]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1515,79 +2737,76 @@ func (x *RuntimeDef) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RuntimeDef.ProtoReflect.Descriptor instead.
-func (*RuntimeDef) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use StepRun.ProtoReflect.Descriptor instead.
+func (*StepRun) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *RuntimeDef) GetID() string {
+func (x *StepRun) GetStep() string {
 	if x != nil {
-		return x.ID
-	}
-	return ""
-}
-
-func (x *RuntimeDef) GetName() string {
-	if x != nil && x.Name != nil {
-		return *x.Name
+		return x.Step
 	}
 	return ""
 }
 
-func (x *RuntimeDef) GetModuleRef() string {
+func (x *StepRun) GetCode() uint64 {
 	if x != nil {
-		return x.ModuleRef
+		return x.Code
 	}
-	return ""
+	return 0
 }
 
-func (x *RuntimeDef) GetMainFuncName() string {
-	if x != nil && x.MainFuncName != nil {
-		return *x.MainFuncName
+func (x *StepRun) GetResult() string {
+	if x != nil {
+		return x.Result
 	}
 	return ""
 }
 
-func (x *RuntimeDef) GetType() RuntimeType {
+func (x *StepRun) GetNext() []string {
 	if x != nil {
-		return x.Type
+		return x.Next
 	}
-	return RuntimeType_Wasm10
+	return nil
 }
 
-func (x *RuntimeDef) GetPlatform() Platform {
-	if x != nil && x.Platform != nil {
-		return *x.Platform
+func (x *StepRun) GetStarts() []*StepStart {
+	if x != nil {
+		return x.Starts
 	}
-	return Platform_TinyGO
+	return nil
 }
 
-type JobDef struct {
+type StepStart struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Event  *EventDef  `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
-	Result *ResultDef `protobuf:"bytes,2,opt,name=result,proto3,oneof" json:"result,omitempty"`
+	Step          string `protobuf:"bytes,1,opt,name=step,proto3" json:"step,omitempty"`
+	Event         string `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	SupplierQueue string `protobuf:"bytes,3,opt,name=supplierQueue,proto3" json:"supplierQueue,omitempty"`
+	Data          []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	// JSON pointer to the partition key of the data, as declared by the event.
+	PartitionKey *string `protobuf:"bytes,5,opt,name=partitionKey,proto3,oneof" json:"partitionKey,omitempty"`
 }
 
-func (x *JobDef) Reset() {
-	*x = JobDef{}
+func (x *StepStart) Reset() {
+	*x = StepStart{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[25]
+		mi := &file_control_proto_msgTypes[37]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *JobDef) String() string {
+func (x *StepStart) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobDef) ProtoMessage() {}
+func (*StepStart) ProtoMessage() {}
 
-func (x *JobDef) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[25]
+func (x *StepStart) ProtoReflect() protoreflect.Message {
+	mi := &file_control_proto_msgTypes[37]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1598,25 +2817,46 @@ func (x *JobDef) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobDef.ProtoReflect.Descriptor instead.
-func (*JobDef) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use StepStart.ProtoReflect.Descriptor instead.
+func (*StepStart) Descriptor() ([]byte, []int) {
+	return file_control_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *JobDef) GetEvent() *EventDef {
+func (x *StepStart) GetStep() string {
+	if x != nil {
+		return x.Step
+	}
+	return ""
+}
+
+func (x *StepStart) GetEvent() string {
 	if x != nil {
 		return x.Event
 	}
-	return nil
+	return ""
 }
 
-func (x *JobDef) GetResult() *ResultDef {
+func (x *StepStart) GetSupplierQueue() string {
 	if x != nil {
-		return x.Result
+		return x.SupplierQueue
+	}
+	return ""
+}
+
+func (x *StepStart) GetData() []byte {
+	if x != nil {
+		return x.Data
 	}
 	return nil
 }
 
+func (x *StepStart) GetPartitionKey() string {
+	if x != nil && x.PartitionKey != nil {
+		return *x.PartitionKey
+	}
+	return ""
+}
+
 type ResultDef struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1624,12 +2864,14 @@ type ResultDef struct {
 
 	Ok    *EventDef `protobuf:"bytes,1,opt,name=ok,proto3,oneof" json:"ok,omitempty"`
 	Error *EventDef `protobuf:"bytes,2,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	// Data of the event sent to ok or error.
+	Payload ResultDef_Payload `protobuf:"varint,3,opt,name=payload,proto3,enum=ResultDef_Payload" json:"payload,omitempty"`
 }
 
 func (x *ResultDef) Reset() {
 	*x = ResultDef{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[26]
+		mi := &file_control_proto_msgTypes[38]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1642,7 +2884,7 @@ func (x *ResultDef) String() string {
 func (*ResultDef) ProtoMessage() {}
 
 func (x *ResultDef) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[26]
+	mi := &file_control_proto_msgTypes[38]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1655,7 +2897,7 @@ func (x *ResultDef) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResultDef.ProtoReflect.Descriptor instead.
 func (*ResultDef) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{26}
+	return file_control_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *ResultDef) GetOk() *EventDef {
@@ -1672,6 +2914,13 @@ func (x *ResultDef) GetError() *EventDef {
 	return nil
 }
 
+func (x *ResultDef) GetPayload() ResultDef_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return ResultDef_Code
+}
+
 type EventDef struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1683,12 +2932,14 @@ type EventDef struct {
 	Schema        *SchemaDef `protobuf:"bytes,4,opt,name=schema,proto3,oneof" json:"schema,omitempty"`
 	SupplierQueue string     `protobuf:"bytes,5,opt,name=supplierQueue,proto3" json:"supplierQueue,omitempty"`
 	Runtime       string     `protobuf:"bytes,6,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	// JSON pointer (RFC 6901) to the partition key of the event. Events with the same key are processed one at a time and in order.
+	PartitionKey *string `protobuf:"bytes,7,opt,name=partitionKey,proto3,oneof" json:"partitionKey,omitempty"`
 }
 
 func (x *EventDef) Reset() {
 	*x = EventDef{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[27]
+		mi := &file_control_proto_msgTypes[39]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1701,7 +2952,7 @@ func (x *EventDef) String() string {
 func (*EventDef) ProtoMessage() {}
 
 func (x *EventDef) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[27]
+	mi := &file_control_proto_msgTypes[39]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1714,7 +2965,7 @@ func (x *EventDef) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EventDef.ProtoReflect.Descriptor instead.
 func (*EventDef) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{27}
+	return file_control_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *EventDef) GetID() string {
@@ -1759,6 +3010,13 @@ func (x *EventDef) GetRuntime() string {
 	return ""
 }
 
+func (x *EventDef) GetPartitionKey() string {
+	if x != nil && x.PartitionKey != nil {
+		return *x.PartitionKey
+	}
+	return ""
+}
+
 type SchemaDef struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1772,7 +3030,7 @@ type SchemaDef struct {
 func (x *SchemaDef) Reset() {
 	*x = SchemaDef{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_control_proto_msgTypes[28]
+		mi := &file_control_proto_msgTypes[40]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1785,7 +3043,7 @@ func (x *SchemaDef) String() string {
 func (*SchemaDef) ProtoMessage() {}
 
 func (x *SchemaDef) ProtoReflect() protoreflect.Message {
-	mi := &file_control_proto_msgTypes[28]
+	mi := &file_control_proto_msgTypes[40]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1798,7 +3056,7 @@ func (x *SchemaDef) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SchemaDef.ProtoReflect.Descriptor instead.
 func (*SchemaDef) Descriptor() ([]byte, []int) {
-	return file_control_proto_rawDescGZIP(), []int{28}
+	return file_control_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *SchemaDef) GetID() string {
@@ -1826,225 +3084,426 @@ var File_control_proto protoreflect.FileDescriptor
 
 var file_control_proto_rawDesc = []byte{
 	0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
-	0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x64, 0x0a,
-	0x1b, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e,
-	0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1f, 0x0a, 0x04,
-	0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x55, 0x70, 0x64,
-	0x61, 0x74, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x24, 0x0a,
-	0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
-	0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x6f, 0x62, 0x6a,
-	0x65, 0x63, 0x74, 0x22, 0x34, 0x0a, 0x1a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x50,
-	0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x53, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x60, 0x0a, 0x18, 0x55, 0x70, 0x64,
-	0x61, 0x74, 0x65, 0x54, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x53, 0x74, 0x72,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x79, 0x70, 0x65,
-	0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50, 0x61, 0x63, 0x6b,
-	0x61, 0x67, 0x65, 0x52, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x22, 0x3a, 0x0a, 0x11, 0x41,
-	0x64, 0x64, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x25, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x07,
-	0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x22, 0x38, 0x0a, 0x0f, 0x41, 0x64, 0x64, 0x50, 0x61,
-	0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x25, 0x0a, 0x07, 0x70, 0x61,
-	0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f,
-	0x62, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
-	0x65, 0x22, 0x3b, 0x0a, 0x10, 0x41, 0x6c, 0x6c, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x27, 0x0a, 0x08, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50, 0x61, 0x63,
-	0x6b, 0x61, 0x67, 0x65, 0x52, 0x08, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x22, 0x45,
-	0x0a, 0x0f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x12, 0x13, 0x0a, 0x02, 0x49, 0x44, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x02, 0x49, 0x44, 0x88, 0x01, 0x01, 0x42, 0x05,
-	0x0a, 0x03, 0x5f, 0x49, 0x44, 0x22, 0x38, 0x0a, 0x0d, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65,
+	0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x64,
+	0x0a, 0x1b, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f,
+	0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1f, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x24,
+	0x0a, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x6f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x22, 0x34, 0x0a, 0x1a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f,
+	0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x53, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x60, 0x0a, 0x18, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x53, 0x74,
+	0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50, 0x61, 0x63,
+	0x6b, 0x61, 0x67, 0x65, 0x52, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x22, 0x3a, 0x0a, 0x11,
+	0x41, 0x64, 0x64, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x25, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52,
+	0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x22, 0x38, 0x0a, 0x0f, 0x41, 0x64, 0x64, 0x50,
+	0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x25, 0x0a, 0x07, 0x70,
+	0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a,
+	0x6f, 0x62, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61,
+	0x67, 0x65, 0x22, 0x3b, 0x0a, 0x10, 0x41, 0x6c, 0x6c, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65,
 	0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x27, 0x0a, 0x08, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
 	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50, 0x61,
 	0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x08, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x22,
-	0x3d, 0x0a, 0x14, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65,
+	0x45, 0x0a, 0x0f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x12, 0x13, 0x0a, 0x02, 0x49, 0x44,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x02, 0x49, 0x44, 0x88, 0x01, 0x01, 0x42,
+	0x05, 0x0a, 0x03, 0x5f, 0x49, 0x44, 0x22, 0x38, 0x0a, 0x0d, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x27, 0x0a, 0x08, 0x70, 0x61, 0x63, 0x6b, 0x61,
+	0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50,
+	0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x08, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73,
+	0x22, 0x3d, 0x0a, 0x14, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b,
+	0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50,
+	0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x22,
+	0x3d, 0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65,
 	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61,
 	0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50, 0x61,
-	0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x22, 0x3d,
-	0x0a, 0x14, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4a, 0x6f, 0x62, 0x50, 0x61, 0x63,
-	0x6b, 0x61, 0x67, 0x65, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x22, 0x57, 0x0a,
-	0x10, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c,
-	0x79, 0x12, 0x33, 0x0a, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e,
-	0x6d, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d,
-	0x65, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x65, 0x6e, 0x76, 0x69, 0x72,
-	0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x47, 0x0a, 0x15, 0x41, 0x64, 0x64, 0x45, 0x6e, 0x76,
-	0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x2e, 0x0a, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65,
-	0x6e, 0x74, 0x52, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x22,
-	0x4a, 0x0a, 0x18, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e,
-	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x0b, 0x65,
-	0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0c, 0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0b,
-	0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x45, 0x0a, 0x13, 0x41,
-	0x64, 0x64, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e,
+	0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x22, 0xc2,
+	0x01, 0x0a, 0x16, 0x41, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c,
+	0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e,
+	0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x77,
+	0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77,
+	0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x73, 0x74, 0x65, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x22, 0x3a, 0x0a, 0x14, 0x41, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x57, 0x6f,
+	0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x22, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x53, 0x74,
+	0x65, 0x70, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x06, 0x73, 0x74, 0x61, 0x72, 0x74, 0x73, 0x22,
+	0x69, 0x0a, 0x17, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x49, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65,
+	0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61,
+	0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x12, 0x1a,
+	0x0a, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x58, 0x0a, 0x15, 0x57, 0x6f,
+	0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x32, 0x0a, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x00, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x69, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x22, 0x57, 0x0a, 0x10, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d,
+	0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x33, 0x0a, 0x0b, 0x65, 0x6e, 0x76, 0x69,
+	0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x0b, 0x65,
+	0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a,
+	0x0c, 0x5f, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x47, 0x0a,
+	0x15, 0x41, 0x64, 0x64, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f,
+	0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x45, 0x6e,
+	0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72,
+	0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x4a, 0x0a, 0x18, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x2e, 0x0a, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e,
 	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f,
 	0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0b, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65,
-	0x6e, 0x74, 0x22, 0x54, 0x0a, 0x0e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x13, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x48, 0x00, 0x52, 0x02, 0x49, 0x44, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x74, 0x65, 0x6e,
-	0x61, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x06, 0x74, 0x65, 0x6e,
-	0x61, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x42, 0x05, 0x0a, 0x03, 0x5f, 0x49, 0x44, 0x42, 0x09, 0x0a,
-	0x07, 0x5f, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x31, 0x0a, 0x0c, 0x54, 0x65, 0x6e, 0x61,
-	0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x21, 0x0a, 0x07, 0x54, 0x65, 0x6e, 0x61,
-	0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x54, 0x65, 0x6e, 0x61,
-	0x6e, 0x74, 0x52, 0x07, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x22, 0x33, 0x0a, 0x10, 0x41,
-	0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x1f, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x07, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74,
-	0x22, 0x31, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x12, 0x1f, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x07, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x06, 0x74, 0x65, 0x6e,
-	0x61, 0x6e, 0x74, 0x22, 0x74, 0x0a, 0x0b, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65,
-	0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
-	0x49, 0x44, 0x12, 0x24, 0x0a, 0x08, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x52, 0x08,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61,
-	0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e,
-	0x74, 0x48, 0x00, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x42, 0x09,
-	0x0a, 0x07, 0x5f, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x82, 0x01, 0x0a, 0x07, 0x53, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1f,
-	0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12,
-	0x24, 0x0a, 0x08, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x08, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x52, 0x08, 0x73, 0x74, 0x6f,
-	0x72, 0x61, 0x67, 0x65, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x7b,
-	0x0a, 0x07, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x4e, 0x61, 0x6d,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x88,
-	0x01, 0x01, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
-	0x12, 0x20, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c,
-	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
-	0x70, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0xbf, 0x01, 0x0a, 0x0a,
-	0x4a, 0x6f, 0x62, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
-	0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x06, 0x71,
-	0x75, 0x65, 0x75, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x51, 0x75,
-	0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x52, 0x06, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x12, 0x1b,
-	0x0a, 0x04, 0x6a, 0x6f, 0x62, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x4a,
-	0x6f, 0x62, 0x44, 0x65, 0x66, 0x52, 0x04, 0x6a, 0x6f, 0x62, 0x73, 0x12, 0x27, 0x0a, 0x08, 0x72,
-	0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
-	0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x44, 0x65, 0x66, 0x52, 0x08, 0x72, 0x75, 0x6e, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x3a, 0x0a,
-	0x06, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01,
-	0x42, 0x07, 0x0a, 0x05, 0x5f, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x3c, 0x0a, 0x08, 0x51, 0x75, 0x65,
-	0x75, 0x65, 0x44, 0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x42, 0x07,
-	0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xf1, 0x01, 0x0a, 0x0a, 0x52, 0x75, 0x6e, 0x74,
-	0x69, 0x6d, 0x65, 0x44, 0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12,
-	0x1c, 0x0a, 0x09, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x12, 0x27, 0x0a,
-	0x0c, 0x6d, 0x61, 0x69, 0x6e, 0x46, 0x75, 0x6e, 0x63, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0c, 0x6d, 0x61, 0x69, 0x6e, 0x46, 0x75, 0x6e, 0x63, 0x4e,
-	0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x54, 0x79,
-	0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x2a, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74,
-	0x66, 0x6f, 0x72, 0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x09, 0x2e, 0x50, 0x6c, 0x61,
-	0x74, 0x66, 0x6f, 0x72, 0x6d, 0x48, 0x02, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
-	0x6d, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0f, 0x0a,
-	0x0d, 0x5f, 0x6d, 0x61, 0x69, 0x6e, 0x46, 0x75, 0x6e, 0x63, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x0b,
-	0x0a, 0x09, 0x5f, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x22, 0x5d, 0x0a, 0x06, 0x4a,
-	0x6f, 0x62, 0x44, 0x65, 0x66, 0x12, 0x1f, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x52,
-	0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x27, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x44,
-	0x65, 0x66, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x88, 0x01, 0x01, 0x42,
-	0x09, 0x0a, 0x07, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x62, 0x0a, 0x09, 0x52, 0x65,
-	0x73, 0x75, 0x6c, 0x74, 0x44, 0x65, 0x66, 0x12, 0x1e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x48, 0x00,
-	0x52, 0x02, 0x6f, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x65,
-	0x66, 0x48, 0x01, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x88, 0x01, 0x01, 0x42, 0x05, 0x0a,
-	0x03, 0x5f, 0x6f, 0x6b, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xd7,
-	0x01, 0x0a, 0x08, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x49,
-	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x08, 0x64, 0x61, 0x74, 0x61, 0x54, 0x79, 0x70, 0x65,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x09, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x54, 0x79, 0x70,
-	0x65, 0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x27, 0x0a, 0x06, 0x73,
-	0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x53, 0x63,
-	0x68, 0x65, 0x6d, 0x61, 0x44, 0x65, 0x66, 0x48, 0x01, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d,
-	0x61, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0d, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x72,
-	0x51, 0x75, 0x65, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x75, 0x70,
-	0x70, 0x6c, 0x69, 0x65, 0x72, 0x51, 0x75, 0x65, 0x75, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x75,
-	0x6e, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x75, 0x6e,
-	0x74, 0x69, 0x6d, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x09, 0x0a,
-	0x07, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x22, 0x5b, 0x0a, 0x09, 0x53, 0x63, 0x68, 0x65,
-	0x6d, 0x61, 0x44, 0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1c,
-	0x0a, 0x09, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x52, 0x65, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x52, 0x65, 0x66, 0x42, 0x07, 0x0a, 0x05,
-	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x2a, 0x21, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x69, 0x72,
-	0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x10, 0x00, 0x2a, 0x21, 0x0a, 0x0b, 0x52, 0x75, 0x6e, 0x74,
-	0x69, 0x6d, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x57, 0x61, 0x73, 0x6d, 0x31,
-	0x30, 0x10, 0x00, 0x12, 0x06, 0x0a, 0x02, 0x47, 0x6f, 0x10, 0x01, 0x2a, 0x16, 0x0a, 0x08, 0x50,
-	0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x0a, 0x0a, 0x06, 0x54, 0x69, 0x6e, 0x79, 0x47,
-	0x4f, 0x10, 0x00, 0x2a, 0x14, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12,
-	0x08, 0x0a, 0x04, 0x4a, 0x73, 0x6f, 0x6e, 0x10, 0x00, 0x32, 0x98, 0x05, 0x0a, 0x07, 0x43, 0x6f,
-	0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x12, 0x2b, 0x0a, 0x07, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73,
-	0x12, 0x0f, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x0d, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
-	0x22, 0x00, 0x12, 0x31, 0x0a, 0x09, 0x41, 0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x12,
-	0x11, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65,
-	0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x34, 0x0a, 0x0a, 0x41, 0x64, 0x64, 0x50, 0x61, 0x63, 0x6b,
-	0x61, 0x67, 0x65, 0x12, 0x12, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x61, 0x63,
-	0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x29, 0x0a, 0x0b, 0x41,
-	0x6c, 0x6c, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x12, 0x05, 0x2e, 0x56, 0x6f, 0x69,
-	0x64, 0x1a, 0x11, 0x2e, 0x41, 0x6c, 0x6c, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x08, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67,
-	0x65, 0x73, 0x12, 0x10, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x2f, 0x0a, 0x0d, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
-	0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x15, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
-	0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x05,
-	0x2e, 0x56, 0x6f, 0x69, 0x64, 0x22, 0x00, 0x12, 0x2f, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x15, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x05, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x22, 0x00, 0x12, 0x51, 0x0a, 0x13, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x54, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x53, 0x74, 0x72, 0x12,
-	0x1b, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67,
-	0x65, 0x73, 0x53, 0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x55,
+	0x6e, 0x74, 0x22, 0x45, 0x0a, 0x13, 0x41, 0x64, 0x64, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e,
+	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x0b, 0x65, 0x6e, 0x76,
+	0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0b, 0x65, 0x6e,
+	0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x54, 0x0a, 0x0e, 0x54, 0x65, 0x6e,
+	0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x13, 0x0a, 0x02, 0x49,
+	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x02, 0x49, 0x44, 0x88, 0x01, 0x01,
+	0x12, 0x1b, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x01, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x42, 0x05, 0x0a,
+	0x03, 0x5f, 0x49, 0x44, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22,
+	0x31, 0x0a, 0x0c, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x21, 0x0a, 0x07, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x07, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x07, 0x54, 0x65, 0x6e, 0x61, 0x6e,
+	0x74, 0x73, 0x22, 0x33, 0x0a, 0x10, 0x41, 0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52,
+	0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x31, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x54, 0x65,
+	0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1f, 0x0a, 0x06, 0x74, 0x65, 0x6e,
+	0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x54, 0x65, 0x6e, 0x61,
+	0x6e, 0x74, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x74, 0x0a, 0x0b, 0x45, 0x6e,
+	0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x24, 0x0a, 0x08, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12,
+	0x24, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x07, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61,
+	0x6e, 0x74, 0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74,
+	0x22, 0x82, 0x01, 0x0a, 0x07, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x0e, 0x0a, 0x02,
+	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x07, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12, 0x24, 0x0a, 0x08, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x52, 0x08, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x73, 0x42, 0x07, 0x0a, 0x05,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x7b, 0x0a, 0x07, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44,
+	0x12, 0x17, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x66,
+	0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x20, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x4e, 0x61,
+	0x6d, 0x65, 0x22, 0xeb, 0x01, 0x0a, 0x0a, 0x4a, 0x6f, 0x62, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49,
+	0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x65,
+	0x6e, 0x61, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x61,
+	0x6e, 0x74, 0x12, 0x21, 0x0a, 0x06, 0x71, 0x75, 0x65, 0x75, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x09, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x52, 0x06, 0x71,
+	0x75, 0x65, 0x75, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x04, 0x6a, 0x6f, 0x62, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x4a, 0x6f, 0x62, 0x44, 0x65, 0x66, 0x52, 0x04, 0x6a, 0x6f,
+	0x62, 0x73, 0x12, 0x27, 0x0a, 0x08, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x44, 0x65,
+	0x66, 0x52, 0x08, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x09, 0x77,
+	0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x44, 0x65, 0x66, 0x52, 0x09, 0x77, 0x6f,
+	0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x22, 0x3a, 0x0a, 0x06, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65,
+	0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0xb9, 0x07, 0x0a,
+	0x08, 0x51, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88,
+	0x01, 0x01, 0x12, 0x25, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x41, 0x74,
+	0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a, 0x0f, 0x64, 0x65, 0x61,
+	0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x51, 0x75, 0x65, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x02, 0x52, 0x0f, 0x64, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72,
+	0x51, 0x75, 0x65, 0x75, 0x65, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0e, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d,
+	0x48, 0x03, 0x52, 0x0e, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x73, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x62, 0x61, 0x74, 0x63, 0x68, 0x53, 0x69,
+	0x7a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x04, 0x52, 0x09, 0x62, 0x61, 0x74, 0x63,
+	0x68, 0x53, 0x69, 0x7a, 0x65, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x12, 0x64, 0x65, 0x64, 0x75,
+	0x70, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0d, 0x48, 0x05, 0x52, 0x12, 0x64, 0x65, 0x64, 0x75, 0x70, 0x57, 0x69, 0x6e,
+	0x64, 0x6f, 0x77, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x34, 0x0a,
+	0x08, 0x6f, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x13, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x2e, 0x4c, 0x69, 0x66, 0x65, 0x63,
+	0x79, 0x63, 0x6c, 0x65, 0x48, 0x06, 0x52, 0x08, 0x6f, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x07, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x4c, 0x65, 0x6e,
+	0x67, 0x74, 0x68, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x48, 0x08, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x11, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x54, 0x54, 0x4c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x0d, 0x48, 0x09, 0x52, 0x11, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x54, 0x4c,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x08, 0x6f, 0x76,
+	0x65, 0x72, 0x66, 0x6c, 0x6f, 0x77, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x51,
+	0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x66, 0x6c, 0x6f, 0x77,
+	0x48, 0x0a, 0x52, 0x08, 0x6f, 0x76, 0x65, 0x72, 0x66, 0x6c, 0x6f, 0x77, 0x88, 0x01, 0x01, 0x12,
+	0x31, 0x0a, 0x11, 0x64, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x45, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x48, 0x0b, 0x52, 0x11, 0x64, 0x65,
+	0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x88,
+	0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x68, 0x69, 0x67, 0x68, 0x57, 0x61, 0x74, 0x65, 0x72, 0x6d,
+	0x61, 0x72, 0x6b, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x0c, 0x52, 0x0d, 0x68, 0x69, 0x67,
+	0x68, 0x57, 0x61, 0x74, 0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a,
+	0x0c, 0x6c, 0x6f, 0x77, 0x57, 0x61, 0x74, 0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x0d, 0x48, 0x0d, 0x52, 0x0c, 0x6c, 0x6f, 0x77, 0x57, 0x61, 0x74, 0x65, 0x72, 0x6d,
+	0x61, 0x72, 0x6b, 0x88, 0x01, 0x01, 0x22, 0x2f, 0x0a, 0x09, 0x4c, 0x69, 0x66, 0x65, 0x63, 0x79,
+	0x63, 0x6c, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x10, 0x00,
+	0x12, 0x09, 0x0a, 0x05, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x10, 0x02, 0x22, 0x26, 0x0a, 0x08, 0x4f, 0x76, 0x65, 0x72, 0x66,
+	0x6c, 0x6f, 0x77, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x10, 0x00, 0x12,
+	0x0e, 0x0a, 0x0a, 0x44, 0x72, 0x6f, 0x70, 0x4f, 0x6c, 0x64, 0x65, 0x73, 0x74, 0x10, 0x01, 0x42,
+	0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x6d, 0x61, 0x78,
+	0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x64, 0x65, 0x61,
+	0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x51, 0x75, 0x65, 0x75, 0x65, 0x42, 0x11, 0x0a, 0x0f,
+	0x5f, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x42,
+	0x0c, 0x0a, 0x0a, 0x5f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x15, 0x0a,
+	0x13, 0x5f, 0x64, 0x65, 0x64, 0x75, 0x70, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x6f, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6d, 0x61, 0x78, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x42,
+	0x0b, 0x0a, 0x09, 0x5f, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x42, 0x14, 0x0a, 0x12,
+	0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x54, 0x4c, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x6f, 0x76, 0x65, 0x72, 0x66, 0x6c, 0x6f, 0x77, 0x42,
+	0x14, 0x0a, 0x12, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x45, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x64, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x68, 0x69, 0x67, 0x68, 0x57, 0x61,
+	0x74, 0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6c, 0x6f, 0x77, 0x57,
+	0x61, 0x74, 0x65, 0x72, 0x6d, 0x61, 0x72, 0x6b, 0x22, 0xa9, 0x02, 0x0a, 0x0a, 0x52, 0x75, 0x6e,
+	0x74, 0x69, 0x6d, 0x65, 0x44, 0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x12, 0x27,
+	0x0a, 0x0c, 0x6d, 0x61, 0x69, 0x6e, 0x46, 0x75, 0x6e, 0x63, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0c, 0x6d, 0x61, 0x69, 0x6e, 0x46, 0x75, 0x6e, 0x63,
+	0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x2a, 0x0a, 0x08, 0x70, 0x6c, 0x61,
+	0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x09, 0x2e, 0x50, 0x6c,
+	0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x48, 0x02, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f,
+	0x72, 0x6d, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x73, 0x48, 0x03, 0x52, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x88,
+	0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0f, 0x0a, 0x0d, 0x5f,
+	0x6d, 0x61, 0x69, 0x6e, 0x46, 0x75, 0x6e, 0x63, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x0b, 0x0a, 0x09,
+	0x5f, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x22, 0x5d, 0x0a, 0x0d, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x74, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x6d,
+	0x61, 0x78, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x61, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x61,
+	0x67, 0x65, 0x73, 0x22, 0x90, 0x01, 0x0a, 0x06, 0x4a, 0x6f, 0x62, 0x44, 0x65, 0x66, 0x12, 0x1f,
+	0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x27, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0a, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x44, 0x65, 0x66, 0x48, 0x00, 0x52, 0x06, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x05, 0x72, 0x65, 0x74, 0x72,
+	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x48, 0x01, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x88, 0x01,
+	0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x08, 0x0a, 0x06,
+	0x5f, 0x72, 0x65, 0x74, 0x72, 0x79, 0x22, 0xeb, 0x01, 0x0a, 0x0b, 0x52, 0x65, 0x74, 0x72, 0x79,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x41, 0x74, 0x74,
+	0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x6d, 0x61, 0x78,
+	0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x32, 0x0a, 0x14, 0x69, 0x6e, 0x69, 0x74,
+	0x69, 0x61, 0x6c, 0x42, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x42,
+	0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x23, 0x0a, 0x0a,
+	0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x00, 0x52, 0x0a, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x88, 0x01,
+	0x01, 0x12, 0x2a, 0x0a, 0x10, 0x6d, 0x61, 0x78, 0x42, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x6d, 0x61, 0x78,
+	0x42, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x26, 0x0a,
+	0x0e, 0x72, 0x65, 0x74, 0x72, 0x79, 0x61, 0x62, 0x6c, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x18,
+	0x05, 0x20, 0x03, 0x28, 0x04, 0x52, 0x0e, 0x72, 0x65, 0x74, 0x72, 0x79, 0x61, 0x62, 0x6c, 0x65,
+	0x43, 0x6f, 0x64, 0x65, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70,
+	0x6c, 0x69, 0x65, 0x72, 0x22, 0x5f, 0x0a, 0x0b, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77,
+	0x44, 0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1e, 0x0a, 0x05,
+	0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x53, 0x74,
+	0x65, 0x70, 0x44, 0x65, 0x66, 0x52, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x42, 0x07, 0x0a, 0x05,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x66, 0x0a, 0x07, 0x53, 0x74, 0x65, 0x70, 0x44, 0x65, 0x66,
+	0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x44, 0x65,
+	0x66, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6a, 0x6f, 0x69,
+	0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6a, 0x6f, 0x69, 0x6e, 0x22, 0xca, 0x01,
+	0x0a, 0x08, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x44, 0x65, 0x66, 0x12, 0x1d, 0x0a, 0x07, 0x6d, 0x69,
+	0x6e, 0x43, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x07, 0x6d,
+	0x69, 0x6e, 0x43, 0x6f, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x6d, 0x61, 0x78,
+	0x43, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x48, 0x01, 0x52, 0x07, 0x6d, 0x61,
+	0x78, 0x43, 0x6f, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x6a, 0x73, 0x6f, 0x6e,
+	0x50, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x08, 0x6a, 0x73,
+	0x6f, 0x6e, 0x50, 0x61, 0x74, 0x68, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x65, 0x71, 0x75,
+	0x61, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x03, 0x52, 0x06, 0x65, 0x71, 0x75,
+	0x61, 0x6c, 0x73, 0x88, 0x01, 0x01, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d,
+	0x69, 0x6e, 0x43, 0x6f, 0x64, 0x65, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x6d, 0x61, 0x78, 0x43, 0x6f,
+	0x64, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x50, 0x61, 0x74, 0x68, 0x42,
+	0x09, 0x0a, 0x07, 0x5f, 0x65, 0x71, 0x75, 0x61, 0x6c, 0x73, 0x22, 0xd7, 0x02, 0x0a, 0x10, 0x57,
+	0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12,
+	0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12,
+	0x18, 0x0a, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x77, 0x6f, 0x72,
+	0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77, 0x6f, 0x72,
+	0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x12, 0x30, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x18, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x49, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x1e, 0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x08, 0x2e, 0x53, 0x74, 0x65, 0x70, 0x52, 0x75, 0x6e, 0x52, 0x05, 0x73, 0x74,
+	0x65, 0x70, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x41, 0x0a, 0x0b, 0x63,
+	0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x48, 0x00, 0x52, 0x0b,
+	0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x41, 0x74, 0x88, 0x01, 0x01, 0x22, 0x24,
+	0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x75, 0x6e, 0x6e,
+	0x69, 0x6e, 0x67, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
+	0x65, 0x64, 0x10, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x22, 0x81, 0x01, 0x0a, 0x07, 0x53, 0x74, 0x65, 0x70, 0x52, 0x75, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x73, 0x74, 0x65, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x65, 0x78, 0x74, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x72, 0x74, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x53, 0x74, 0x65, 0x70, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x72, 0x74, 0x73, 0x22, 0xa9, 0x01, 0x0a, 0x09, 0x53, 0x74, 0x65,
+	0x70, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x74, 0x65, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x24, 0x0a, 0x0d, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x51, 0x75, 0x65, 0x75,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x69, 0x65,
+	0x72, 0x51, 0x75, 0x65, 0x75, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x27, 0x0a, 0x0c, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x00, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79,
+	0x88, 0x01, 0x01, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x4b, 0x65, 0x79, 0x22, 0xca, 0x01, 0x0a, 0x09, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x44,
+	0x65, 0x66, 0x12, 0x1e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09,
+	0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x48, 0x00, 0x52, 0x02, 0x6f, 0x6b, 0x88,
+	0x01, 0x01, 0x12, 0x24, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x09, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x48, 0x01, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c,
+	0x6f, 0x61, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x44, 0x65, 0x66, 0x2e, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x07, 0x70,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x38, 0x0a, 0x07, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x12, 0x08, 0x0a, 0x04, 0x43, 0x6f, 0x64, 0x65, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x49, 0x6e, 0x70, 0x75, 0x74,
+	0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x10, 0x03,
+	0x42, 0x05, 0x0a, 0x03, 0x5f, 0x6f, 0x6b, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x22, 0x91, 0x02, 0x0a, 0x08, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x12, 0x0e,
+	0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x17,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x08, 0x64, 0x61, 0x74, 0x61, 0x54,
+	0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x09, 0x2e, 0x44, 0x61, 0x74, 0x61,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x27,
+	0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44, 0x65, 0x66, 0x48, 0x01, 0x52, 0x06, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0d, 0x73, 0x75, 0x70, 0x70, 0x6c,
+	0x69, 0x65, 0x72, 0x51, 0x75, 0x65, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x73, 0x75, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x51, 0x75, 0x65, 0x75, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52,
+	0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x88, 0x01, 0x01,
+	0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x22, 0x5b, 0x0a, 0x09, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x44,
+	0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x49, 0x44, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x52, 0x65, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x52, 0x65, 0x66, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x2a, 0x21, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x12, 0x0a, 0x0e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x79, 0x10, 0x00, 0x2a, 0x21, 0x0a, 0x0b, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x57, 0x61, 0x73, 0x6d, 0x31, 0x30, 0x10, 0x00,
+	0x12, 0x06, 0x0a, 0x02, 0x47, 0x6f, 0x10, 0x01, 0x2a, 0x16, 0x0a, 0x08, 0x50, 0x6c, 0x61, 0x74,
+	0x66, 0x6f, 0x72, 0x6d, 0x12, 0x0a, 0x0a, 0x06, 0x54, 0x69, 0x6e, 0x79, 0x47, 0x4f, 0x10, 0x00,
+	0x2a, 0x14, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04,
+	0x4a, 0x73, 0x6f, 0x6e, 0x10, 0x00, 0x32, 0xa5, 0x06, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x72,
+	0x6f, 0x6c, 0x12, 0x2b, 0x0a, 0x07, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x12, 0x0f, 0x2e,
+	0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d,
+	0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
+	0x31, 0x0a, 0x09, 0x41, 0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x12, 0x11, 0x2e, 0x41,
+	0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x0f, 0x2e, 0x41, 0x64, 0x64, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x12, 0x34, 0x0a, 0x0a, 0x41, 0x64, 0x64, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65,
+	0x12, 0x12, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x41, 0x64, 0x64, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x29, 0x0a, 0x0b, 0x41, 0x6c, 0x6c, 0x50,
+	0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x12, 0x05, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x1a, 0x11,
+	0x2e, 0x41, 0x6c, 0x6c, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x08, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x12,
+	0x10, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x0e, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x12, 0x2f, 0x0a, 0x0d, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x61, 0x63,
+	0x6b, 0x61, 0x67, 0x65, 0x12, 0x15, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x61, 0x63,
+	0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x05, 0x2e, 0x56, 0x6f,
+	0x69, 0x64, 0x22, 0x00, 0x12, 0x2f, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x61,
+	0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x15, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x61,
+	0x63, 0x6b, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x05, 0x2e, 0x56,
+	0x6f, 0x69, 0x64, 0x22, 0x00, 0x12, 0x51, 0x0a, 0x13, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54,
+	0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x53, 0x74, 0x72, 0x12, 0x1b, 0x2e, 0x55,
 	0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x53,
-	0x74, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x29, 0x0a, 0x0b, 0x45,
-	0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x05, 0x2e, 0x56, 0x6f, 0x69,
-	0x64, 0x1a, 0x11, 0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x41, 0x0a, 0x16, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
-	0x54, 0x6f, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72,
-	0x12, 0x05, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x1a, 0x1c, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
-	0x54, 0x6f, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72,
-	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x40, 0x0a, 0x0e, 0x41, 0x64, 0x64,
-	0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x2e, 0x41, 0x64,
-	0x64, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x41, 0x64, 0x64, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e,
-	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x11, 0x55,
-	0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
-	0x12, 0x19, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e,
-	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x05, 0x2e, 0x56, 0x6f,
-	0x69, 0x64, 0x22, 0x00, 0x42, 0x08, 0x5a, 0x06, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x54, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x53, 0x74, 0x72, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x29, 0x0a, 0x0b, 0x45, 0x6e, 0x76, 0x69,
+	0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x05, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x1a, 0x11,
+	0x2e, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x12, 0x41, 0x0a, 0x16, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x45,
+	0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x12, 0x05, 0x2e,
+	0x56, 0x6f, 0x69, 0x64, 0x1a, 0x1c, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x45,
+	0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x40, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x45, 0x6e, 0x76,
+	0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x2e, 0x41, 0x64, 0x64, 0x45, 0x6e,
+	0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x14, 0x2e, 0x41, 0x64, 0x64, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x37, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x19, 0x2e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x45, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x05, 0x2e, 0x56, 0x6f, 0x69, 0x64, 0x22,
+	0x00, 0x12, 0x43, 0x0a, 0x0f, 0x41, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x57, 0x6f, 0x72, 0x6b,
+	0x66, 0x6c, 0x6f, 0x77, 0x12, 0x17, 0x2e, 0x41, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x57, 0x6f,
+	0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
+	0x41, 0x64, 0x76, 0x61, 0x6e, 0x63, 0x65, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x46, 0x0a, 0x10, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c,
+	0x6f, 0x77, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x2e, 0x57, 0x6f, 0x72,
+	0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x66, 0x6c, 0x6f, 0x77, 0x49,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x08,
+	0x5a, 0x06, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -2059,109 +3518,144 @@ func file_control_proto_rawDescGZIP() []byte {
 	return file_control_proto_rawDescData
 }
 
-var file_control_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_control_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_control_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_control_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_control_proto_goTypes = []interface{}{
 	(StorageType)(0),                    // 0: StorageType
 	(RuntimeType)(0),                    // 1: RuntimeType
 	(Platform)(0),                       // 2: Platform
 	(DataType)(0),                       // 3: DataType
-	(*UpdateToEnvironmentStrReply)(nil), // 4: UpdateToEnvironmentStrReply
-	(*UpdateToPackagesStrRequest)(nil),  // 5: UpdateToPackagesStrRequest
-	(*UpdateToPackagesStrReply)(nil),    // 6: UpdateToPackagesStrReply
-	(*AddPackageRequest)(nil),           // 7: AddPackageRequest
-	(*AddPackageReply)(nil),             // 8: AddPackageReply
-	(*AllPackagesReply)(nil),            // 9: AllPackagesReply
-	(*PackagesRequest)(nil),             // 10: PackagesRequest
-	(*PackagesReply)(nil),               // 11: PackagesReply
-	(*UpdatePackageRequest)(nil),        // 12: UpdatePackageRequest
-	(*DeletePackageRequest)(nil),        // 13: DeletePackageRequest
-	(*EnvironmentReply)(nil),            // 14: EnvironmentReply
-	(*AddEnvironmentRequest)(nil),       // 15: AddEnvironmentRequest
-	(*UpdateEnvironmentRequest)(nil),    // 16: UpdateEnvironmentRequest
-	(*AddEnvironmentReply)(nil),         // 17: AddEnvironmentReply
-	(*TenantsRequest)(nil),              // 18: TenantsRequest
-	(*TenantsReply)(nil),                // 19: TenantsReply
-	(*AddTenantRequest)(nil),            // 20: AddTenantRequest
-	(*AddTenantReply)(nil),              // 21: AddTenantReply
-	(*Environment)(nil),                 // 22: Environment
-	(*Service)(nil),                     // 23: Service
-	(*Storage)(nil),                     // 24: Storage
-	(*JobPackage)(nil),                  // 25: JobPackage
-	(*Tenant)(nil),                      // 26: Tenant
-	(*QueueDef)(nil),                    // 27: QueueDef
-	(*RuntimeDef)(nil),                  // 28: RuntimeDef
-	(*JobDef)(nil),                      // 29: JobDef
-	(*ResultDef)(nil),                   // 30: ResultDef
-	(*EventDef)(nil),                    // 31: EventDef
-	(*SchemaDef)(nil),                   // 32: SchemaDef
-	(UpdateType)(0),                     // 33: UpdateType
-	(*Host)(nil),                        // 34: Host
-	(*Void)(nil),                        // 35: Void
+	(QueueDef_Lifecycle)(0),             // 4: QueueDef.Lifecycle
+	(QueueDef_Overflow)(0),              // 5: QueueDef.Overflow
+	(WorkflowInstance_Status)(0),        // 6: WorkflowInstance.Status
+	(ResultDef_Payload)(0),              // 7: ResultDef.Payload
+	(*UpdateToEnvironmentStrReply)(nil), // 8: UpdateToEnvironmentStrReply
+	(*UpdateToPackagesStrRequest)(nil),  // 9: UpdateToPackagesStrRequest
+	(*UpdateToPackagesStrReply)(nil),    // 10: UpdateToPackagesStrReply
+	(*AddPackageRequest)(nil),           // 11: AddPackageRequest
+	(*AddPackageReply)(nil),             // 12: AddPackageReply
+	(*AllPackagesReply)(nil),            // 13: AllPackagesReply
+	(*PackagesRequest)(nil),             // 14: PackagesRequest
+	(*PackagesReply)(nil),               // 15: PackagesReply
+	(*UpdatePackageRequest)(nil),        // 16: UpdatePackageRequest
+	(*DeletePackageRequest)(nil),        // 17: DeletePackageRequest
+	(*AdvanceWorkflowRequest)(nil),      // 18: AdvanceWorkflowRequest
+	(*AdvanceWorkflowReply)(nil),        // 19: AdvanceWorkflowReply
+	(*WorkflowInstanceRequest)(nil),     // 20: WorkflowInstanceRequest
+	(*WorkflowInstanceReply)(nil),       // 21: WorkflowInstanceReply
+	(*EnvironmentReply)(nil),            // 22: EnvironmentReply
+	(*AddEnvironmentRequest)(nil),       // 23: AddEnvironmentRequest
+	(*UpdateEnvironmentRequest)(nil),    // 24: UpdateEnvironmentRequest
+	(*AddEnvironmentReply)(nil),         // 25: AddEnvironmentReply
+	(*TenantsRequest)(nil),              // 26: TenantsRequest
+	(*TenantsReply)(nil),                // 27: TenantsReply
+	(*AddTenantRequest)(nil),            // 28: AddTenantRequest
+	(*AddTenantReply)(nil),              // 29: AddTenantReply
+	(*Environment)(nil),                 // 30: Environment
+	(*Service)(nil),                     // 31: Service
+	(*Storage)(nil),                     // 32: Storage
+	(*JobPackage)(nil),                  // 33: JobPackage
+	(*Tenant)(nil),                      // 34: Tenant
+	(*QueueDef)(nil),                    // 35: QueueDef
+	(*RuntimeDef)(nil),                  // 36: RuntimeDef
+	(*RuntimeLimits)(nil),               // 37: RuntimeLimits
+	(*JobDef)(nil),                      // 38: JobDef
+	(*RetryPolicy)(nil),                 // 39: RetryPolicy
+	(*WorkflowDef)(nil),                 // 40: WorkflowDef
+	(*StepDef)(nil),                     // 41: StepDef
+	(*RouteDef)(nil),                    // 42: RouteDef
+	(*WorkflowInstance)(nil),            // 43: WorkflowInstance
+	(*StepRun)(nil),                     // 44: StepRun
+	(*StepStart)(nil),                   // 45: StepStart
+	(*ResultDef)(nil),                   // 46: ResultDef
+	(*EventDef)(nil),                    // 47: EventDef
+	(*SchemaDef)(nil),                   // 48: SchemaDef
+	(UpdateType)(0),                     // 49: UpdateType
+	(*Host)(nil),                        // 50: Host
+	(*timestamppb.Timestamp)(nil),       // 51: google.protobuf.Timestamp
+	(*Void)(nil),                        // 52: Void
 }
 var file_control_proto_depIdxs = []int32{
-	33, // 0: UpdateToEnvironmentStrReply.type:type_name -> UpdateType
-	22, // 1: UpdateToEnvironmentStrReply.object:type_name -> Environment
-	33, // 2: UpdateToPackagesStrReply.type:type_name -> UpdateType
-	25, // 3: UpdateToPackagesStrReply.object:type_name -> JobPackage
-	25, // 4: AddPackageRequest.package:type_name -> JobPackage
-	25, // 5: AddPackageReply.package:type_name -> JobPackage
-	25, // 6: AllPackagesReply.packages:type_name -> JobPackage
-	25, // 7: PackagesReply.packages:type_name -> JobPackage
-	25, // 8: UpdatePackageRequest.package:type_name -> JobPackage
-	25, // 9: DeletePackageRequest.package:type_name -> JobPackage
-	22, // 10: EnvironmentReply.environment:type_name -> Environment
-	22, // 11: AddEnvironmentRequest.environment:type_name -> Environment
-	22, // 12: UpdateEnvironmentRequest.environment:type_name -> Environment
-	22, // 13: AddEnvironmentReply.environment:type_name -> Environment
-	26, // 14: TenantsReply.Tenants:type_name -> Tenant
-	26, // 15: AddTenantRequest.tenant:type_name -> Tenant
-	26, // 16: AddTenantReply.tenant:type_name -> Tenant
-	23, // 17: Environment.services:type_name -> Service
-	26, // 18: Environment.tenant:type_name -> Tenant
-	34, // 19: Service.servers:type_name -> Host
-	24, // 20: Service.storages:type_name -> Storage
-	0,  // 21: Storage.type:type_name -> StorageType
-	27, // 22: JobPackage.queues:type_name -> QueueDef
-	29, // 23: JobPackage.jobs:type_name -> JobDef
-	28, // 24: JobPackage.runtimes:type_name -> RuntimeDef
-	1,  // 25: RuntimeDef.type:type_name -> RuntimeType
-	2,  // 26: RuntimeDef.platform:type_name -> Platform
-	31, // 27: JobDef.event:type_name -> EventDef
-	30, // 28: JobDef.result:type_name -> ResultDef
-	31, // 29: ResultDef.ok:type_name -> EventDef
-	31, // 30: ResultDef.error:type_name -> EventDef
-	3,  // 31: EventDef.dataType:type_name -> DataType
-	32, // 32: EventDef.schema:type_name -> SchemaDef
-	18, // 33: Control.Tenants:input_type -> TenantsRequest
-	20, // 34: Control.AddTenant:input_type -> AddTenantRequest
-	7,  // 35: Control.AddPackage:input_type -> AddPackageRequest
-	35, // 36: Control.AllPackages:input_type -> Void
-	10, // 37: Control.Packages:input_type -> PackagesRequest
-	12, // 38: Control.UpdatePackage:input_type -> UpdatePackageRequest
-	13, // 39: Control.DeletePackage:input_type -> DeletePackageRequest
-	5,  // 40: Control.UpdateToPackagesStr:input_type -> UpdateToPackagesStrRequest
-	35, // 41: Control.Environment:input_type -> Void
-	35, // 42: Control.UpdateToEnvironmentStr:input_type -> Void
-	15, // 43: Control.AddEnvironment:input_type -> AddEnvironmentRequest
-	16, // 44: Control.UpdateEnvironment:input_type -> UpdateEnvironmentRequest
-	19, // 45: Control.Tenants:output_type -> TenantsReply
-	21, // 46: Control.AddTenant:output_type -> AddTenantReply
-	8,  // 47: Control.AddPackage:output_type -> AddPackageReply
-	9,  // 48: Control.AllPackages:output_type -> AllPackagesReply
-	11, // 49: Control.Packages:output_type -> PackagesReply
-	35, // 50: Control.UpdatePackage:output_type -> Void
-	35, // 51: Control.DeletePackage:output_type -> Void
-	6,  // 52: Control.UpdateToPackagesStr:output_type -> UpdateToPackagesStrReply
-	14, // 53: Control.Environment:output_type -> EnvironmentReply
-	4,  // 54: Control.UpdateToEnvironmentStr:output_type -> UpdateToEnvironmentStrReply
-	17, // 55: Control.AddEnvironment:output_type -> AddEnvironmentReply
-	35, // 56: Control.UpdateEnvironment:output_type -> Void
-	45, // [45:57] is the sub-list for method output_type
-	33, // [33:45] is the sub-list for method input_type
-	33, // [33:33] is the sub-list for extension type_name
-	33, // [33:33] is the sub-list for extension extendee
-	0,  // [0:33] is the sub-list for field type_name
+	49, // 0: UpdateToEnvironmentStrReply.type:type_name -> UpdateType
+	30, // 1: UpdateToEnvironmentStrReply.object:type_name -> Environment
+	49, // 2: UpdateToPackagesStrReply.type:type_name -> UpdateType
+	33, // 3: UpdateToPackagesStrReply.object:type_name -> JobPackage
+	33, // 4: AddPackageRequest.package:type_name -> JobPackage
+	33, // 5: AddPackageReply.package:type_name -> JobPackage
+	33, // 6: AllPackagesReply.packages:type_name -> JobPackage
+	33, // 7: PackagesReply.packages:type_name -> JobPackage
+	33, // 8: UpdatePackageRequest.package:type_name -> JobPackage
+	33, // 9: DeletePackageRequest.package:type_name -> JobPackage
+	45, // 10: AdvanceWorkflowReply.starts:type_name -> StepStart
+	43, // 11: WorkflowInstanceReply.instance:type_name -> WorkflowInstance
+	30, // 12: EnvironmentReply.environment:type_name -> Environment
+	30, // 13: AddEnvironmentRequest.environment:type_name -> Environment
+	30, // 14: UpdateEnvironmentRequest.environment:type_name -> Environment
+	30, // 15: AddEnvironmentReply.environment:type_name -> Environment
+	34, // 16: TenantsReply.Tenants:type_name -> Tenant
+	34, // 17: AddTenantRequest.tenant:type_name -> Tenant
+	34, // 18: AddTenantReply.tenant:type_name -> Tenant
+	31, // 19: Environment.services:type_name -> Service
+	34, // 20: Environment.tenant:type_name -> Tenant
+	50, // 21: Service.servers:type_name -> Host
+	32, // 22: Service.storages:type_name -> Storage
+	0,  // 23: Storage.type:type_name -> StorageType
+	35, // 24: JobPackage.queues:type_name -> QueueDef
+	38, // 25: JobPackage.jobs:type_name -> JobDef
+	36, // 26: JobPackage.runtimes:type_name -> RuntimeDef
+	40, // 27: JobPackage.workflows:type_name -> WorkflowDef
+	4,  // 28: QueueDef.onDelete:type_name -> QueueDef.Lifecycle
+	5,  // 29: QueueDef.overflow:type_name -> QueueDef.Overflow
+	1,  // 30: RuntimeDef.type:type_name -> RuntimeType
+	2,  // 31: RuntimeDef.platform:type_name -> Platform
+	37, // 32: RuntimeDef.limits:type_name -> RuntimeLimits
+	47, // 33: JobDef.event:type_name -> EventDef
+	46, // 34: JobDef.result:type_name -> ResultDef
+	39, // 35: JobDef.retry:type_name -> RetryPolicy
+	41, // 36: WorkflowDef.steps:type_name -> StepDef
+	42, // 37: StepDef.routes:type_name -> RouteDef
+	6,  // 38: WorkflowInstance.status:type_name -> WorkflowInstance.Status
+	44, // 39: WorkflowInstance.steps:type_name -> StepRun
+	51, // 40: WorkflowInstance.completedAt:type_name -> google.protobuf.Timestamp
+	45, // 41: StepRun.starts:type_name -> StepStart
+	47, // 42: ResultDef.ok:type_name -> EventDef
+	47, // 43: ResultDef.error:type_name -> EventDef
+	7,  // 44: ResultDef.payload:type_name -> ResultDef.Payload
+	3,  // 45: EventDef.dataType:type_name -> DataType
+	48, // 46: EventDef.schema:type_name -> SchemaDef
+	26, // 47: Control.Tenants:input_type -> TenantsRequest
+	28, // 48: Control.AddTenant:input_type -> AddTenantRequest
+	11, // 49: Control.AddPackage:input_type -> AddPackageRequest
+	52, // 50: Control.AllPackages:input_type -> Void
+	14, // 51: Control.Packages:input_type -> PackagesRequest
+	16, // 52: Control.UpdatePackage:input_type -> UpdatePackageRequest
+	17, // 53: Control.DeletePackage:input_type -> DeletePackageRequest
+	9,  // 54: Control.UpdateToPackagesStr:input_type -> UpdateToPackagesStrRequest
+	52, // 55: Control.Environment:input_type -> Void
+	52, // 56: Control.UpdateToEnvironmentStr:input_type -> Void
+	23, // 57: Control.AddEnvironment:input_type -> AddEnvironmentRequest
+	24, // 58: Control.UpdateEnvironment:input_type -> UpdateEnvironmentRequest
+	18, // 59: Control.AdvanceWorkflow:input_type -> AdvanceWorkflowRequest
+	20, // 60: Control.WorkflowInstance:input_type -> WorkflowInstanceRequest
+	27, // 61: Control.Tenants:output_type -> TenantsReply
+	29, // 62: Control.AddTenant:output_type -> AddTenantReply
+	12, // 63: Control.AddPackage:output_type -> AddPackageReply
+	13, // 64: Control.AllPackages:output_type -> AllPackagesReply
+	15, // 65: Control.Packages:output_type -> PackagesReply
+	52, // 66: Control.UpdatePackage:output_type -> Void
+	52, // 67: Control.DeletePackage:output_type -> Void
+	10, // 68: Control.UpdateToPackagesStr:output_type -> UpdateToPackagesStrReply
+	22, // 69: Control.Environment:output_type -> EnvironmentReply
+	8,  // 70: Control.UpdateToEnvironmentStr:output_type -> UpdateToEnvironmentStrReply
+	25, // 71: Control.AddEnvironment:output_type -> AddEnvironmentReply
+	52, // 72: Control.UpdateEnvironment:output_type -> Void
+	19, // 73: Control.AdvanceWorkflow:output_type -> AdvanceWorkflowReply
+	21, // 74: Control.WorkflowInstance:output_type -> WorkflowInstanceReply
+	61, // [61:75] is the sub-list for method output_type
+	47, // [47:61] is the sub-list for method input_type
+	47, // [47:47] is the sub-list for extension type_name
+	47, // [47:47] is the sub-list for extension extendee
+	0,  // [0:47] is the sub-list for field type_name
 }
 
 func init() { file_control_proto_init() }
@@ -2292,7 +3786,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EnvironmentReply); i {
+			switch v := v.(*AdvanceWorkflowRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2304,7 +3798,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AddEnvironmentRequest); i {
+			switch v := v.(*AdvanceWorkflowReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2316,7 +3810,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateEnvironmentRequest); i {
+			switch v := v.(*WorkflowInstanceRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2328,7 +3822,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AddEnvironmentReply); i {
+			switch v := v.(*WorkflowInstanceReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2340,7 +3834,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TenantsRequest); i {
+			switch v := v.(*EnvironmentReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2352,7 +3846,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TenantsReply); i {
+			switch v := v.(*AddEnvironmentRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2364,7 +3858,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AddTenantRequest); i {
+			switch v := v.(*UpdateEnvironmentRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2376,7 +3870,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AddTenantReply); i {
+			switch v := v.(*AddEnvironmentReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2388,7 +3882,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Environment); i {
+			switch v := v.(*TenantsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2400,7 +3894,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Service); i {
+			switch v := v.(*TenantsReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2412,7 +3906,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Storage); i {
+			switch v := v.(*AddTenantRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2424,7 +3918,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JobPackage); i {
+			switch v := v.(*AddTenantReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2436,7 +3930,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Tenant); i {
+			switch v := v.(*Environment); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2448,7 +3942,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueueDef); i {
+			switch v := v.(*Service); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2460,7 +3954,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RuntimeDef); i {
+			switch v := v.(*Storage); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2472,7 +3966,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JobDef); i {
+			switch v := v.(*JobPackage); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2484,7 +3978,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ResultDef); i {
+			switch v := v.(*Tenant); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2496,7 +3990,7 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EventDef); i {
+			switch v := v.(*QueueDef); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2508,6 +4002,150 @@ func file_control_proto_init() {
 			}
 		}
 		file_control_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuntimeDef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuntimeLimits); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobDef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RetryPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WorkflowDef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StepDef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RouteDef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WorkflowInstance); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StepRun); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StepStart); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResultDef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EventDef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_control_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SchemaDef); i {
 			case 0:
 				return &v.state
@@ -2521,12 +4159,9 @@ func file_control_proto_init() {
 		}
 	}
 	file_control_proto_msgTypes[6].OneofWrappers = []interface{}{}
-	file_control_proto_msgTypes[10].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[13].OneofWrappers = []interface{}{}
 	file_control_proto_msgTypes[14].OneofWrappers = []interface{}{}
 	file_control_proto_msgTypes[18].OneofWrappers = []interface{}{}
-	file_control_proto_msgTypes[19].OneofWrappers = []interface{}{}
-	file_control_proto_msgTypes[20].OneofWrappers = []interface{}{}
-	file_control_proto_msgTypes[21].OneofWrappers = []interface{}{}
 	file_control_proto_msgTypes[22].OneofWrappers = []interface{}{}
 	file_control_proto_msgTypes[23].OneofWrappers = []interface{}{}
 	file_control_proto_msgTypes[24].OneofWrappers = []interface{}{}
@@ -2534,13 +4169,22 @@ func file_control_proto_init() {
 	file_control_proto_msgTypes[26].OneofWrappers = []interface{}{}
 	file_control_proto_msgTypes[27].OneofWrappers = []interface{}{}
 	file_control_proto_msgTypes[28].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[30].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[31].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[32].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[34].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[35].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[37].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[38].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[39].OneofWrappers = []interface{}{}
+	file_control_proto_msgTypes[40].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_control_proto_rawDesc,
-			NumEnums:      4,
-			NumMessages:   29,
+			NumEnums:      8,
+			NumMessages:   41,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -31,6 +31,8 @@ const (
 	Control_UpdateToEnvironmentStr_FullMethodName = "/Control/UpdateToEnvironmentStr"
 	Control_AddEnvironment_FullMethodName         = "/Control/AddEnvironment"
 	Control_UpdateEnvironment_FullMethodName      = "/Control/UpdateEnvironment"
+	Control_AdvanceWorkflow_FullMethodName        = "/Control/AdvanceWorkflow"
+	Control_WorkflowInstance_FullMethodName       = "/Control/WorkflowInstance"
 )
 
 // ControlClient is the client API for Control service.
@@ -49,6 +51,8 @@ type ControlClient interface {
 	UpdateToEnvironmentStr(ctx context.Context, in *Void, opts ...grpc.CallOption) (Control_UpdateToEnvironmentStrClient, error)
 	AddEnvironment(ctx context.Context, in *AddEnvironmentRequest, opts ...grpc.CallOption) (*AddEnvironmentReply, error)
 	UpdateEnvironment(ctx context.Context, in *UpdateEnvironmentRequest, opts ...grpc.CallOption) (*Void, error)
+	AdvanceWorkflow(ctx context.Context, in *AdvanceWorkflowRequest, opts ...grpc.CallOption) (*AdvanceWorkflowReply, error)
+	WorkflowInstance(ctx context.Context, in *WorkflowInstanceRequest, opts ...grpc.CallOption) (*WorkflowInstanceReply, error)
 }
 
 type controlClient struct {
@@ -213,6 +217,24 @@ func (c *controlClient) UpdateEnvironment(ctx context.Context, in *UpdateEnviron
 	return out, nil
 }
 
+func (c *controlClient) AdvanceWorkflow(ctx context.Context, in *AdvanceWorkflowRequest, opts ...grpc.CallOption) (*AdvanceWorkflowReply, error) {
+	out := new(AdvanceWorkflowReply)
+	err := c.cc.Invoke(ctx, Control_AdvanceWorkflow_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) WorkflowInstance(ctx context.Context, in *WorkflowInstanceRequest, opts ...grpc.CallOption) (*WorkflowInstanceReply, error) {
+	out := new(WorkflowInstanceReply)
+	err := c.cc.Invoke(ctx, Control_WorkflowInstance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ControlServer is the server API for Control service.
 // All implementations must embed UnimplementedControlServer
 // for forward compatibility
@@ -229,6 +251,8 @@ type ControlServer interface {
 	UpdateToEnvironmentStr(*Void, Control_UpdateToEnvironmentStrServer) error
 	AddEnvironment(context.Context, *AddEnvironmentRequest) (*AddEnvironmentReply, error)
 	UpdateEnvironment(context.Context, *UpdateEnvironmentRequest) (*Void, error)
+	AdvanceWorkflow(context.Context, *AdvanceWorkflowRequest) (*AdvanceWorkflowReply, error)
+	WorkflowInstance(context.Context, *WorkflowInstanceRequest) (*WorkflowInstanceReply, error)
 	mustEmbedUnimplementedControlServer()
 }
 
@@ -272,6 +296,12 @@ func (UnimplementedControlServer) AddEnvironment(context.Context, *AddEnvironmen
 func (UnimplementedControlServer) UpdateEnvironment(context.Context, *UpdateEnvironmentRequest) (*Void, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateEnvironment not implemented")
 }
+func (UnimplementedControlServer) AdvanceWorkflow(context.Context, *AdvanceWorkflowRequest) (*AdvanceWorkflowReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdvanceWorkflow not implemented")
+}
+func (UnimplementedControlServer) WorkflowInstance(context.Context, *WorkflowInstanceRequest) (*WorkflowInstanceReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WorkflowInstance not implemented")
+}
 func (UnimplementedControlServer) mustEmbedUnimplementedControlServer() {}
 
 // UnsafeControlServer may be embedded to opt out of forward compatibility for this service.
@@ -507,6 +537,42 @@ func _Control_UpdateEnvironment_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Control_AdvanceWorkflow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdvanceWorkflowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).AdvanceWorkflow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Control_AdvanceWorkflow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).AdvanceWorkflow(ctx, req.(*AdvanceWorkflowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_WorkflowInstance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WorkflowInstanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).WorkflowInstance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Control_WorkflowInstance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).WorkflowInstance(ctx, req.(*WorkflowInstanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Control_ServiceDesc is the grpc.ServiceDesc for Control service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -554,6 +620,14 @@ var Control_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateEnvironment",
 			Handler:    _Control_UpdateEnvironment_Handler,
 		},
+		{
+			MethodName: "AdvanceWorkflow",
+			Handler:    _Control_AdvanceWorkflow_Handler,
+		},
+		{
+			MethodName: "WorkflowInstance",
+			Handler:    _Control_WorkflowInstance_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
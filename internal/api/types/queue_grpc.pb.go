@@ -19,16 +19,47 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Queue_Queue_FullMethodName   = "/Queue/Queue"
-	Queue_Dequeue_FullMethodName = "/Queue/Dequeue"
+	Queue_Queue_FullMethodName              = "/Queue/Queue"
+	Queue_Dequeue_FullMethodName            = "/Queue/Dequeue"
+	Queue_Ack_FullMethodName                = "/Queue/Ack"
+	Queue_Nack_FullMethodName               = "/Queue/Nack"
+	Queue_DeadLetters_FullMethodName        = "/Queue/DeadLetters"
+	Queue_RequeueDeadLetters_FullMethodName = "/Queue/RequeueDeadLetters"
+	Queue_PurgeDeadLetters_FullMethodName   = "/Queue/PurgeDeadLetters"
+	Queue_Subscribe_FullMethodName          = "/Queue/Subscribe"
+	Queue_Stats_FullMethodName              = "/Queue/Stats"
+	Queue_Peek_FullMethodName               = "/Queue/Peek"
+	Queue_Purge_FullMethodName              = "/Queue/Purge"
+	Queue_Quarantined_FullMethodName        = "/Queue/Quarantined"
+	Queue_RequeueQuarantined_FullMethodName = "/Queue/RequeueQuarantined"
+	Queue_DeleteQuarantined_FullMethodName  = "/Queue/DeleteQuarantined"
+	Queue_Replicate_FullMethodName          = "/Queue/Replicate"
+	Queue_Promote_FullMethodName            = "/Queue/Promote"
 )
 
 // QueueClient is the client API for Queue service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type QueueClient interface {
-	Queue(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (*Void, error)
+	Queue(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (*QueueReply, error)
 	Dequeue(ctx context.Context, in *DequeueRequest, opts ...grpc.CallOption) (*DequeueReply, error)
+	Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*Void, error)
+	Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*Void, error)
+	DeadLetters(ctx context.Context, in *DeadLettersRequest, opts ...grpc.CallOption) (*DeadLettersReply, error)
+	RequeueDeadLetters(ctx context.Context, in *RequeueDeadLettersRequest, opts ...grpc.CallOption) (*RequeueDeadLettersReply, error)
+	PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*PurgeDeadLettersReply, error)
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Queue_SubscribeClient, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsReply, error)
+	Peek(ctx context.Context, in *PeekRequest, opts ...grpc.CallOption) (*PeekReply, error)
+	Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeReply, error)
+	// Lists the items of a queue that could not be decoded.
+	Quarantined(ctx context.Context, in *QuarantinedRequest, opts ...grpc.CallOption) (*QuarantinedReply, error)
+	RequeueQuarantined(ctx context.Context, in *RequeueQuarantinedRequest, opts ...grpc.CallOption) (*Void, error)
+	DeleteQuarantined(ctx context.Context, in *DeleteQuarantinedRequest, opts ...grpc.CallOption) (*Void, error)
+	// Applies the changes of the primary node to a replica.
+	Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (*Void, error)
+	// Turns a replica into the primary node.
+	Promote(ctx context.Context, in *Void, opts ...grpc.CallOption) (*Void, error)
 }
 
 type queueClient struct {
@@ -39,8 +70,8 @@ func NewQueueClient(cc grpc.ClientConnInterface) QueueClient {
 	return &queueClient{cc}
 }
 
-func (c *queueClient) Queue(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (*Void, error) {
-	out := new(Void)
+func (c *queueClient) Queue(ctx context.Context, in *QueueRequest, opts ...grpc.CallOption) (*QueueReply, error) {
+	out := new(QueueReply)
 	err := c.cc.Invoke(ctx, Queue_Queue_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -57,12 +88,178 @@ func (c *queueClient) Dequeue(ctx context.Context, in *DequeueRequest, opts ...g
 	return out, nil
 }
 
+func (c *queueClient) Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*Void, error) {
+	out := new(Void)
+	err := c.cc.Invoke(ctx, Queue_Ack_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*Void, error) {
+	out := new(Void)
+	err := c.cc.Invoke(ctx, Queue_Nack_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) DeadLetters(ctx context.Context, in *DeadLettersRequest, opts ...grpc.CallOption) (*DeadLettersReply, error) {
+	out := new(DeadLettersReply)
+	err := c.cc.Invoke(ctx, Queue_DeadLetters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) RequeueDeadLetters(ctx context.Context, in *RequeueDeadLettersRequest, opts ...grpc.CallOption) (*RequeueDeadLettersReply, error) {
+	out := new(RequeueDeadLettersReply)
+	err := c.cc.Invoke(ctx, Queue_RequeueDeadLetters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*PurgeDeadLettersReply, error) {
+	out := new(PurgeDeadLettersReply)
+	err := c.cc.Invoke(ctx, Queue_PurgeDeadLetters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Queue_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Queue_ServiceDesc.Streams[0], Queue_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &queueSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Queue_SubscribeClient interface {
+	Recv() (*DequeuedItem, error)
+	grpc.ClientStream
+}
+
+type queueSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *queueSubscribeClient) Recv() (*DequeuedItem, error) {
+	m := new(DequeuedItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *queueClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsReply, error) {
+	out := new(StatsReply)
+	err := c.cc.Invoke(ctx, Queue_Stats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Peek(ctx context.Context, in *PeekRequest, opts ...grpc.CallOption) (*PeekReply, error) {
+	out := new(PeekReply)
+	err := c.cc.Invoke(ctx, Queue_Peek_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeReply, error) {
+	out := new(PurgeReply)
+	err := c.cc.Invoke(ctx, Queue_Purge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Quarantined(ctx context.Context, in *QuarantinedRequest, opts ...grpc.CallOption) (*QuarantinedReply, error) {
+	out := new(QuarantinedReply)
+	err := c.cc.Invoke(ctx, Queue_Quarantined_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) RequeueQuarantined(ctx context.Context, in *RequeueQuarantinedRequest, opts ...grpc.CallOption) (*Void, error) {
+	out := new(Void)
+	err := c.cc.Invoke(ctx, Queue_RequeueQuarantined_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) DeleteQuarantined(ctx context.Context, in *DeleteQuarantinedRequest, opts ...grpc.CallOption) (*Void, error) {
+	out := new(Void)
+	err := c.cc.Invoke(ctx, Queue_DeleteQuarantined_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (*Void, error) {
+	out := new(Void)
+	err := c.cc.Invoke(ctx, Queue_Replicate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queueClient) Promote(ctx context.Context, in *Void, opts ...grpc.CallOption) (*Void, error) {
+	out := new(Void)
+	err := c.cc.Invoke(ctx, Queue_Promote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // QueueServer is the server API for Queue service.
 // All implementations must embed UnimplementedQueueServer
 // for forward compatibility
 type QueueServer interface {
-	Queue(context.Context, *QueueRequest) (*Void, error)
+	Queue(context.Context, *QueueRequest) (*QueueReply, error)
 	Dequeue(context.Context, *DequeueRequest) (*DequeueReply, error)
+	Ack(context.Context, *AckRequest) (*Void, error)
+	Nack(context.Context, *NackRequest) (*Void, error)
+	DeadLetters(context.Context, *DeadLettersRequest) (*DeadLettersReply, error)
+	RequeueDeadLetters(context.Context, *RequeueDeadLettersRequest) (*RequeueDeadLettersReply, error)
+	PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*PurgeDeadLettersReply, error)
+	Subscribe(*SubscribeRequest, Queue_SubscribeServer) error
+	Stats(context.Context, *StatsRequest) (*StatsReply, error)
+	Peek(context.Context, *PeekRequest) (*PeekReply, error)
+	Purge(context.Context, *PurgeRequest) (*PurgeReply, error)
+	// Lists the items of a queue that could not be decoded.
+	Quarantined(context.Context, *QuarantinedRequest) (*QuarantinedReply, error)
+	RequeueQuarantined(context.Context, *RequeueQuarantinedRequest) (*Void, error)
+	DeleteQuarantined(context.Context, *DeleteQuarantinedRequest) (*Void, error)
+	// Applies the changes of the primary node to a replica.
+	Replicate(context.Context, *ReplicateRequest) (*Void, error)
+	// Turns a replica into the primary node.
+	Promote(context.Context, *Void) (*Void, error)
 	mustEmbedUnimplementedQueueServer()
 }
 
@@ -70,12 +267,54 @@ type QueueServer interface {
 type UnimplementedQueueServer struct {
 }
 
-func (UnimplementedQueueServer) Queue(context.Context, *QueueRequest) (*Void, error) {
+func (UnimplementedQueueServer) Queue(context.Context, *QueueRequest) (*QueueReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Queue not implemented")
 }
 func (UnimplementedQueueServer) Dequeue(context.Context, *DequeueRequest) (*DequeueReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Dequeue not implemented")
 }
+func (UnimplementedQueueServer) Ack(context.Context, *AckRequest) (*Void, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ack not implemented")
+}
+func (UnimplementedQueueServer) Nack(context.Context, *NackRequest) (*Void, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Nack not implemented")
+}
+func (UnimplementedQueueServer) DeadLetters(context.Context, *DeadLettersRequest) (*DeadLettersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeadLetters not implemented")
+}
+func (UnimplementedQueueServer) RequeueDeadLetters(context.Context, *RequeueDeadLettersRequest) (*RequeueDeadLettersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequeueDeadLetters not implemented")
+}
+func (UnimplementedQueueServer) PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*PurgeDeadLettersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeDeadLetters not implemented")
+}
+func (UnimplementedQueueServer) Subscribe(*SubscribeRequest, Queue_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedQueueServer) Stats(context.Context, *StatsRequest) (*StatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedQueueServer) Peek(context.Context, *PeekRequest) (*PeekReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Peek not implemented")
+}
+func (UnimplementedQueueServer) Purge(context.Context, *PurgeRequest) (*PurgeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purge not implemented")
+}
+func (UnimplementedQueueServer) Quarantined(context.Context, *QuarantinedRequest) (*QuarantinedReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Quarantined not implemented")
+}
+func (UnimplementedQueueServer) RequeueQuarantined(context.Context, *RequeueQuarantinedRequest) (*Void, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequeueQuarantined not implemented")
+}
+func (UnimplementedQueueServer) DeleteQuarantined(context.Context, *DeleteQuarantinedRequest) (*Void, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteQuarantined not implemented")
+}
+func (UnimplementedQueueServer) Replicate(context.Context, *ReplicateRequest) (*Void, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Replicate not implemented")
+}
+func (UnimplementedQueueServer) Promote(context.Context, *Void) (*Void, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Promote not implemented")
+}
 func (UnimplementedQueueServer) mustEmbedUnimplementedQueueServer() {}
 
 // UnsafeQueueServer may be embedded to opt out of forward compatibility for this service.
@@ -125,6 +364,261 @@ func _Queue_Dequeue_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Queue_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Ack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Ack(ctx, req.(*AckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Nack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Nack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Nack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Nack(ctx, req.(*NackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_DeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).DeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_DeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).DeadLetters(ctx, req.(*DeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_RequeueDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequeueDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).RequeueDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_RequeueDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).RequeueDeadLetters(ctx, req.(*RequeueDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_PurgeDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).PurgeDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_PurgeDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).PurgeDeadLetters(ctx, req.(*PurgeDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QueueServer).Subscribe(m, &queueSubscribeServer{stream})
+}
+
+type Queue_SubscribeServer interface {
+	Send(*DequeuedItem) error
+	grpc.ServerStream
+}
+
+type queueSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *queueSubscribeServer) Send(m *DequeuedItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Queue_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Peek_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeekRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Peek(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Peek_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Peek(ctx, req.(*PeekRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Purge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Purge(ctx, req.(*PurgeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Quarantined_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuarantinedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Quarantined(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Quarantined_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Quarantined(ctx, req.(*QuarantinedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_RequeueQuarantined_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequeueQuarantinedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).RequeueQuarantined(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_RequeueQuarantined_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).RequeueQuarantined(ctx, req.(*RequeueQuarantinedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_DeleteQuarantined_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteQuarantinedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).DeleteQuarantined(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_DeleteQuarantined_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).DeleteQuarantined(ctx, req.(*DeleteQuarantinedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Replicate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Replicate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Replicate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Replicate(ctx, req.(*ReplicateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Queue_Promote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Void)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueueServer).Promote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Queue_Promote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueueServer).Promote(ctx, req.(*Void))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Queue_ServiceDesc is the grpc.ServiceDesc for Queue service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -140,7 +634,65 @@ var Queue_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Dequeue",
 			Handler:    _Queue_Dequeue_Handler,
 		},
+		{
+			MethodName: "Ack",
+			Handler:    _Queue_Ack_Handler,
+		},
+		{
+			MethodName: "Nack",
+			Handler:    _Queue_Nack_Handler,
+		},
+		{
+			MethodName: "DeadLetters",
+			Handler:    _Queue_DeadLetters_Handler,
+		},
+		{
+			MethodName: "RequeueDeadLetters",
+			Handler:    _Queue_RequeueDeadLetters_Handler,
+		},
+		{
+			MethodName: "PurgeDeadLetters",
+			Handler:    _Queue_PurgeDeadLetters_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Queue_Stats_Handler,
+		},
+		{
+			MethodName: "Peek",
+			Handler:    _Queue_Peek_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _Queue_Purge_Handler,
+		},
+		{
+			MethodName: "Quarantined",
+			Handler:    _Queue_Quarantined_Handler,
+		},
+		{
+			MethodName: "RequeueQuarantined",
+			Handler:    _Queue_RequeueQuarantined_Handler,
+		},
+		{
+			MethodName: "DeleteQuarantined",
+			Handler:    _Queue_DeleteQuarantined_Handler,
+		},
+		{
+			MethodName: "Replicate",
+			Handler:    _Queue_Replicate_Handler,
+		},
+		{
+			MethodName: "Promote",
+			Handler:    _Queue_Promote_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Queue_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "queue.proto",
 }
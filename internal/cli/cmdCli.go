@@ -21,6 +21,7 @@ func newCli() *command {
 		newRecorder(),
 		newShow(),
 		newEnv(),
+		newPromote(),
 	}
 	cliCommand.run = runCli
 	return cliCommand
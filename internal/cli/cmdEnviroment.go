@@ -19,7 +19,7 @@ func newEnv() *command {
 		usageLine: `cli env <file>`,
 		short:     "upload environment information",
 		long: `
-	Uploads environment information. This option is reserved for future usage.`,
+	Uploads environment information. The servers of the queue service are the nodes of the queue cluster.`,
 	}
 	cmdEnv.flag = *flag.NewFlagSet("env1", flag.ContinueOnError)
 	_ = cmdEnv.flag.Bool("update", false, "override deployment")
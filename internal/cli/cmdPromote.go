@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/andrescosta/goico/pkg/service"
+	"github.com/andrescosta/jobico/internal/api/client"
+)
+
+func newPromote() *command {
+	cmdPromote := &command{
+		name:      "promote",
+		usageLine: `cli promote <queue node address>`,
+		short:     "promote a queue replica",
+		long: `
+	The promote command turns a queue replica into the primary node. The items it holds become visible,
+	and it starts accepting requests from clients.`,
+	}
+	cmdPromote.flag = *flag.NewFlagSet("promote", flag.ContinueOnError)
+	cmdPromote.run = runPromote
+	cmdPromote.flag.Usage = func() {}
+	return cmdPromote
+}
+
+func runPromote(ctx context.Context, cmd *command, d service.GrpcDialer, args []string) {
+	if len(args) < 1 {
+		printHelp(os.Stdout, cmd)
+		return
+	}
+	queue, err := client.NewQueueForNode(ctx, d, args[0])
+	if err != nil {
+		printError(os.Stderr, cmd, err)
+		return
+	}
+	defer queue.Close()
+	if err := queue.Promote(ctx); err != nil {
+		printError(os.Stderr, cmd, err)
+		return
+	}
+	fmt.Printf("The replica %s was promoted.\n", args[0])
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/andrescosta/goico/pkg/syncutil"
 	pb "github.com/andrescosta/jobico/internal/api/types"
 	"github.com/andrescosta/jobico/internal/ctl/data"
+	"github.com/andrescosta/jobico/internal/ctl/workflow"
 	"github.com/andrescosta/jobico/pkg/grpchelper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -86,6 +89,9 @@ func (c *PackageController) GetAllPackages() (*pb.AllPackagesReply, error) {
 }
 
 func (c *PackageController) AddPackage(ctx context.Context, in *pb.AddPackageRequest) (*pb.AddPackageReply, error) {
+	if err := workflow.Validate(in.Package); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	mydao, err := c.daoCache.ForTenant(in.Package.Tenant, tblPackage, &pb.JobPackage{})
 	if err != nil {
 		return nil, err
@@ -99,6 +105,9 @@ func (c *PackageController) AddPackage(ctx context.Context, in *pb.AddPackageReq
 }
 
 func (c *PackageController) UpdatePackage(ctx context.Context, in *pb.UpdatePackageRequest) (*pb.Void, error) {
+	if err := workflow.Validate(in.Package); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	mydao, err := c.daoCache.ForTenant(in.Package.Tenant, tblPackage, &pb.JobPackage{})
 	if err != nil {
 		return nil, err
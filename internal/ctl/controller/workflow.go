@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/andrescosta/goico/pkg/database"
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/ctl/data"
+	"github.com/andrescosta/jobico/internal/ctl/workflow"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	tblWorkflow = "workflow"
+)
+
+// WorkflowRetention tells how long the completed instances are kept.
+type WorkflowRetention struct {
+	// Retention is the time a completed instance is kept, so its state can be queried and the
+	// executor can still send its last steps again.
+	Retention time.Duration
+	// Interval is the time between the removals of the expired instances.
+	Interval time.Duration
+}
+
+// WorkflowController keeps the state of the workflow instances, so their steps are routed and joined
+// across executors and restarts.
+type WorkflowController struct {
+	// mu serializes the updates of the instances, because the branches of a join can complete at once.
+	mu                sync.Mutex
+	daoCache          *data.DAOS
+	packageController *PackageController
+	retention         WorkflowRetention
+	cancel            context.CancelFunc
+	done              chan struct{}
+}
+
+func NewWorkflowController(ctx context.Context, db *database.Database, packageController *PackageController, retention WorkflowRetention) *WorkflowController {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &WorkflowController{
+		daoCache:          data.NewDAOS(db),
+		packageController: packageController,
+		retention:         retention,
+		cancel:            cancel,
+		done:              make(chan struct{}),
+	}
+	go c.removeExpired(ctx)
+	return c
+}
+
+func (c *WorkflowController) Close() error {
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *WorkflowController) AdvanceWorkflow(in *pb.AdvanceWorkflowRequest) (*pb.AdvanceWorkflowReply, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pkg, err := c.packageController.getPackage(in.Tenant, in.Package)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, status.Errorf(codes.NotFound, "package %s/%s not found", in.Tenant, in.Package)
+	}
+	wf := findWorkflow(pkg, in.Workflow)
+	if wf == nil {
+		return nil, status.Errorf(codes.NotFound, "workflow %s not found in package %s", in.Workflow, in.Package)
+	}
+	step := workflow.Step(wf, in.Step)
+	if step == nil {
+		return nil, status.Errorf(codes.NotFound, "step %s not found in workflow %s", in.Step, in.Workflow)
+	}
+	instance, err := c.getInstance(in.Tenant, in.Workflow, in.Instance)
+	if err != nil {
+		return nil, err
+	}
+	if instance == nil {
+		if step != wf.Steps[0] {
+			return nil, status.Errorf(codes.FailedPrecondition, "instance %s of workflow %s was not started", in.Instance, in.Workflow)
+		}
+		instance = &pb.WorkflowInstance{
+			ID:       instanceID(in.Workflow, in.Instance),
+			Package:  in.Package,
+			Workflow: in.Workflow,
+			Instance: in.Instance,
+			Active:   1,
+		}
+	}
+	// the executor sends the step again when it could not queue the next ones.
+	if run := stepRun(instance, in.Step); run != nil {
+		return &pb.AdvanceWorkflowReply{Starts: run.Starts}, nil
+	}
+	run := &pb.StepRun{
+		Step:   in.Step,
+		Code:   in.Code,
+		Result: in.Result,
+		Next:   workflow.Next(step, in.Code, in.Result),
+	}
+	instance.Steps = append(instance.Steps, run)
+	for _, next := range run.Next {
+		start, err := startStep(pkg, wf, instance, run, next)
+		if err != nil {
+			return nil, err
+		}
+		if start != nil {
+			run.Starts = append(run.Starts, start)
+		}
+	}
+	instance.Active = instance.Active - 1 + uint32(len(run.Starts))
+	if instance.Active == 0 {
+		instance.Status = pb.WorkflowInstance_Completed
+		instance.CompletedAt = timestamppb.Now()
+	}
+	mydao, err := c.daoCache.ForTenant(in.Tenant, tblWorkflow, &pb.WorkflowInstance{})
+	if err != nil {
+		return nil, err
+	}
+	if err := mydao.Update(instance); err != nil {
+		return nil, err
+	}
+	return &pb.AdvanceWorkflowReply{Starts: run.Starts}, nil
+}
+
+func (c *WorkflowController) WorkflowInstance(in *pb.WorkflowInstanceRequest) (*pb.WorkflowInstanceReply, error) {
+	instance, err := c.getInstance(in.Tenant, in.Workflow, in.Instance)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.WorkflowInstanceReply{Instance: instance}, nil
+}
+
+// removeExpired removes the instances that completed more than the retention ago, every interval.
+func (c *WorkflowController) removeExpired(ctx context.Context) {
+	defer close(c.done)
+	logger := zerolog.Ctx(ctx)
+	ticker := time.NewTicker(c.retention.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.removeCompleted(time.Now().Add(-c.retention.Retention)); err != nil {
+				logger.Warn().Msgf("error removing the completed workflow instances: %s", err)
+			}
+		}
+	}
+}
+
+// removeCompleted removes the instances of every tenant that completed before the given time.
+func (c *WorkflowController) removeCompleted(before time.Time) error {
+	tenants, err := c.packageController.tenantController.getTenants()
+	if err != nil {
+		return err
+	}
+	var errs error
+	for _, t := range tenants {
+		errs = errors.Join(errs, c.removeCompletedOf(t.ID, before))
+	}
+	return errs
+}
+
+func (c *WorkflowController) removeCompletedOf(tenant string, before time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	mydao, err := c.daoCache.ForTenant(tenant, tblWorkflow, &pb.WorkflowInstance{})
+	if err != nil {
+		return err
+	}
+	ms, err := mydao.All()
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		instance := m.(*pb.WorkflowInstance)
+		if instance.Status != pb.WorkflowInstance_Completed || instance.CompletedAt == nil || !instance.CompletedAt.AsTime().Before(before) {
+			continue
+		}
+		if err := mydao.Delete(instance.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *WorkflowController) getInstance(tenant string, wf string, instance string) (*pb.WorkflowInstance, error) {
+	mydao, err := c.daoCache.ForTenant(tenant, tblWorkflow, &pb.WorkflowInstance{})
+	if err != nil {
+		return nil, err
+	}
+	ms, err := mydao.Get(instanceID(wf, instance))
+	if err != nil {
+		return nil, err
+	}
+	if ms != nil {
+		return (*ms).(*pb.WorkflowInstance), nil
+	}
+	return nil, nil
+}
+
+// startStep returns the step to queue after run was routed to next. Joins are started only when the
+// last of their branches arrives, and nil is returned for the rest.
+func startStep(pkg *pb.JobPackage, wf *pb.WorkflowDef, instance *pb.WorkflowInstance, run *pb.StepRun, next string) (*pb.StepStart, error) {
+	step := workflow.Step(wf, next)
+	job := findJob(pkg, step.Event)
+	if job == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "event %s of step %s is not declared by a job", step.Event, step.ID)
+	}
+	start := &pb.StepStart{
+		Step:          step.ID,
+		Event:         step.Event,
+		SupplierQueue: job.Event.SupplierQueue,
+		Data:          []byte(run.Result),
+		PartitionKey:  job.Event.PartitionKey,
+	}
+	if !step.Join {
+		return start, nil
+	}
+	results := make(map[string]string)
+	for _, pred := range workflow.Predecessors(wf)[step.ID] {
+		r := stepRun(instance, pred)
+		if r == nil || !routedTo(r, step.ID) {
+			return nil, nil
+		}
+		results[pred] = r.Result
+	}
+	data, err := workflow.Join(results)
+	if err != nil {
+		return nil, err
+	}
+	start.Data = data
+	return start, nil
+}
+
+func instanceID(wf string, instance string) string {
+	return wf + "/" + instance
+}
+
+// stepRun returns the run of the step. Workflows are acyclic, so a step runs once per instance.
+func stepRun(instance *pb.WorkflowInstance, step string) *pb.StepRun {
+	for _, r := range instance.Steps {
+		if r.Step == step {
+			return r
+		}
+	}
+	return nil
+}
+
+func routedTo(run *pb.StepRun, step string) bool {
+	for _, n := range run.Next {
+		if n == step {
+			return true
+		}
+	}
+	return false
+}
+
+func findWorkflow(pkg *pb.JobPackage, id string) *pb.WorkflowDef {
+	for _, wf := range pkg.Workflows {
+		if wf.ID == id {
+			return wf
+		}
+	}
+	return nil
+}
+
+func findJob(pkg *pb.JobPackage, event string) *pb.JobDef {
+	for _, j := range pkg.Jobs {
+		if j.Event != nil && j.Event.ID == event {
+			return j
+		}
+	}
+	return nil
+}
@@ -15,19 +15,22 @@ type Server struct {
 	pkgControler    *controller.PackageController
 	envControler    *controller.EnvironmentController
 	tenantControler *controller.TenantController
+	wfControler     *controller.WorkflowController
 	ctx             context.Context
 }
 
-func New(ctx context.Context, dbDir string, dbo database.Option) (*Server, error) {
+func New(ctx context.Context, dbDir string, dbo database.Option, retention controller.WorkflowRetention) (*Server, error) {
 	db, err := database.Open(ctx, dbDir, dbo)
 	if err != nil {
 		return nil, err
 	}
+	pkgControler := controller.NewPackageController(ctx, db)
 	return &Server{
 		db:              db,
 		tenantControler: controller.NewTenantController(db),
-		pkgControler:    controller.NewPackageController(ctx, db),
+		pkgControler:    pkgControler,
 		envControler:    controller.NewEnvironmentController(ctx, db),
+		wfControler:     controller.NewWorkflowController(ctx, db, pkgControler, retention),
 		ctx:             ctx,
 	}, nil
 }
@@ -36,6 +39,7 @@ func (c *Server) Close() error {
 	err := errors.Join(c.tenantControler.Close())
 	err = errors.Join(err, c.pkgControler.Close())
 	err = errors.Join(err, c.envControler.Close())
+	err = errors.Join(err, c.wfControler.Close())
 	err = errors.Join(err, c.db.Close())
 	return err
 }
@@ -87,3 +91,11 @@ func (c *Server) UpdateToPackagesStr(req *pb.UpdateToPackagesStrRequest, ctl pb.
 func (c *Server) UpdateToEnvironmentStr(req *pb.Void, ctl pb.Control_UpdateToEnvironmentStrServer) error {
 	return c.envControler.UpdateToEnvironmentStr(req, ctl)
 }
+
+func (c *Server) AdvanceWorkflow(_ context.Context, in *pb.AdvanceWorkflowRequest) (*pb.AdvanceWorkflowReply, error) {
+	return c.wfControler.AdvanceWorkflow(in)
+}
+
+func (c *Server) WorkflowInstance(_ context.Context, in *pb.WorkflowInstanceRequest) (*pb.WorkflowInstanceReply, error) {
+	return c.wfControler.WorkflowInstance(in)
+}
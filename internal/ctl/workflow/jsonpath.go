@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrJSONPath = errors.New("invalid JSONPath")
+
+// segment is a member name or an array index of a JSONPath.
+type segment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// parsePath parses the subset of JSONPath made of the root, member names ($.a or $['a']) and
+// array indexes ($.a[0]).
+func parsePath(path string) ([]segment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("%w %q: it must start with $", ErrJSONPath, path)
+	}
+	segments := make([]segment, 0)
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("%w %q: empty member name", ErrJSONPath, path)
+			}
+			segments = append(segments, segment{name: name})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("%w %q: missing ]", ErrJSONPath, path)
+			}
+			s, err := bracketSegment(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("%w %q: %w", ErrJSONPath, path, err)
+			}
+			segments = append(segments, s)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%w %q: unexpected %q", ErrJSONPath, path, rest[0])
+		}
+	}
+	return segments, nil
+}
+
+func bracketSegment(s string) (segment, error) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return segment{name: s[1 : len(s)-1]}, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		return segment{}, fmt.Errorf("bad index %q", s)
+	}
+	return segment{index: i, isIndex: true}, nil
+}
+
+// lookup returns the value of doc selected by the path.
+func lookup(doc any, path []segment) (any, bool) {
+	value := doc
+	for _, s := range path {
+		if s.isIndex {
+			a, ok := value.([]any)
+			if !ok || s.index >= len(a) {
+				return nil, false
+			}
+			value = a[s.index]
+			continue
+		}
+		o, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = o[s.name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// decode parses data keeping the numbers as they were written, so they are compared as text.
+func decode(data []byte) (any, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var doc any
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// text returns the value as it is compared with RouteDef.equals: strings as they are and the
+// rest as JSON.
+func text(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
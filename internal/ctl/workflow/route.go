@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"encoding/json"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+)
+
+// Next returns the steps the result of step is routed to, in the order they were declared.
+func Next(step *pb.StepDef, code uint64, result string) []string {
+	var doc any
+	parsed := false
+	next := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, r := range step.Routes {
+		if r.MinCode != nil && code < *r.MinCode || r.MaxCode != nil && code > *r.MaxCode {
+			continue
+		}
+		if r.JsonPath != nil {
+			if !parsed {
+				// a result that is not JSON does not match any path.
+				doc, _ = decode([]byte(result))
+				parsed = true
+			}
+			if !matchesPath(doc, *r.JsonPath, r.GetEquals()) {
+				continue
+			}
+		}
+		for _, n := range r.Next {
+			if _, ok := seen[n]; !ok {
+				seen[n] = struct{}{}
+				next = append(next, n)
+			}
+		}
+	}
+	return next
+}
+
+func matchesPath(doc any, jsonPath string, equals string) bool {
+	if doc == nil {
+		return false
+	}
+	path, err := parsePath(jsonPath)
+	if err != nil {
+		return false
+	}
+	value, ok := lookup(doc, path)
+	if !ok {
+		return false
+	}
+	return equals == "" || text(value) == equals
+}
+
+// Join returns the data of a join step: the results of the steps it waited for, keyed by step ID.
+// Results that are JSON are embedded as they are, and the rest as strings.
+func Join(results map[string]string) ([]byte, error) {
+	o := make(map[string]json.RawMessage, len(results))
+	for step, result := range results {
+		if json.Valid([]byte(result)) {
+			o[step] = json.RawMessage(result)
+			continue
+		}
+		s, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		o[step] = s
+	}
+	return json.Marshal(o)
+}
+
+// Predecessors returns the steps that route to each step of the workflow.
+func Predecessors(wf *pb.WorkflowDef) map[string][]string {
+	preds := make(map[string][]string)
+	for _, s := range wf.Steps {
+		seen := make(map[string]struct{})
+		for _, r := range s.Routes {
+			for _, n := range r.Next {
+				if _, ok := seen[n]; !ok {
+					seen[n] = struct{}{}
+					preds[n] = append(preds[n], s.ID)
+				}
+			}
+		}
+	}
+	return preds
+}
+
+// Step returns the step of the workflow with the ID.
+func Step(wf *pb.WorkflowDef, id string) *pb.StepDef {
+	for _, s := range wf.Steps {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Package workflow validates the workflows declared by the packages and routes the results of their steps.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+)
+
+var ErrInvalidWorkflow = errors.New("invalid workflow")
+
+// Validate checks that the workflows of the package are DAGs of steps that process the events of its jobs.
+func Validate(pkg *pb.JobPackage) error {
+	events := make(map[string]struct{})
+	for _, j := range pkg.Jobs {
+		if j.Event != nil {
+			events[j.Event.ID] = struct{}{}
+		}
+	}
+	ids := make(map[string]struct{})
+	for _, wf := range pkg.Workflows {
+		if wf.ID == "" {
+			return fmt.Errorf("%w: the ID is empty", ErrInvalidWorkflow)
+		}
+		if _, ok := ids[wf.ID]; ok {
+			return fmt.Errorf("%w %s: it is declared twice", ErrInvalidWorkflow, wf.ID)
+		}
+		ids[wf.ID] = struct{}{}
+		if err := validate(wf, events); err != nil {
+			return fmt.Errorf("%w %s: %w", ErrInvalidWorkflow, wf.ID, err)
+		}
+	}
+	return nil
+}
+
+func validate(wf *pb.WorkflowDef, events map[string]struct{}) error {
+	if len(wf.Steps) == 0 {
+		return errors.New("it has no steps")
+	}
+	steps := make(map[string]*pb.StepDef)
+	for _, s := range wf.Steps {
+		if s.ID == "" {
+			return errors.New("a step ID is empty")
+		}
+		if _, ok := steps[s.ID]; ok {
+			return fmt.Errorf("step %s is declared twice", s.ID)
+		}
+		steps[s.ID] = s
+		if _, ok := events[s.Event]; !ok {
+			return fmt.Errorf("step %s: event %q is not declared by a job", s.ID, s.Event)
+		}
+	}
+	for _, s := range wf.Steps {
+		if err := validateRoutes(s, steps); err != nil {
+			return fmt.Errorf("step %s: %w", s.ID, err)
+		}
+	}
+	preds := Predecessors(wf)
+	if len(preds[wf.Steps[0].ID]) > 0 {
+		return fmt.Errorf("step %s: the first step cannot be routed to", wf.Steps[0].ID)
+	}
+	for _, s := range wf.Steps {
+		switch n := len(preds[s.ID]); {
+		case s.Join && n < 2:
+			return fmt.Errorf("step %s: a join must be routed from two steps or more", s.ID)
+		case !s.Join && n > 1:
+			return fmt.Errorf("step %s: it is routed from several steps but it is not a join", s.ID)
+		}
+	}
+	return acyclic(wf, steps)
+}
+
+func validateRoutes(s *pb.StepDef, steps map[string]*pb.StepDef) error {
+	for i, r := range s.Routes {
+		if len(r.Next) == 0 {
+			return fmt.Errorf("route %d has no next steps", i)
+		}
+		for _, n := range r.Next {
+			if _, ok := steps[n]; !ok {
+				return fmt.Errorf("route %d: step %s is not declared", i, n)
+			}
+		}
+		if r.MinCode != nil && r.MaxCode != nil && *r.MinCode > *r.MaxCode {
+			return fmt.Errorf("route %d: mincode is greater than maxcode", i)
+		}
+		if r.JsonPath == nil {
+			if r.Equals != nil {
+				return fmt.Errorf("route %d: equals needs a jsonpath", i)
+			}
+			continue
+		}
+		if _, err := parsePath(*r.JsonPath); err != nil {
+			return fmt.Errorf("route %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// acyclic returns an error if a step can be routed back to itself.
+func acyclic(wf *pb.WorkflowDef, steps map[string]*pb.StepDef) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int)
+	var visit func(s *pb.StepDef) error
+	visit = func(s *pb.StepDef) error {
+		switch state[s.ID] {
+		case visiting:
+			return fmt.Errorf("step %s is part of a cycle", s.ID)
+		case visited:
+			return nil
+		}
+		state[s.ID] = visiting
+		for _, r := range s.Routes {
+			for _, n := range r.Next {
+				if err := visit(steps[n]); err != nil {
+					return err
+				}
+			}
+		}
+		state[s.ID] = visited
+		return nil
+	}
+	for _, s := range wf.Steps {
+		if err := visit(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
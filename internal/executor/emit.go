@@ -0,0 +1,138 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/executor/wasm"
+	"github.com/andrescosta/jobico/internal/jsonptr"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// target is an event the Jobicolets of its tenant can emit.
+type target struct {
+	def    *pb.EventDef
+	schema *jsonschema.Schema
+}
+
+// emitted collects the events emitted by a run, which are queued only once the job completes.
+type emitted struct {
+	mu    sync.Mutex
+	items []emittedItem
+}
+
+type emittedItem struct {
+	queue string
+	item  *pb.QueueItem
+}
+
+type emittedKey struct{}
+
+func withEmitted(ctx context.Context, e *emitted) context.Context {
+	return context.WithValue(ctx, emittedKey{}, e)
+}
+
+func (e *emitted) add(queue string, item *pb.QueueItem) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.items = append(e.items, emittedItem{queue: queue, item: item})
+}
+
+// addTargets registers the events of the package, compiling their schemas.
+func (e *Executor) addTargets(ctx context.Context, pkg *pb.JobPackage) error {
+	for _, job := range pkg.Jobs {
+		t := &target{def: job.Event}
+		if ref := job.Event.GetSchema().GetSchemaRef(); ref != "" {
+			f, err := e.cli.repo.File(ctx, pkg.Tenant, ref)
+			if err != nil {
+				return err
+			}
+			name := targetKey(pkg.Tenant, job.Event.ID)
+			comp := jsonschema.NewCompiler()
+			if err := comp.AddResource(name, bytes.NewReader(f)); err != nil {
+				return err
+			}
+			if t.schema, err = comp.Compile(name); err != nil {
+				return err
+			}
+		}
+		e.targets.Store(targetKey(pkg.Tenant, job.Event.ID), t)
+	}
+	return nil
+}
+
+func (e *Executor) removeTargets(pkg *pb.JobPackage) {
+	for _, job := range pkg.Jobs {
+		e.targets.Delete(targetKey(pkg.Tenant, job.Event.ID))
+	}
+}
+
+// emit validates the data against the schema of the event and adds it to the events of the run.
+func (e *Executor) emit(ctx context.Context, tenant string, event string, data []byte) error {
+	t, ok := e.targets.Load(targetKey(tenant, event))
+	if !ok {
+		return fmt.Errorf("%w %s", wasm.ErrUnknownEvent, event)
+	}
+	out, ok := ctx.Value(emittedKey{}).(*emitted)
+	if !ok {
+		return errors.New("emit called outside of a run")
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return errors.Join(wasm.ErrInvalidEvent, err)
+	}
+	if t.schema != nil {
+		if err := t.schema.Validate(v); err != nil {
+			return errors.Join(wasm.ErrInvalidEvent, err)
+		}
+	}
+	item := &pb.QueueItem{
+		Event: event,
+		Data:  data,
+	}
+	if pointer := t.def.GetPartitionKey(); pointer != "" {
+		key, err := jsonptr.Key(pointer, v)
+		if err != nil {
+			return errors.Join(wasm.ErrInvalidEvent, err)
+		}
+		item.PartitionKey = &key
+	}
+	out.add(t.def.SupplierQueue, item)
+	return nil
+}
+
+// queueEmitted queues the events emitted by the run of item, the ones of each queue at once.
+func (p *processor) queueEmitted(ctx context.Context, item *pb.QueueItem, emitted *emitted) error {
+	queues := make([]string, 0)
+	items := make(map[string][]*pb.QueueItem)
+	for i, e := range emitted.items {
+		// a redelivered item emits the same events again, so they are queued once. The key only
+		// holds within the dedup window of the queue: with a window of 0 they are queued again.
+		key := item.ID + "/emit/" + strconv.Itoa(i)
+		e.item.DedupKey = &key
+		if _, ok := items[e.queue]; !ok {
+			queues = append(queues, e.queue)
+		}
+		items[e.queue] = append(items[e.queue], e.item)
+	}
+	for _, q := range queues {
+		if _, err := p.cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: p.tenant,
+			Queue:  q,
+			Items:  items[q],
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func targetKey(tenant string, event string) string {
+	return tenant + "/" + event
+}
@@ -6,12 +6,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/andrescosta/goico/pkg/collection"
 	"github.com/andrescosta/goico/pkg/env"
-	"github.com/andrescosta/goico/pkg/runtimes/wasm"
 	"github.com/andrescosta/goico/pkg/service"
 	"github.com/andrescosta/goico/pkg/syncutil"
 	"github.com/andrescosta/jobico/internal/api/client"
 	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/executor/wasm"
 	"github.com/rs/zerolog"
 )
 
@@ -31,11 +32,28 @@ type Executor struct {
 	cli       *cli
 	scheduler *scheduler
 	runtime   *wasm.Runtime
+	// targets are the events the Jobicolets can emit, by tenant and event ID.
+	targets   *collection.SyncMap[string, *target]
+	batchSize uint32
+	waitTime  time.Duration
+	workers   int
 }
 
 type Options struct {
 	Ticker  syncutil.Ticker
 	MaxProc int
+	// Polling dequeues the events every time the ticker fires instead of subscribing to the queues.
+	Polling bool
+	// MaxInFlight is the number of events a subscription receives before they are processed.
+	MaxInFlight uint32
+	// BatchSize is the number of events dequeued at once when polling. Zero means the queue limit.
+	// Queues can declare their own batch size, which is also used for their subscriptions.
+	BatchSize uint32
+	// WaitTime is the time a poll waits for events to arrive when the queue is empty.
+	WaitTime time.Duration
+	// Workers is the number of events of a queue processed at the same time. Events with the same
+	// partition key are still processed one at a time and in order. Zero means one.
+	Workers int
 }
 
 func New(ctx context.Context, dialer service.GrpcDialer, option Options) (*Executor, error) {
@@ -72,11 +90,19 @@ func New(ctx context.Context, dialer service.GrpcDialer, option Options) (*Execu
 	if err != nil {
 		return nil, err
 	}
-	scheduller := newScheduler(ctx, ticker, option.MaxProc)
+	scheduller := newScheduler(ctx, ticker, option.MaxProc, option.Polling, option.MaxInFlight)
+	workers := option.Workers
+	if workers <= 0 {
+		workers = 1
+	}
 	e := &Executor{
 		cli:       cli,
 		scheduler: scheduller,
 		runtime:   wasmRuntime,
+		targets:   collection.NewSyncMap[string, *target](),
+		batchSize: option.BatchSize,
+		waitTime:  option.WaitTime,
+		workers:   workers,
 	}
 	return e, nil
 }
@@ -123,7 +149,11 @@ func (e *Executor) init(ctx context.Context) error {
 }
 
 func (e *Executor) addExecutors(ctx context.Context, pkg *pb.JobPackage) error {
+	if err := e.addTargets(ctx, pkg); err != nil {
+		return err
+	}
 	events := make(map[string]*event)
+	deadLetterQueues := deadLetterQueues(pkg)
 	for _, job := range pkg.Jobs {
 		runtime := getRuntime(job.Event.Runtime, pkg.Runtimes)
 		if runtime != nil {
@@ -135,6 +165,8 @@ func (e *Executor) addExecutors(ctx context.Context, pkg *pb.JobPackage) error {
 			event := &event{
 				id:        job.Event.ID,
 				nextStep:  job.Result,
+				retry:     job.Retry,
+				workflows: workflows(pkg, job.Event.ID),
 				logSender: sender,
 			}
 			events[job.Event.ID] = event
@@ -146,37 +178,52 @@ func (e *Executor) addExecutors(ctx context.Context, pkg *pb.JobPackage) error {
 			if runtime.MainFuncName != nil {
 				funcName = *runtime.MainFuncName
 			}
-			wasmModule, err := wasm.NewModule(ctx, e.runtime, wasmfile, funcName, sender.sendLog)
+			timeout, limits := runtimeLimits(runtime)
+			host := wasm.Host{
+				Log: sender.sendLog,
+				Emit: func(ctx context.Context, event string, data []byte) error {
+					return e.emit(ctx, pkg.Tenant, event, data)
+				},
+			}
+			module, err := newModule(ctx, e.runtime, wasmfile, funcName, host, e.workers, timeout, limits)
 			if err != nil {
 				return err
 			}
-			module := module{
-				wasmModule: wasmModule,
-			}
-			event.module = &module
+			event.module = module
 		}
-		for _, q := range pkg.Queues {
-			if strings.HasSuffix(q.ID, "_ok") || strings.HasSuffix(q.ID, "_error") {
-				continue
-			}
-			ex := &processor{
-				packageID: pkg.GetID(),
-				tenant:    pkg.Tenant,
-				queue:     q.ID,
-				runtime:   e.runtime,
-				events:    events,
-				cli:       e.cli,
-			}
-			e.scheduler.add(ex)
+	}
+	for _, q := range pkg.Queues {
+		if strings.HasSuffix(q.ID, "_ok") || strings.HasSuffix(q.ID, "_error") {
+			continue
+		}
+		// dead-letter queues are inspected and requeued by operators, not processed.
+		if _, ok := deadLetterQueues[q.ID]; ok {
+			continue
+		}
+		batchSize := q.GetBatchSize()
+		if batchSize == 0 {
+			batchSize = e.batchSize
 		}
+		ex := &processor{
+			packageID:   pkg.GetID(),
+			tenant:      pkg.Tenant,
+			queue:       q.ID,
+			runtime:     e.runtime,
+			events:      events,
+			cli:         e.cli,
+			batchSize:   batchSize,
+			maxInFlight: q.GetBatchSize(),
+			waitTime:    e.waitTime,
+			slots:       make(chan struct{}, e.workers),
+		}
+		e.scheduler.add(ex)
 	}
 	return nil
 }
 
 func (e *Executor) removeExecutor(_ context.Context, p *pb.JobPackage) {
-	for _, q := range p.Queues {
-		e.scheduler.remove(p.Tenant, p.ID, q.ID)
-	}
+	e.scheduler.remove(p.Tenant, p.ID)
+	e.removeTargets(p)
 }
 
 func (e *Executor) startListeningUpdates(ctx context.Context) error {
@@ -217,6 +264,26 @@ func (e *Executor) onUpdate(ctx context.Context, u *pb.UpdateToPackagesStrReply)
 	}
 }
 
+func deadLetterQueues(pkg *pb.JobPackage) map[string]struct{} {
+	qs := make(map[string]struct{})
+	for _, q := range pkg.Queues {
+		if q.DeadLetterQueue != nil {
+			qs[*q.DeadLetterQueue] = struct{}{}
+		}
+	}
+	return qs
+}
+
+func workflows(pkg *pb.JobPackage, event string) map[string]string {
+	wfs := make(map[string]string)
+	for _, wf := range pkg.Workflows {
+		if len(wf.Steps) > 0 && wf.Steps[0].Event == event {
+			wfs[wf.ID] = wf.Steps[0].ID
+		}
+	}
+	return wfs
+}
+
 func getRuntime(id string, rs []*pb.RuntimeDef) *pb.RuntimeDef {
 	for _, r := range rs {
 		if r.ID == id {
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andrescosta/goico/pkg/env"
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/executor/wasm"
+)
+
+// limitError is returned by the runs stopped because they exceeded a limit of the runtime.
+type limitError struct {
+	limit string
+	err   error
+}
+
+func (e *limitError) Error() string {
+	return fmt.Sprintf("%s: %v", e.limit, e.err)
+}
+
+func (e *limitError) Unwrap() error {
+	return e.err
+}
+
+// runtimeLimits returns the time a run of the runtime's modules can take and the resources they can use.
+func runtimeLimits(runtime *pb.RuntimeDef) (time.Duration, wasm.Limits) {
+	timeout := *env.Duration("wasm.exec.timeout", 2*time.Minute)
+	if millis := runtime.GetLimits().GetTimeoutMillis(); millis > 0 {
+		timeout = time.Duration(millis) * time.Millisecond
+	}
+	return timeout, wasm.Limits{
+		MaxMemoryPages: runtime.GetLimits().GetMaxMemoryPages(),
+	}
+}
+
+// checkLimits wraps the error of a run in a limitError when the run exceeded a limit. Runs stopped
+// because ctx is done are not violations.
+func checkLimits(ctx context.Context, err error, timeout time.Duration, limits wasm.Limits) error {
+	switch {
+	case err == nil || ctx.Err() != nil:
+		return err
+	case errors.Is(err, context.DeadlineExceeded):
+		return &limitError{limit: fmt.Sprintf("timeout of %s exceeded", timeout), err: err}
+	case errors.Is(err, wasm.ErrMemoryLimit):
+		return &limitError{limit: fmt.Sprintf("memory limit of %d pages exceeded", limits.MaxMemoryPages), err: err}
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"encoding/json"
+	"time"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// envelope is the data of the chained events when the result declares the Envelope payload.
+type envelope struct {
+	Tenant  string          `json:"tenant"`
+	Event   string          `json:"event"`
+	ID      string          `json:"id"`
+	Queue   string          `json:"queue"`
+	Code    uint64          `json:"code"`
+	Attempt uint32          `json:"attempt"`
+	Date    time.Time       `json:"date"`
+	Result  json.RawMessage `json:"result"`
+	Input   json.RawMessage `json:"input"`
+}
+
+// payload returns the data of the event that follows the execution of item.
+func (p *processor) payload(kind pb.ResultDef_Payload, item *pb.QueueItem, attempt uint32, code uint64, result string) ([]byte, error) {
+	switch kind {
+	case pb.ResultDef_Result:
+		return []byte(result), nil
+	case pb.ResultDef_Input:
+		return item.Data, nil
+	case pb.ResultDef_Envelope:
+		return json.Marshal(envelope{
+			Tenant:  p.tenant,
+			Event:   item.Event,
+			ID:      item.ID,
+			Queue:   p.queue,
+			Code:    code,
+			Attempt: attempt,
+			Date:    time.Now().UTC(),
+			Result:  rawJSON([]byte(result)),
+			Input:   rawJSON(item.Data),
+		})
+	}
+	return proto.Marshal(&pb.JobResult{Code: code})
+}
+
+// rawJSON embeds data as is when it is JSON, and as a string otherwise.
+func rawJSON(data []byte) json.RawMessage {
+	if json.Valid(data) {
+		return data
+	}
+	// a string cannot fail to be marshaled.
+	s, _ := json.Marshal(string(data))
+	return s
+}
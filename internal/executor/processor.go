@@ -2,16 +2,17 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/andrescosta/goico/pkg/env"
-	"github.com/andrescosta/goico/pkg/runtimes/wasm"
 	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/executor/wasm"
+	"github.com/andrescosta/jobico/internal/jsonptr"
 	"github.com/rs/zerolog"
-	"google.golang.org/protobuf/proto"
 )
 
 type processor struct {
@@ -21,93 +22,338 @@ type processor struct {
 	events    map[string]*event
 	runtime   *wasm.Runtime
 	cli       *cli
+	// batchSize is the number of items dequeued at once, and waitTime the time a dequeue waits for them.
+	batchSize uint32
+	waitTime  time.Duration
+	// maxInFlight is the batch size declared by the queue for its subscription. Zero means the executor default.
+	maxInFlight uint32
+	// subscribed is set while the items are pushed by the queue, so the processor is not polled.
+	subscribed atomic.Bool
+	cancel     context.CancelFunc
+	// slots bounds the items processed at the same time. The queue does not deliver an item while another
+	// one with the same partition key is in flight, so the items of a key are still processed in order.
+	slots chan struct{}
 }
 
 type event struct {
-	id        string
-	nextStep  *pb.ResultDef
+	id       string
+	nextStep *pb.ResultDef
+	retry    *pb.RetryPolicy
+	// workflows maps the workflows that start with the event to their first step.
+	workflows map[string]string
 	module    *module
 	logSender *recorder
 }
 
+// module is a pool of instances of the event's module, because an instance cannot run concurrently.
 type module struct {
-	wasmModule *wasm.Module
+	instances chan *wasm.Module
+	mu        sync.Mutex
+	all       []*wasm.Module
+	// newInstance replaces the instances stopped by a limit, which are left in an unknown state.
+	newInstance func(context.Context) (*wasm.Module, error)
+	timeout     time.Duration
+	limits      wasm.Limits
+	closed      bool
+}
+
+func newModule(ctx context.Context, runtime *wasm.Runtime, wasmfile []byte, funcName string, host wasm.Host, size int, timeout time.Duration, limits wasm.Limits) (*module, error) {
+	m := &module{
+		instances: make(chan *wasm.Module, size),
+		newInstance: func(ctx context.Context) (*wasm.Module, error) {
+			return wasm.NewModule(ctx, runtime, wasmfile, funcName, host, limits)
+		},
+		timeout: timeout,
+		limits:  limits,
+	}
+	for i := 0; i < size; i++ {
+		instance, err := m.newInstance(ctx)
+		if err != nil {
+			return nil, errors.Join(err, m.close(ctx))
+		}
+		m.all = append(m.all, instance)
+		m.instances <- instance
+	}
+	return m, nil
+}
+
+func (m *module) run(ctx context.Context, data []byte) (uint64, string, error) {
+	var instance *wasm.Module
+	select {
+	case <-ctx.Done():
+		return 0, "", ctx.Err()
+	case instance = <-m.instances:
+	}
+	defer func() { m.instances <- instance }()
+	code, result, err := run(ctx, instance, data, m.timeout)
+	err = checkLimits(ctx, err, m.timeout, m.limits)
+	var limitErr *limitError
+	if errors.As(err, &limitErr) {
+		instance = m.renew(ctx, instance)
+	}
+	return code, result, err
+}
+
+// renew closes an instance and returns a new one in its place. If it cannot be created, the old one is
+// returned, and its runs fail until the package is loaded again.
+func (m *module) renew(ctx context.Context, old *wasm.Module) *wasm.Module {
+	logger := zerolog.Ctx(ctx)
+	if err := old.Close(ctx); err != nil {
+		logger.Warn().AnErr("error", err).Msg("error closing the module")
+	}
+	instance, err := m.newInstance(ctx)
+	if err != nil {
+		logger.Err(err).Msg("error creating the module")
+		return old
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, a := range m.all {
+		if a == old {
+			m.all[i] = instance
+		}
+	}
+	return instance
+}
+
+// close closes the instances once the running ones are done, or when ctx is done. The closed instances are
+// kept in the pool, so a late run fails and its item is delivered again.
+func (m *module) close(ctx context.Context) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	size := len(m.all)
+	m.mu.Unlock()
+	idle := make([]*wasm.Module, 0, size)
+	defer func() {
+		for _, instance := range idle {
+			m.instances <- instance
+		}
+	}()
+	for len(idle) < size && ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case instance := <-m.instances:
+			idle = append(idle, instance)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var err error
+	for _, instance := range m.all {
+		err = errors.Join(instance.Close(ctx), err)
+	}
+	return err
 }
 
 func (p *processor) processEvents(ctx context.Context, w *sync.WaitGroup) {
 	defer w.Done()
-	logger := zerolog.Ctx(ctx)
-	items, err := p.cli.queue.Dequeue(ctx, p.tenant, p.queue)
+	items, err := p.cli.queue.DequeueBatch(ctx, p.tenant, p.queue, p.batchSize, p.waitTime)
 	// TODO: do something with errors
 	if err != nil || len(items) == 0 {
 		return
 	}
-	for _, item := range items {
-		event, ok := p.events[item.Event]
-		if !ok {
-			logger.Warn().Msgf("event %s not supported", event.id)
-			continue
-		}
-		code, result, err := run(ctx, event.module.wasmModule, item.Data)
+	p.process(ctx, items...)
+}
+
+// subscribe processes the items pushed by the queue until the subscription ends.
+func (p *processor) subscribe(ctx context.Context, maxInFlight uint32, running chan struct{}) error {
+	s, err := p.cli.queue.Subscribe(ctx, p.tenant, p.queue, maxInFlight)
+	if err != nil {
+		return err
+	}
+	p.subscribed.Store(true)
+	defer p.subscribed.Store(false)
+	w := sync.WaitGroup{}
+	defer w.Wait()
+	for {
+		item, err := s.Recv()
 		if err != nil {
-			logger.Err(err).Msg("error executing")
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case running <- struct{}{}:
+		}
+		select {
+		case <-ctx.Done():
+			<-running
+			return ctx.Err()
+		case p.slots <- struct{}{}:
+		}
+		w.Add(1)
+		go func() {
+			defer w.Done()
+			p.processItem(ctx, item)
+			<-p.slots
+			<-running
+		}()
+	}
+}
+
+// stop ends the subscription of the processor and closes the modules of its events. The events are
+// shared by the processors of a package, which are stopped together.
+func (p *processor) stop(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	var err error
+	for _, e := range p.events {
+		if e.module != nil {
+			err = errors.Join(e.module.close(ctx), err)
 		}
-		if err := event.logSender.sendResult(ctx, p.queue, code, result); err != nil {
-			logger.Err(err).Msg("error reporting to recorder")
+	}
+	return err
+}
+
+func (p *processor) process(ctx context.Context, items ...*pb.DequeuedItem) {
+	w := sync.WaitGroup{}
+	defer w.Wait()
+	for _, dequeued := range items {
+		select {
+		case <-ctx.Done():
+			// the items not processed become visible again when their lease expires.
+			return
+		case p.slots <- struct{}{}:
 		}
-		if err := p.makeDecisions(ctx, p.tenant, code, event.nextStep); err != nil {
-			logger.Err(err).Msg("error enqueuing the result")
+		w.Add(1)
+		go func(dequeued *pb.DequeuedItem) {
+			defer w.Done()
+			p.processItem(ctx, dequeued)
+			<-p.slots
+		}(dequeued)
+	}
+}
+
+func (p *processor) processItem(ctx context.Context, dequeued *pb.DequeuedItem) {
+	logger := zerolog.Ctx(ctx)
+	item := dequeued.Item
+	event, ok := p.events[item.Event]
+	if !ok {
+		// the failure is recorded by the queue, which dead-letters the item after the configured attempts.
+		reason := fmt.Sprintf("event %s not supported", item.Event)
+		logger.Warn().Msg(reason)
+		if err := p.cli.queue.Nack(ctx, p.tenant, p.queue, reason, dequeued.Receipt); err != nil {
+			logger.Err(err).Msg("error releasing the event")
 		}
+		return
+	}
+	emitted := &emitted{}
+	code, result, err := event.module.run(withEmitted(ctx, emitted), item.Data)
+	if err != nil {
+		logger.Err(err).Msg("error executing")
+		var limitErr *limitError
+		if errors.As(err, &limitErr) {
+			if err := event.logSender.sendLimit(ctx, p.queue, dequeued.Attempts, limitErr.limit); err != nil {
+				logger.Err(err).Msg("error reporting to recorder")
+			}
+		}
+		// the failure is recorded by the queue, which dead-letters the item after the configured attempts.
+		if err := p.cli.queue.Nack(ctx, p.tenant, p.queue, err.Error(), dequeued.Receipt); err != nil {
+			logger.Err(err).Msg("error releasing the event")
+		}
+		return
+	}
+	if err := p.complete(ctx, event, dequeued, code, result, emitted); err != nil {
+		logger.Err(err).Msg("error completing the event")
 	}
 }
 
-func (p *processor) makeDecisions(ctx context.Context, tenant string, code uint64, resultDef *pb.ResultDef) error {
-	if resultDef == nil ||
-		(code == NoError && resultDef.Ok == nil) ||
-		(code != NoError && resultDef.Error == nil) {
+// complete acknowledges the item only after the result was recorded and the emitted events and the next
+// steps were enqueued. Otherwise, the item is released so it can be delivered again. An item that is retried
+// is released after the backoff instead.
+func (p *processor) complete(ctx context.Context, event *event, dequeued *pb.DequeuedItem, code uint64, result string, emitted *emitted) error {
+	attempt := dequeued.Attempts
+	var err error
+	if err = event.logSender.sendResult(ctx, p.queue, attempt, code, result); err != nil {
+		err = errors.Join(errors.New("error reporting to recorder"), err)
+	} else if retryable(event.retry, code, attempt) {
+		if err := p.retry(ctx, dequeued, code, backoff(event.retry, attempt)); err != nil {
+			// the lease of the item expires and it is delivered again.
+			return errors.Join(errors.New("error scheduling the retry"), err)
+		}
 		return nil
+	} else if err = p.queueEmitted(ctx, dequeued.Item, emitted); err != nil {
+		err = errors.Join(errors.New("error enqueuing the emitted events"), err)
+	} else if err = p.makeDecisions(ctx, dequeued.Item, attempt, code, result, event.nextStep); err != nil {
+		err = errors.Join(errors.New("error enqueuing the result"), err)
+	} else if err = p.advance(ctx, event, dequeued.Item, code, result); err != nil {
+		err = errors.Join(errors.New("error advancing the workflow"), err)
 	}
-	r := pb.JobResult{
-		Code: code,
+	if err != nil {
+		return errors.Join(err, p.cli.queue.Nack(ctx, p.tenant, p.queue, err.Error(), dequeued.Receipt))
 	}
-	bytes1, err := proto.Marshal(&r)
+	return p.cli.queue.Ack(ctx, p.tenant, p.queue, dequeued.Receipt)
+}
+
+// retry releases the item to be run again after the backoff. It stays leased until then, so the items
+// with the same partition key are not delivered before the retry.
+func (p *processor) retry(ctx context.Context, dequeued *pb.DequeuedItem, code uint64, backoff time.Duration) error {
+	reason := fmt.Sprintf("job failed with code %d", code)
+	return p.cli.queue.NackAfter(ctx, p.tenant, p.queue, reason, backoff, dequeued.Receipt)
+}
+
+func (p *processor) makeDecisions(ctx context.Context, item *pb.QueueItem, attempt uint32, code uint64, result string, resultDef *pb.ResultDef) error {
+	next := resultDef.GetOk()
+	if code != NoError {
+		next = resultDef.GetError()
+	}
+	if next == nil {
+		return nil
+	}
+	data, err := p.payload(resultDef.Payload, item, attempt, code, result)
 	if err != nil {
 		return err
 	}
-	var q *pb.QueueRequest
-	if code == NoError {
-		q = &pb.QueueRequest{
-			Tenant: tenant,
-			Queue:  resultDef.Ok.SupplierQueue,
-			Items: []*pb.QueueItem{
-				{
-					Event: resultDef.Ok.ID,
-					Data:  bytes1,
-				},
-			},
-		}
-	} else {
-		q = &pb.QueueRequest{
-			Tenant: tenant,
-			Queue:  resultDef.Error.SupplierQueue,
-			Items: []*pb.QueueItem{
-				{
-					Event: resultDef.Error.ID,
-					Data:  bytes1,
-				},
+	partitionKey, err := partitionKey(next.GetPartitionKey(), data)
+	if err != nil {
+		return fmt.Errorf("partition key of event %s: %w", next.ID, err)
+	}
+	// the result is queued again when a later step fails and the item is redelivered, so it is queued once.
+	key := item.ID + "/result/" + next.ID
+	q := &pb.QueueRequest{
+		Tenant: p.tenant,
+		Queue:  next.SupplierQueue,
+		Items: []*pb.QueueItem{
+			{
+				Event:        next.ID,
+				Data:         data,
+				DedupKey:     &key,
+				PartitionKey: partitionKey,
 			},
-		}
+		},
 	}
-	if err := p.cli.queue.Queue(ctx, q); err != nil {
+	if _, err := p.cli.queue.Queue(ctx, q); err != nil {
 		return err
 	}
 	return nil
 }
 
-func run(ctx context.Context, module *wasm.Module, data []byte) (uint64, string, error) {
+// partitionKey returns the value the pointer declared by an event refers to in its data, or nil if the
+// event does not declare one.
+func partitionKey(pointer string, data []byte) (*string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	key, err := jsonptr.Key(pointer, v)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func run(ctx context.Context, module *wasm.Module, data []byte, timeout time.Duration) (uint64, string, error) {
 	mod := "goenv"
 	logger := zerolog.Ctx(ctx)
-	ctx, cancel := context.WithTimeout(ctx, *env.Duration("wasm.exec.timeout", 2*time.Minute))
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	code, result, err := module.Run(ctx, string(data))
 	if err != nil {
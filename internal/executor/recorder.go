@@ -40,7 +40,25 @@ func (r *recorder) sendLog(ctx context.Context, lvl uint32, msg string) error {
 	})
 }
 
-func (r *recorder) sendResult(ctx context.Context, queue string, code uint64, result string) error {
+func (r *recorder) sendResult(ctx context.Context, queue string, attempt uint32, code uint64, result string) error {
+	return r.send(ctx, queue, attempt, &pb.JobResult{
+		Type:     pb.JobResult_Result,
+		TypeDesc: "result",
+		Code:     code,
+		Message:  result,
+	})
+}
+
+// sendLimit records that the run was stopped because it exceeded the limit of its runtime.
+func (r *recorder) sendLimit(ctx context.Context, queue string, attempt uint32, limit string) error {
+	return r.send(ctx, queue, attempt, &pb.JobResult{
+		Type:     pb.JobResult_Limit,
+		TypeDesc: "limit",
+		Message:  limit,
+	})
+}
+
+func (r *recorder) send(ctx context.Context, queue string, attempt uint32, result *pb.JobResult) error {
 	now := time.Now()
 	host, err := os.Hostname()
 	if err != nil {
@@ -54,13 +72,9 @@ func (r *recorder) sendResult(ctx context.Context, queue string, code uint64, re
 			Seconds: now.Unix(),
 			Nanos:   int32(now.Nanosecond()),
 		},
-		Server: host,
-		Result: &pb.JobResult{
-			Type:     pb.JobResult_Result,
-			TypeDesc: "result",
-			Code:     code,
-			Message:  result,
-		},
+		Server:  host,
+		Result:  result,
+		Attempt: attempt,
 	}
 	return r.cli.recorder.AddJobExecution(ctx, ex)
 }
@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+)
+
+const defaultBackoffMultiplier = 2.0
+
+// maxBackoffMillis bounds the backoff of the policies that do not cap it, so it fits in a time.Duration.
+const maxBackoffMillis = float64(math.MaxInt64 / int64(time.Millisecond))
+
+// retryable reports whether an event whose job failed with code in its attempt-th run is run again.
+func retryable(policy *pb.RetryPolicy, code uint64, attempt uint32) bool {
+	if policy == nil || code == NoError || attempt >= policy.MaxAttempts {
+		return false
+	}
+	return len(policy.RetryableCodes) == 0 || slices.Contains(policy.RetryableCodes, code)
+}
+
+// backoff returns the time to wait before the run that follows the attempt-th one.
+func backoff(policy *pb.RetryPolicy, attempt uint32) time.Duration {
+	multiplier := policy.GetMultiplier()
+	if policy.Multiplier == nil {
+		multiplier = defaultBackoffMultiplier
+	}
+	millis := float64(policy.InitialBackoffMillis) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxBackoffMillis > 0 {
+		millis = min(millis, float64(policy.MaxBackoffMillis))
+	}
+	millis = min(millis, maxBackoffMillis)
+	return time.Duration(millis * float64(time.Millisecond))
+}
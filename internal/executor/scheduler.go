@@ -4,16 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/andrescosta/goico/pkg/collection"
 	"github.com/andrescosta/goico/pkg/syncutil"
+	"github.com/rs/zerolog"
 )
 
 type status int
 
-const defaultMaxProcess = 10
+const (
+	defaultMaxProcess  = 10
+	defaultMaxInFlight = 10
+	// subscribeRetry is the time a processor is polled before subscribing to its queue again.
+	subscribeRetry = 5 * time.Second
+	// closeTimeout is the time a stopped processor waits for its running items before closing its modules.
+	closeTimeout = 5 * time.Second
+)
+
 const (
 	statusStopped status = iota + 1
 	statusStarting
@@ -27,37 +37,104 @@ type scheduler struct {
 	ticker     syncutil.Ticker
 	executors  *collection.SyncMap[string, *processor]
 	maxProc    int
+	// polling disables the subscriptions, so the queues are only dequeued when the ticker fires.
+	polling     bool
+	maxInFlight uint32
+	running     chan struct{}
+	streams     sync.WaitGroup
 }
 
-func newScheduler(ctx context.Context, ticker syncutil.Ticker, maxProc int) *scheduler {
+func newScheduler(ctx context.Context, ticker syncutil.Ticker, maxProc int, polling bool, maxInFlight uint32) *scheduler {
 	if maxProc == 0 {
 		maxProc = defaultMaxProcess
 	}
+	if maxInFlight == 0 {
+		maxInFlight = defaultMaxInFlight
+	}
 	return &scheduler{
-		currStatus: statusStarting,
-		muStatus:   &sync.RWMutex{},
-		ctx:        ctx,
-		ticker:     ticker,
-		executors:  collection.NewSyncMap[string, *processor](),
-		maxProc:    maxProc,
+		currStatus:  statusStarting,
+		muStatus:    &sync.RWMutex{},
+		ctx:         ctx,
+		ticker:      ticker,
+		executors:   collection.NewSyncMap[string, *processor](),
+		maxProc:     maxProc,
+		polling:     polling,
+		maxInFlight: maxInFlight,
+		running:     make(chan struct{}, maxProc),
 	}
 }
 
 func (s *scheduler) add(ex *processor) {
-	s.executors.Store(id(ex.tenant, ex.packageID, ex.queue), ex)
+	key := id(ex.tenant, ex.packageID, ex.queue)
+	if old, ok := s.executors.Load(key); ok {
+		s.stop(old)
+	}
+	s.executors.Store(key, ex)
+	if !s.polling {
+		s.subscribe(ex)
+	}
+}
+
+// subscribe receives the items of the processor's queue as they arrive. While the subscription
+// is down, the processor is polled.
+func (s *scheduler) subscribe(ex *processor) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	ex.cancel = cancel
+	logger := zerolog.Ctx(ctx)
+	maxInFlight := s.maxInFlight
+	if ex.maxInFlight > 0 {
+		maxInFlight = ex.maxInFlight
+	}
+	s.streams.Add(1)
+	go func() {
+		defer s.streams.Done()
+		for {
+			err := ex.subscribe(ctx, maxInFlight, s.running)
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn().AnErr("error", err).Msgf("subscription to %s/%s ended, polling the queue", ex.tenant, ex.queue)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(subscribeRetry):
+			}
+		}
+	}()
 }
 
 func id(tenant string, pkg string, queue string) string {
 	return fmt.Sprintf("%s/%s/%s", tenant, pkg, queue)
 }
 
-func (s *scheduler) remove(tenant string, pkg string, queue string) {
-	s.executors.Delete(id(tenant, pkg, queue))
+// remove stops the processors of a package, including the ones of the queues that were dropped from it.
+func (s *scheduler) remove(tenant string, pkg string) {
+	prefix := id(tenant, pkg, "")
+	stopped := make(map[string]*processor)
+	s.executors.Range(func(key string, ex *processor) bool {
+		if strings.HasPrefix(key, prefix) {
+			stopped[key] = ex
+		}
+		return true
+	})
+	for key, ex := range stopped {
+		s.executors.Delete(key)
+		s.stop(ex)
+	}
+}
+
+func (s *scheduler) stop(ex *processor) {
+	ctx, cancel := context.WithTimeout(s.ctx, closeTimeout)
+	defer cancel()
+	if err := ex.stop(ctx); err != nil {
+		zerolog.Ctx(s.ctx).Warn().AnErr("error", err).Msgf("error closing the modules of %s/%s", ex.tenant, ex.queue)
+	}
 }
 
 func (s *scheduler) run() {
 	defer s.ticker.Stop()
 	defer s.setCurrStatus(statusStopped)
+	defer s.streams.Wait()
 	s.setCurrStatus(statusStarted)
 	for {
 		select {
@@ -71,6 +148,9 @@ func (s *scheduler) run() {
 					if s.ctx.Err() != nil {
 						return false
 					}
+					if process.subscribed.Load() {
+						return true
+					}
 					w.Add(1)
 					running++
 					go process.processEvents(s.ctx, &w)
@@ -90,11 +170,11 @@ func (s *scheduler) run() {
 
 func (s *scheduler) dispose() error {
 	var err error
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
 	defer cancel()
 	s.executors.Range(func(_ string, ex *processor) bool {
 		for _, e := range ex.events {
-			err = errors.Join(e.module.wasmModule.Close(ctx), err)
+			err = errors.Join(e.module.close(ctx), err)
 		}
 		return true
 	})
@@ -0,0 +1,290 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/rs/zerolog"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+type (
+	ModuleType uint32
+	LogFn      func(context.Context, uint32, string) error
+	// EmitFn queues an event with the data. It receives the context of the Run that called emit.
+	EmitFn func(ctx context.Context, event string, data []byte) error
+)
+
+const (
+	TypeDefault ModuleType = iota
+	TypeRust
+)
+
+// ErrMemoryLimit is returned by Run when the module failed after its memory reached MaxMemoryPages.
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+var (
+	// ErrUnknownEvent and ErrInvalidEvent are returned by EmitFn, and reported to the module as
+	// EmitUnknownEvent and EmitInvalidEvent.
+	ErrUnknownEvent = errors.New("unknown event")
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+// Status returned by emit to the module.
+const (
+	EmitOk uint32 = iota
+	EmitUnknownEvent
+	EmitInvalidEvent
+	EmitError
+)
+
+// Host holds the functions the module can call.
+type Host struct {
+	Log  LogFn
+	Emit EmitFn
+}
+
+// Limits bounds the resources of a module.
+type Limits struct {
+	// MaxMemoryPages is the number of pages the linear memory can grow to. Zero means the WASM limit.
+	MaxMemoryPages uint32
+}
+
+type Module struct {
+	mainFunc   api.Function
+	initFunc   api.Function
+	mallocFunc api.Function
+	freeFunc   api.Function
+	logFn      LogFn
+	emitFn     EmitFn
+	freeFn     func(context.Context, uint64, uint64) ([]uint64, error)
+	runtime    wazero.Runtime
+	module     api.Module
+	ver        ModuleType
+	limits     Limits
+}
+
+type EventFuncResult struct {
+	Errno         uint64
+	StrPtrEncoded uint64
+}
+
+func NewModule(ctx context.Context, runtime *Runtime, wasmModule []byte, mainFuncName string, host Host, limits Limits) (*Module, error) {
+	wm := &Module{
+		logFn:  host.Log,
+		emitFn: host.Emit,
+		limits: limits,
+	}
+	wm.runtime = wazero.NewRuntimeWithConfig(ctx, runtime.config(limits))
+	if err := wm.instantiate(ctx, wasmModule, mainFuncName); err != nil {
+		return nil, errors.Join(err, wm.runtime.Close(ctx))
+	}
+	return wm, nil
+}
+
+func (f *Module) instantiate(ctx context.Context, wasmModule []byte, mainFuncName string) error {
+	// DON'T MOVE IT.
+	_, err := f.runtime.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(f.log).Export("log").
+		NewFunctionBuilder().WithFunc(f.emit).Export("emit").
+		Instantiate(ctx)
+	if err != nil {
+		return err
+	}
+	wasi_snapshot_preview1.MustInstantiate(ctx, f.runtime)
+	module, err := f.runtime.Instantiate(ctx, wasmModule)
+	if err != nil {
+		return err
+	}
+	ver := TypeDefault
+	if verFunc := module.ExportedFunction("ver"); verFunc != nil {
+		if v, err := call(ctx, verFunc); err == nil {
+			ver = ModuleType(v[0])
+		}
+	}
+	f.mainFunc = module.ExportedFunction(mainFuncName)
+	f.initFunc = module.ExportedFunction("init")
+	// for tinygo: tinygo-org/tinygo#2788
+	f.mallocFunc = module.ExportedFunction("malloc")
+	f.freeFunc = module.ExportedFunction("free")
+	f.module = module
+	f.ver = ver
+	f.freeFn = f.free
+	// Call the init function to initialize the module
+	_, err = call(ctx, f.initFunc)
+	return err
+}
+
+func (f *Module) free(ctx context.Context, offset, size uint64) ([]uint64, error) {
+	if f.ver == TypeDefault {
+		return call(ctx, f.freeFunc, offset)
+	}
+	return call(ctx, f.freeFunc, offset, size)
+}
+
+// Run calls the main function of the module with data. When the context is done the module is closed,
+// and the error is context.Canceled or context.DeadlineExceeded.
+func (f *Module) Run(ctx context.Context, data string) (uint64, string, error) {
+	code, result, err := f.run(ctx, data)
+	if err != nil && ctx.Err() == nil && f.memoryExhausted() {
+		err = errors.Join(ErrMemoryLimit, err)
+	}
+	return code, result, err
+}
+
+func (f *Module) run(ctx context.Context, data string) (uint64, string, error) {
+	logger := zerolog.Ctx(ctx)
+	// write to internal memory
+	strParamOffset, strParamSize, err := f.writeToMemory(ctx, data)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		if _, err := f.freeFn(ctx, strParamOffset, strParamSize); err != nil {
+			logger.Warn().AnErr("err", err).Msg("error freeing memory")
+		}
+	}()
+	resultFuncPtr, resultFuncSize, err := f.reserveMemoryForResult(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		if _, err := f.freeFn(ctx, resultFuncPtr, resultFuncSize); err != nil {
+			logger.Warn().AnErr("err", err).Msg("error freeing memory")
+		}
+	}()
+	logger.Debug().Msg("calling main method")
+	// The result of the call will be stored in struct pointed by resultFuncPtr
+	if _, err = call(ctx, f.mainFunc, resultFuncPtr, strParamOffset, strParamSize); err != nil {
+		return 0, "", err
+	}
+	return f.getResult(ctx, resultFuncPtr, resultFuncSize)
+}
+
+// memoryExhausted reports whether the memory cannot grow anymore, so a failed allocation made the module fail.
+func (f *Module) memoryExhausted() bool {
+	if f.limits.MaxMemoryPages == 0 || f.module.Memory() == nil {
+		return false
+	}
+	return uint64(f.module.Memory().Size()) >= uint64(f.limits.MaxMemoryPages)*PageSize
+}
+
+func (f *Module) reserveMemoryForResult(ctx context.Context) (uint64, uint64, error) {
+	eventDataSize := uint64(unsafe.Sizeof(EventFuncResult{}))
+	results, err := call(ctx, f.mallocFunc, eventDataSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	eventDataPtr := results[0]
+	return eventDataPtr, eventDataSize, nil
+}
+
+func (f *Module) writeToMemory(ctx context.Context, data string) (uint64, uint64, error) {
+	size := uint64(len(data))
+	results, err := call(ctx, f.mallocFunc, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset := results[0]
+	if !f.module.Memory().Write(uint32(offset), []byte(data)) {
+		return 0, 0, fmt.Errorf("Memory.Write(%d, %d) out of range of memory size %d",
+			offset, size, f.module.Memory().Size())
+	}
+	return offset, size, nil
+}
+
+func (f *Module) getResult(ctx context.Context, offset uint64, size uint64) (uint64, string, error) {
+	data, ok := f.module.Memory().Read(uint32(offset), uint32(size))
+	if !ok {
+		return 0, "", fmt.Errorf("Memory.Read(%d, %d) out of range of memory size %d",
+			offset, size, f.module.Memory().Size())
+	}
+	var result EventFuncResult
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &result); err != nil {
+		return 0, "", err
+	}
+	resultStr, err := f.getResultStr(ctx, result.StrPtrEncoded)
+	if err != nil {
+		return 0, "", err
+	}
+	return result.Errno, resultStr, nil
+}
+
+func (f *Module) getResultStr(ctx context.Context, encodedPtr uint64) (string, error) {
+	logger := zerolog.Ctx(ctx)
+	offset := uint32(encodedPtr >> 32)
+	size := uint32(encodedPtr)
+	if offset != 0 {
+		defer func() {
+			if _, err := f.freeFn(ctx, uint64(offset), uint64(size)); err != nil {
+				logger.Err(err).Msg("error freeing memory")
+			}
+		}()
+	}
+	bytes, ok := f.module.Memory().Read(offset, size)
+	if !ok {
+		return "", fmt.Errorf("Memory.Read(%d, %d) out of range of memory size %d",
+			offset, size, f.module.Memory().Size())
+	}
+	return string(bytes), nil
+}
+
+func (f *Module) log(ctx context.Context, m api.Module, level, offset, byteCount uint32) {
+	logger := zerolog.Ctx(ctx)
+	buf, ok := m.Memory().Read(offset, byteCount)
+	if !ok {
+		logger.Error().Msgf("Memory.Read(%d, %d) out of range", offset, byteCount)
+	}
+	msg := string(buf)
+	logger.WithLevel(zerolog.Level(level)).Msg(msg)
+	if f.logFn != nil {
+		if err := f.logFn(ctx, level, msg); err != nil {
+			logger.Err(err).Msg("error executing log function.")
+		}
+	}
+}
+
+// emit queues the event named by the first string with the data of the second one, and returns the status.
+func (f *Module) emit(ctx context.Context, m api.Module, eventOffset, eventSize, dataOffset, dataSize uint32) uint32 {
+	logger := zerolog.Ctx(ctx)
+	event, ok := m.Memory().Read(eventOffset, eventSize)
+	if !ok {
+		logger.Error().Msgf("Memory.Read(%d, %d) out of range", eventOffset, eventSize)
+		return EmitError
+	}
+	data, ok := m.Memory().Read(dataOffset, dataSize)
+	if !ok {
+		logger.Error().Msgf("Memory.Read(%d, %d) out of range", dataOffset, dataSize)
+		return EmitError
+	}
+	if f.emitFn == nil {
+		return EmitUnknownEvent
+	}
+	// the memory of the module is reused, so the data is copied before it is kept.
+	err := f.emitFn(ctx, string(event), bytes.Clone(data))
+	switch {
+	case err == nil:
+		return EmitOk
+	case errors.Is(err, ErrUnknownEvent):
+		return EmitUnknownEvent
+	case errors.Is(err, ErrInvalidEvent):
+		return EmitInvalidEvent
+	}
+	logger.Err(err).Msg("error emitting the event")
+	return EmitError
+}
+
+// Close releases the module and the host modules instantiated for it.
+func (f *Module) Close(ctx context.Context) error {
+	return f.runtime.Close(ctx)
+}
+
+func call(ctx context.Context, f api.Function, params ...uint64) ([]uint64, error) {
+	return f.Call(ctx, params...)
+}
@@ -0,0 +1,63 @@
+// Package wasm runs the Jobicolets, the WASM modules that process the events.
+//
+// It is a copy of goico's runtimes/wasm with three changes: the wazero runtime of a module is configured
+// with the memory limit of the module, closing a module closes its runtime too, and the modules can import
+// the emit host function. goico builds every runtime from one configuration, closes only the module and
+// takes no host function other than log, so it cannot be extended from here without changing its API. The
+// copy can be dropped once goico accepts them.
+package wasm
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/tetratelabs/wazero"
+)
+
+// PageSize is the size of a page of the linear memory of a module.
+const PageSize = 65536
+
+type Runtime struct {
+	cacheDir      string
+	cache         wazero.CompilationCache
+	runtimeConfig wazero.RuntimeConfig
+}
+
+func NewRuntimeWithCompilationCache(tempDir string) (*Runtime, error) {
+	if tempDir == "" {
+		return nil, errors.New("directory cannot be empty")
+	}
+	if err := os.MkdirAll(tempDir, 0o700); err != nil {
+		return nil, err
+	}
+	cacheDir, err := os.MkdirTemp(tempDir, "cache")
+	if err != nil {
+		return nil, err
+	}
+	cache, err := wazero.NewCompilationCacheWithDir(cacheDir)
+	if err != nil {
+		return nil, errors.Join(err, os.RemoveAll(cacheDir))
+	}
+	// the modules are closed when the context of a call is done, so a timeout stops a module that never returns.
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithCompilationCache(cache).
+		WithCloseOnContextDone(true)
+	return &Runtime{
+		cacheDir:      cacheDir,
+		cache:         cache,
+		runtimeConfig: runtimeConfig,
+	}, nil
+}
+
+// config returns the configuration of a module with the limits applied.
+func (r *Runtime) config(limits Limits) wazero.RuntimeConfig {
+	if limits.MaxMemoryPages > 0 {
+		return r.runtimeConfig.WithMemoryLimitPages(limits.MaxMemoryPages)
+	}
+	return r.runtimeConfig
+}
+
+func (r *Runtime) Close(ctx context.Context) error {
+	return errors.Join(r.cache.Close(ctx), os.RemoveAll(r.cacheDir))
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+)
+
+// advance records the step run by the item in its workflow instance, or starts an instance of the
+// workflows that begin with its event, and queues the steps that follow.
+func (p *processor) advance(ctx context.Context, event *event, item *pb.QueueItem, code uint64, result string) error {
+	if item.Workflow != nil {
+		return p.advanceStep(ctx, item.Workflow.ID, item.Workflow.Instance, item.Workflow.Step, code, result)
+	}
+	for wf, step := range event.workflows {
+		if err := p.advanceStep(ctx, wf, item.ID, step, code, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *processor) advanceStep(ctx context.Context, workflow string, instance string, step string, code uint64, result string) error {
+	starts, err := p.cli.ctl.AdvanceWorkflow(ctx, &pb.AdvanceWorkflowRequest{
+		Tenant:   p.tenant,
+		Package:  p.packageID,
+		Workflow: workflow,
+		Instance: instance,
+		Step:     step,
+		Code:     code,
+		Result:   result,
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range starts {
+		partitionKey, err := partitionKey(s.GetPartitionKey(), s.Data)
+		if err != nil {
+			return fmt.Errorf("partition key of step %s: %w", s.Step, err)
+		}
+		// the steps are returned again when the item is redelivered, so they are queued once.
+		key := workflow + "/" + instance + "/" + s.Step
+		_, err = p.cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: p.tenant,
+			Queue:  s.SupplierQueue,
+			Items: []*pb.QueueItem{
+				{
+					Event:        s.Event,
+					Data:         s.Data,
+					DedupKey:     &key,
+					PartitionKey: partitionKey,
+					Workflow: &pb.WorkflowStep{
+						ID:       workflow,
+						Instance: instance,
+						Step:     s.Step,
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
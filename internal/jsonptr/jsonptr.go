@@ -0,0 +1,49 @@
+// Package jsonptr resolves JSON pointers (RFC 6901) on events decoded by encoding/json.
+package jsonptr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Key resolves the pointer on the event and returns its value as a key. Strings are used as
+// they are, the rest of the values as JSON. A missing or null value is an error.
+func Key(pointer string, ev any) (string, error) {
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	value := ev
+	for _, token := range strings.Split(pointer, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := value.(type) {
+		case map[string]any:
+			field, ok := v[token]
+			if !ok {
+				return "", fmt.Errorf("%s not found", pointer)
+			}
+			value = field
+		case []any:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return "", fmt.Errorf("%s not found", pointer)
+			}
+			value = v[idx]
+		default:
+			return "", fmt.Errorf("%s not found", pointer)
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("%s is null", pointer)
+	case string:
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
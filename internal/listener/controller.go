@@ -4,15 +4,40 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/andrescosta/goico/pkg/service"
 	"github.com/andrescosta/jobico/internal/api/client"
 	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/jsonptr"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
+const (
+	// DelayHeader holds back the delivery of the events. Its value is a Go duration (e.g. 90s, 5m) or a number of seconds.
+	DelayHeader = "Jobico-Delay"
+	// PriorityHeader is the priority level of the events in their queue.
+	PriorityHeader = "Jobico-Priority"
+	// IdempotencyKeyHeader is the deduplication key of the events. When the request has many events,
+	// the key of each one is suffixed with its position.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// RetryAfterHeader is the number of seconds a sender should wait when the queue of the event is too deep.
+	RetryAfterHeader = "Retry-After"
+)
+
+// PostReply holds the IDs assigned to the events by the queue, in the order of the request.
+type PostReply struct {
+	IDs []string `json:"ids"`
+}
+
 type Controller struct {
 	ctx         context.Context
 	queue       *client.Queue
@@ -69,6 +94,21 @@ func (c Controller) Post(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	delay, err := parseDelay(request.Header.Get(DelayHeader))
+	if err != nil {
+		logger.Error().Msgf("Failed to parse %s header: %s", DelayHeader, err)
+		http.Error(writer, "Delay illegal", http.StatusBadRequest)
+		return
+	}
+	priority, err := parsePriority(request.Header.Get(PriorityHeader))
+	if err != nil {
+		logger.Error().Msgf("Failed to parse %s header: %s", PriorityHeader, err)
+		http.Error(writer, "Priority illegal", http.StatusBadRequest)
+		return
+	}
+
+	idempotencyKey := request.Header.Get(IdempotencyKeyHeader)
+
 	tenant := mux.Vars(request)["tenant_id"]
 	eventID := mux.Vars(request)["event_id"]
 	ef, err := c.eventsCache.Get(c.ctx, tenant, eventID)
@@ -97,8 +137,25 @@ func (c Controller) Post(writer http.ResponseWriter, request *http.Request) {
 		}
 
 		q := pb.QueueItem{
-			Data:  evBin,
-			Event: eventID,
+			Data:     evBin,
+			Event:    eventID,
+			Priority: priority,
+		}
+		if idempotencyKey != "" {
+			key := idempotencyKey
+			if len(event.Data) > 1 {
+				key = fmt.Sprintf("%s/%d", idempotencyKey, idx)
+			}
+			q.DedupKey = &key
+		}
+		if pointer := ef.EventDef.GetPartitionKey(); pointer != "" {
+			key, err := jsonptr.Key(pointer, ev)
+			if err != nil {
+				logger.Error().Msgf("Failed to get the partition key: %s", err)
+				http.Error(writer, "Partition key illegal", http.StatusBadRequest)
+				return
+			}
+			q.PartitionKey = &key
 		}
 		items[idx] = &q
 	}
@@ -108,11 +165,59 @@ func (c Controller) Post(writer http.ResponseWriter, request *http.Request) {
 		Tenant: tenant,
 		Items:  items,
 	}
-	err = c.queue.Queue(request.Context(), &queueRequest)
+	if delay > 0 {
+		queueRequest.Delay = durationpb.New(delay)
+	}
+	ids, err := c.queue.Queue(request.Context(), &queueRequest)
+	if retryAfter, ok := client.Backpressure(err); ok {
+		logger.Warn().Msgf("queue %s/%s rejected the events: %s", tenant, queueRequest.Queue, err)
+		writer.Header().Set(RetryAfterHeader, strconv.Itoa(max(int(math.Ceil(retryAfter.Seconds())), 1)))
+		http.Error(writer, "Too many events", http.StatusTooManyRequests)
+		return
+	}
+	// the request has more events than the queue takes at once.
+	if status.Code(err) == codes.InvalidArgument {
+		logger.Warn().Msgf("queue %s/%s rejected the events: %s", tenant, queueRequest.Queue, err)
+		http.Error(writer, "Too many events for the queue", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		logger.Error().Msgf("Failed to connect to connect to queue server: %s", err)
 		http.Error(writer, "", http.StatusInternalServerError)
 		return
 	}
+	writer.Header().Set("Content-Type", "application/json")
 	writer.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(writer).Encode(PostReply{IDs: ids}); err != nil {
+		logger.Error().Msgf("Failed to encode the reply: %s", err)
+	}
+}
+
+func parseDelay(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		secs, errSecs := strconv.ParseUint(value, 10, 32)
+		if errSecs != nil {
+			return 0, err
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	if d < 0 {
+		return 0, errors.New("negative delay")
+	}
+	return d, nil
+}
+
+func parsePriority(value string) (uint32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	p, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(p), nil
 }
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andrescosta/goico/pkg/collection"
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// DefaultRetryAfter is the time the senders are told to wait when a queue rejects their items.
+const DefaultRetryAfter = 5 * time.Second
+
+// depthSampling is how long a queue's sampled depth is trusted before the provider is asked again.
+// Reading the stats of a queue walks all its items, so it is not done on every request.
+const depthSampling = time.Second
+
+// backpressure tracks the queues that reached their high watermark.
+type backpressure struct {
+	retryAfter time.Duration
+	queues     *collection.SyncMap[string, struct{}]
+	depths     *collection.SyncMap[string, *sampledDepth]
+}
+
+// sampledDepth is the last depth read from a queue, adjusted with the items queued and acked since then.
+type sampledDepth struct {
+	mu      sync.Mutex
+	items   int
+	sampled time.Time
+}
+
+func newBackpressure(retryAfter time.Duration) *backpressure {
+	if retryAfter == 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	return &backpressure{
+		retryAfter: retryAfter,
+		queues:     collection.NewSyncMap[string, struct{}](),
+		depths:     collection.NewSyncMap[string, *sampledDepth](),
+	}
+}
+
+// depth returns the depth of the queue, reading it from the provider when the sample is too old.
+func (b *backpressure) depth(name string, q provider.Queue[*pb.QueueItem]) (int, error) {
+	d := b.depths.LoadOrStore(name, &sampledDepth{})
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if time.Since(d.sampled) < depthSampling {
+		return d.items, nil
+	}
+	st, err := q.Stats()
+	if err != nil {
+		return 0, err
+	}
+	d.items = st.Depth()
+	d.sampled = time.Now()
+	return d.items, nil
+}
+
+// changed adjusts the sampled depth of a queue with n items queued, or removed if n is negative.
+func (b *backpressure) changed(name string, n int) {
+	d, ok := b.depths.Load(name)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.items = max(d.items+n, 0)
+}
+
+func (b *backpressure) applied(name string) bool {
+	_, ok := b.queues.Load(name)
+	return ok
+}
+
+// checkBackpressure fails with RESOURCE_EXHAUSTED if the queue cannot take n more items. A queue that
+// reaches its high watermark rejects every item until its depth falls to the low watermark. The status
+// carries a RetryInfo with the time the sender should wait. A request with more items than the high
+// watermark can never be accepted, so it fails with INVALID_ARGUMENT instead.
+func (s *Controller) checkBackpressure(tenant string, queue string, q provider.Queue[*pb.QueueItem], def *pb.QueueDef, n int) error {
+	name := getQueueName(tenant, queue)
+	high := int(def.GetHighWatermark())
+	if high == 0 {
+		s.backpressure.queues.Delete(name)
+		s.backpressure.depths.Delete(name)
+		return nil
+	}
+	if n > high {
+		return status.Errorf(codes.InvalidArgument, "queue %s/%s takes at most %d items, the request has %d", tenant, queue, high, n)
+	}
+	low := high
+	if def.LowWatermark != nil {
+		low = min(int(*def.LowWatermark), high)
+	}
+	depth, err := s.backpressure.depth(name, q)
+	if err != nil {
+		return err
+	}
+	logger := zerolog.Ctx(s.ctx)
+	if s.backpressure.applied(name) {
+		if depth > low {
+			return s.backpressureError(tenant, queue, depth)
+		}
+		s.backpressure.queues.Delete(name)
+		logger.Info().Msgf("queue %s/%s: depth %d reached the low watermark, accepting items again", tenant, queue, depth)
+	}
+	if depth >= high {
+		s.backpressure.queues.Store(name, struct{}{})
+		logger.Warn().Msgf("queue %s/%s: depth %d reached the high watermark, rejecting items", tenant, queue, depth)
+		return s.backpressureError(tenant, queue, depth)
+	}
+	if depth+n > high {
+		return s.backpressureError(tenant, queue, depth)
+	}
+	return nil
+}
+
+func (s *Controller) backpressureError(tenant string, queue string, depth int) error {
+	st := status.Newf(codes.ResourceExhausted, "queue %s/%s is too deep: %d items", tenant, queue, depth)
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(s.backpressure.retryAfter)})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
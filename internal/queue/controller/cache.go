@@ -3,6 +3,12 @@ package controller
 import (
 	"context"
 	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/andrescosta/goico/pkg/collection"
 	"github.com/andrescosta/goico/pkg/service"
@@ -17,7 +23,31 @@ var ErrQueueUnknown = errors.New("queue unknown")
 
 type Option struct {
 	InMemory bool
-	Dir      string
+	// Log stores the queues in segmented append-only logs instead of a file per item.
+	Log       bool
+	LogOption provider.LogOption
+	// Pebble stores the queues in a Pebble database shared by all of them, kept in memory if InMemory is set.
+	Pebble            bool
+	Dir               string
+	VisibilityTimeout time.Duration
+	// PriorityAging is the waiting time after which an item is served as if it had one more level of priority.
+	PriorityAging time.Duration
+	// Cluster spreads the queues across the nodes of the queue service declared by the environment.
+	// Node is the address of this node in the environment.
+	Cluster bool
+	Node    string
+	// RebalanceInterval is the time between the checks for queues owned by other nodes.
+	RebalanceInterval time.Duration
+	Replication       ReplicationOption
+	// DrainInterval is the time between the checks for draining queues that are empty.
+	DrainInterval time.Duration
+	// ExpirationInterval is the time between the checks for expired items.
+	ExpirationInterval time.Duration
+	// RetryAfter is the time the senders are told to wait when a queue reached its high watermark.
+	RetryAfter time.Duration
+	// Compression stores the data of the items compressed with zstd.
+	Compression bool
+	Encryption  EncryptionOption
 }
 
 type QueueBuilder[T any] func(string) (provider.Queue[T], error)
@@ -25,25 +55,75 @@ type QueueBuilder[T any] func(string) (provider.Queue[T], error)
 type Cache[T any] struct {
 	init         *syncutil.OnceDisposable
 	queues       *collection.SyncMap[string, provider.Queue[T]]
+	defs         *collection.SyncMap[string, *pb.QueueDef]
 	queueBuilder QueueBuilder[T]
 	ctl          *client.Ctl
+	// store is the database shared by the queues, if the provider uses one.
+	store io.Closer
+	// wrap decorates the queues when they are built, if it is set.
+	wrap func(name string, queue provider.Queue[T]) provider.Queue[T]
+	// dir holds the archives and the audit log of the deleted queues. It is empty if the queues are in memory.
+	dir           string
+	drainInterval time.Duration
+	draining      *collection.SyncMap[string, struct{}]
+	// lifecycle serializes the removal of the queues with their creation.
+	lifecycle sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      sync.WaitGroup
 }
 
 func NewCache[T any](ctx context.Context, dialer service.GrpcDialer, o Option) (*Cache[T], error) {
 	syncmap := collection.NewSyncMap[string, provider.Queue[T]]()
-	queueBuilder := func(id string) (provider.Queue[T], error) { return provider.NewFileQueue[T](o.Dir, id) }
-	if o.InMemory {
-		queueBuilder = func(_ string) (provider.Queue[T], error) { return provider.NewMemBasedQueue[T]() }
+	aging := o.PriorityAging
+	if aging == 0 {
+		aging = DefaultPriorityAging
+	}
+	queueBuilder := func(id string) (provider.Queue[T], error) { return provider.NewFileQueue[T](o.Dir, id, aging) }
+	if o.Log {
+		queueBuilder = func(id string) (provider.Queue[T], error) {
+			return provider.NewLogQueue[T](o.Dir, id, aging, o.LogOption)
+		}
+	}
+	var store io.Closer
+	if o.Pebble {
+		pebbleStore, err := provider.NewPebbleStore(ctx, filepath.Join(o.Dir, "pebble"), o.InMemory)
+		if err != nil {
+			return nil, err
+		}
+		store = pebbleStore
+		queueBuilder = func(id string) (provider.Queue[T], error) {
+			return provider.NewPebbleQueue[T](pebbleStore, id, aging)
+		}
+	} else if o.InMemory {
+		queueBuilder = func(_ string) (provider.Queue[T], error) { return provider.NewMemBasedQueue[T](aging) }
 	}
 	ctl, err := client.NewCtl(ctx, dialer)
 	if err != nil {
+		if store != nil {
+			err = errors.Join(err, store.Close())
+		}
 		return nil, err
 	}
+	drainInterval := o.DrainInterval
+	if drainInterval == 0 {
+		drainInterval = DefaultDrainInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
 	cache := &Cache[T]{
-		init:         syncutil.NewOnceDisposable(),
-		queues:       syncmap,
-		ctl:          ctl,
-		queueBuilder: queueBuilder,
+		init:          syncutil.NewOnceDisposable(),
+		queues:        syncmap,
+		defs:          collection.NewSyncMap[string, *pb.QueueDef](),
+		ctl:           ctl,
+		queueBuilder:  queueBuilder,
+		store:         store,
+		drainInterval: drainInterval,
+		draining:      collection.NewSyncMap[string, struct{}](),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	if !o.InMemory {
+		cache.dir = o.Dir
 	}
 	return cache, nil
 }
@@ -59,12 +139,21 @@ func (q *Cache[T]) populate(ctx context.Context) error {
 }
 
 func (q *Cache[T]) Close() error {
+	q.cancel()
+	q.done.Wait()
 	err := q.init.Dispose(context.Background(), func(_ context.Context) error {
 		if q.ctl != nil {
 			return q.ctl.Close()
 		}
 		return nil
 	})
+	q.queues.Range(func(_ string, queue provider.Queue[T]) bool {
+		err = errors.Join(err, closeQueue(queue))
+		return true
+	})
+	if q.store != nil {
+		err = errors.Join(err, q.store.Close())
+	}
 	return err
 }
 
@@ -80,6 +169,49 @@ func (q *Cache[T]) GetQueue(ctx context.Context, tentant string, queueID string)
 	return queue, nil
 }
 
+func (q *Cache[T]) GetQueueDef(ctx context.Context, tentant string, queueID string) (*pb.QueueDef, error) {
+	err := q.init.Do(ctx, q.populate)
+	if err != nil {
+		return nil, err
+	}
+	def, ok := q.defs.Load(getQueueName(tentant, queueID))
+	if !ok {
+		return nil, ErrQueueUnknown
+	}
+	return def, nil
+}
+
+// QueueIDs returns the IDs of the queues of a tenant, sorted.
+func (q *Cache[T]) QueueIDs(ctx context.Context, tenant string) ([]string, error) {
+	err := q.init.Do(ctx, q.populate)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0)
+	prefix := getQueueName(tenant, "")
+	q.defs.Range(func(name string, def *pb.QueueDef) bool {
+		if strings.HasPrefix(name, prefix) {
+			ids = append(ids, def.ID)
+		}
+		return true
+	})
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// Range calls fn for the definition of every queue until it returns false.
+func (q *Cache[T]) Range(ctx context.Context, fn func(tenant string, def *pb.QueueDef) bool) error {
+	err := q.init.Do(ctx, q.populate)
+	if err != nil {
+		return err
+	}
+	q.defs.Range(func(name string, def *pb.QueueDef) bool {
+		tenant, _ := splitQueueName(name)
+		return fn(tenant, def)
+	})
+	return nil
+}
+
 func (q *Cache[T]) addPackages(ctx context.Context) error {
 	pkgs, err := q.ctl.AllPackages(ctx)
 	if err != nil {
@@ -101,7 +233,13 @@ func (q *Cache[T]) startListeningUpdates(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
 				return
-			case u := <-l.C:
+			case <-q.ctx.Done():
+				return
+			case u, ok := <-l.C:
+				// the channel is closed with the connection to ctl.
+				if !ok {
+					return
+				}
 				q.onUpdate(ctx, u)
 			}
 		}
@@ -113,7 +251,7 @@ func (q *Cache[T]) onUpdate(ctx context.Context, u *pb.UpdateToPackagesStrReply)
 	logger := zerolog.Ctx(ctx)
 	switch u.Type {
 	case pb.UpdateType_Delete:
-		q.delete(u.Object.Tenant, u.Object.Queues)
+		q.delete(ctx, u.Object.Tenant, u.Object.Queues)
 	case pb.UpdateType_New, pb.UpdateType_Update:
 		if err := q.addOrUpdate(ctx, []*pb.JobPackage{u.Object}); err != nil {
 			logger.Warn().AnErr("error", err).Msg("onUpdate: error updating queue")
@@ -121,12 +259,6 @@ func (q *Cache[T]) onUpdate(ctx context.Context, u *pb.UpdateToPackagesStrReply)
 	}
 }
 
-func (q *Cache[T]) delete(tenant string, qs []*pb.QueueDef) {
-	for _, queue := range qs {
-		q.queues.Delete(getQueueName(tenant, queue.ID))
-	}
-}
-
 func (q *Cache[T]) addOrUpdate(ctx context.Context, pkgs []*pb.JobPackage) error {
 	for _, ps := range pkgs {
 		tenant := ps.Tenant
@@ -141,16 +273,41 @@ func (q *Cache[T]) addOrUpdate(ctx context.Context, pkgs []*pb.JobPackage) error
 }
 
 func (q *Cache[T]) addOrUpdateQueue(ctx context.Context, tenant string, def *pb.QueueDef) error {
+	q.lifecycle.Lock()
+	defer q.lifecycle.Unlock()
 	name := getQueueName(tenant, def.ID)
+	q.defs.Store(name, def)
+	// a draining queue whose package is deployed again keeps its items.
+	q.draining.Delete(name)
+	// the queue is built only once because the providers recover the state left by previous instances.
+	if _, ok := q.queues.Load(name); ok {
+		return nil
+	}
 	queue, err := q.queueBuilder(name)
 	if err != nil {
 		return err
 	}
+	if q.wrap != nil {
+		queue = q.wrap(name, queue)
+	}
 	zerolog.Ctx(ctx).Debug().Msgf("New queue:%s", def.ID)
 	_ = q.queues.LoadOrStore(name, queue)
 	return nil
 }
 
+// closeQueue releases the resources held by the providers that keep files open.
+func closeQueue[T any](queue provider.Queue[T]) error {
+	if c, ok := queue.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 func getQueueName(tenant string, queueID string) string {
 	return tenant + "/" + queueID
 }
+
+func splitQueueName(name string) (string, string) {
+	tenant, queueID, _ := strings.Cut(name, "/")
+	return tenant, queueID
+}
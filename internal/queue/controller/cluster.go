@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/andrescosta/goico/pkg/service"
+	"github.com/andrescosta/jobico/internal/api/client"
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+)
+
+// DefaultRebalanceInterval is the time between the checks for queues owned by other nodes of the cluster.
+const DefaultRebalanceInterval = 5 * time.Second
+
+// cluster moves the items of the queues owned by other nodes to them. The nodes are the servers of the
+// queue service of the environment, and every queue is owned by the node the ring assigns it to, so when a
+// node joins or leaves, the nodes that no longer own a queue forward its items to the new owner.
+// The items of the dead-letter queues stay where they are, and clients query every node for them.
+type cluster struct {
+	node     string
+	dialer   service.GrpcDialer
+	interval time.Duration
+	peers    map[string]*client.Queue
+	cancel   context.CancelFunc
+	done     sync.WaitGroup
+}
+
+func newCluster(d service.GrpcDialer, o Option) *cluster {
+	interval := o.RebalanceInterval
+	if interval == 0 {
+		interval = DefaultRebalanceInterval
+	}
+	return &cluster{
+		node:     o.Node,
+		dialer:   d,
+		interval: interval,
+		peers:    make(map[string]*client.Queue),
+	}
+}
+
+func (s *Controller) startRebalancing() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cluster.cancel = cancel
+	s.cluster.done.Add(1)
+	go func() {
+		defer s.cluster.done.Done()
+		logger := zerolog.Ctx(ctx)
+		ticker := time.NewTicker(s.cluster.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.rebalance(ctx); err != nil && ctx.Err() == nil {
+					logger.Warn().AnErr("error", err).Msg("error moving queues to other nodes")
+				}
+			}
+		}
+	}()
+}
+
+func (c *cluster) stop() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.done.Wait()
+	var err error
+	for _, p := range c.peers {
+		err = errors.Join(err, p.Close())
+	}
+	return err
+}
+
+// rebalance forwards the items of the queues owned by other nodes.
+func (s *Controller) rebalance(ctx context.Context) error {
+	// the items of a replica are moved by its primary.
+	if s.checkPrimary() != nil {
+		return nil
+	}
+	environment, err := s.cache.ctl.Environment(ctx)
+	if err != nil {
+		return err
+	}
+	ring := client.NewRing(client.QueueNodes(environment))
+	if len(ring.Nodes()) == 0 {
+		return nil
+	}
+	s.cluster.closePeers(ring)
+	deadLetterQueues := make(map[string]struct{})
+	owners := make(map[string]string)
+	err = s.cache.Range(ctx, func(tenant string, def *pb.QueueDef) bool {
+		if def.DeadLetterQueue != nil {
+			deadLetterQueues[getQueueName(tenant, *def.DeadLetterQueue)] = struct{}{}
+		}
+		// a draining queue is emptied where it is.
+		if s.cache.Draining(tenant, def.ID) {
+			return true
+		}
+		if owner := ring.Node(tenant, def.ID); owner != s.cluster.node {
+			owners[getQueueName(tenant, def.ID)] = owner
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	var errs error
+	for name, owner := range owners {
+		if _, ok := deadLetterQueues[name]; ok {
+			continue
+		}
+		errs = errors.Join(errs, s.forward(ctx, name, owner))
+	}
+	return errs
+}
+
+// forward moves the visible items of a queue to its owner. The leased items are moved if they are
+// released, and the delayed ones once they are due.
+func (s *Controller) forward(ctx context.Context, name string, owner string) error {
+	tenant, queue := splitQueueName(name)
+	myqueue, err := s.cache.GetQueue(ctx, tenant, queue)
+	if err != nil {
+		return err
+	}
+	peer, err := s.cluster.peer(ctx, owner)
+	if err != nil {
+		return err
+	}
+	for {
+		ls, err := myqueue.Lease(provider.MaxItems, s.visibilityTimeout)
+		if errors.Is(err, provider.ErrQueueEmpty) || (err == nil && len(ls) == 0) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		items := make([]*pb.QueueItem, len(ls))
+		deliveries := make([]*pb.Deliveries, len(ls))
+		for i, l := range ls {
+			item := l.Data
+			item.Priority = l.Priority
+			if l.PartitionKey != "" {
+				key := l.PartitionKey
+				item.PartitionKey = &key
+			}
+			items[i] = item
+			// the lease taken to forward the item is not a delivery.
+			deliveries[i] = &pb.Deliveries{Attempts: l.Attempts - 1, Failures: deliveryFailures(l.Failures)}
+		}
+		if _, err := peer.Queue(ctx, &pb.QueueRequest{Tenant: tenant, Queue: queue, Items: items, Deliveries: deliveries}); err != nil {
+			for _, l := range ls {
+				err = errors.Join(err, myqueue.Nack(l.Receipt, "", 0))
+			}
+			return err
+		}
+		for _, l := range ls {
+			if err := s.applied(myqueue.Ack(l.Receipt), tenant, queue); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *cluster) peer(ctx context.Context, addr string) (*client.Queue, error) {
+	if p, ok := c.peers[addr]; ok {
+		return p, nil
+	}
+	p, err := client.NewQueueForNode(ctx, c.dialer, addr)
+	if err != nil {
+		return nil, err
+	}
+	c.peers[addr] = p
+	return p, nil
+}
+
+// closePeers closes the connections to the nodes that left the ring.
+func (c *cluster) closePeers(ring *client.Ring) {
+	nodes := make(map[string]struct{})
+	for _, n := range ring.Nodes() {
+		nodes[n] = struct{}{}
+	}
+	for addr, p := range c.peers {
+		if _, ok := nodes[addr]; !ok {
+			_ = p.Close()
+			delete(c.peers, addr)
+		}
+	}
+}
@@ -3,15 +3,35 @@ package controller
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/andrescosta/goico/pkg/service"
 	pb "github.com/andrescosta/jobico/internal/api/types"
 	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	DefaultVisibilityTimeout = 30 * time.Second
+	DefaultPriorityAging     = 1 * time.Minute
+	// MaxWaitTime is the longest a dequeue request waits for items to arrive.
+	MaxWaitTime = 20 * time.Second
 )
 
 type Controller struct {
-	cache *Cache[*pb.QueueItem]
-	ctx   context.Context
+	cache             *Cache[*pb.QueueItem]
+	ctx               context.Context
+	visibilityTimeout time.Duration
+	subscriptions     *subscriptions
+	dedup             *dedup
+	// cluster is set when the queues are spread across many nodes.
+	cluster *cluster
+	// replication is set when the node has replicas or is a replica.
+	replication *replication
+	retention   *retention
+	// backpressure holds the queues that reject items until their consumers catch up.
+	backpressure *backpressure
 }
 
 func New(ctx context.Context, d service.GrpcDialer, o Option) (*Controller, error) {
@@ -19,42 +39,304 @@ func New(ctx context.Context, d service.GrpcDialer, o Option) (*Controller, erro
 	if err != nil {
 		return nil, err
 	}
-	return &Controller{
-		cache: c,
-		ctx:   ctx,
-	}, nil
+	visibilityTimeout := o.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = DefaultVisibilityTimeout
+	}
+	r, err := newRetention(o.ExpirationInterval)
+	if err != nil {
+		return nil, errors.Join(err, c.Close())
+	}
+	sealing, err := newSealing(ctx, o)
+	if err != nil {
+		return nil, errors.Join(err, c.Close())
+	}
+	c.wrap = sealing.wrap
+	s := &Controller{
+		cache:             c,
+		ctx:               ctx,
+		visibilityTimeout: visibilityTimeout,
+		subscriptions:     newSubscriptions(),
+		dedup:             newDedup(),
+		retention:         r,
+		backpressure:      newBackpressure(o.RetryAfter),
+	}
+	if len(o.Replication.Replicas) > 0 || o.Replication.Replica {
+		r, err := newReplication(d, o.Node, o.Replication)
+		if err != nil {
+			return nil, errors.Join(err, c.Close())
+		}
+		s.replication = r
+		// the replicas receive the items as they are queued and seal them with their own keys.
+		c.wrap = func(name string, q provider.Queue[*pb.QueueItem]) provider.Queue[*pb.QueueItem] {
+			return newReplicatedQueue(name, sealing.wrap(name, q), r)
+		}
+		s.startReplication()
+	}
+	if o.Cluster {
+		s.cluster = newCluster(d, o)
+		s.startRebalancing()
+	}
+	s.startExpiring()
+	return s, nil
 }
 
-func (s *Controller) Queue(in *pb.QueueRequest) (*pb.Void, error) {
+func (s *Controller) Queue(in *pb.QueueRequest) (*pb.QueueReply, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
 	myqueue, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
 	if err != nil {
 		return nil, err
 	}
-	for _, i := range in.Items {
-		if err := myqueue.Add(i); err != nil {
+	if s.cache.Draining(in.Tenant, in.Queue) {
+		return nil, ErrQueueDraining
+	}
+	def, err := s.cache.GetQueueDef(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var delayed time.Time
+	if in.Delay != nil {
+		delayed = now.Add(in.Delay.AsDuration())
+	}
+	window := DefaultDedupWindow
+	if def.DedupWindowSeconds != nil {
+		window = time.Duration(*def.DedupWindowSeconds) * time.Second
+	}
+	if err := s.checkBackpressure(in.Tenant, in.Queue, myqueue, def, len(in.Items)); err != nil {
+		return nil, err
+	}
+	if limited(def) {
+		unlock := s.retention.lock(getQueueName(in.Tenant, in.Queue))
+		defer unlock()
+		if err := s.makeRoom(in.Tenant, in.Queue, myqueue, def, in.Items); err != nil {
 			return nil, err
 		}
 	}
-	ret := pb.Void{}
-	return &ret, nil
+	name := getQueueName(in.Tenant, in.Queue)
+	ids := make([]string, len(in.Items))
+	ms := make([]provider.Message[*pb.QueueItem], 0, len(in.Items))
+	// the keys reserved by the request are released if the items are not queued.
+	keys := make([]string, 0)
+	forget := func() {
+		for _, key := range keys {
+			s.dedup.forget(name, key)
+		}
+	}
+	for idx, i := range in.Items {
+		// the items forwarded by another node of the cluster keep their ID.
+		id := i.ID
+		if id == "" {
+			id, err = provider.NewID()
+			if err != nil {
+				forget()
+				return nil, err
+			}
+		}
+		key := i.GetDedupKey()
+		if key != "" && window > 0 {
+			original, dup := s.dedup.reserve(name, key, id, window, now)
+			if dup {
+				ids[idx] = original
+				continue
+			}
+			keys = append(keys, key)
+		}
+		i.ID = id
+		if i.QueuedAt == nil {
+			i.QueuedAt = timestamppb.New(now)
+		}
+		// an item's own notBefore takes precedence over the request delay.
+		notBefore := delayed
+		if i.NotBefore != nil {
+			notBefore = i.NotBefore.AsTime()
+		} else if !notBefore.IsZero() {
+			i.NotBefore = timestamppb.New(notBefore)
+		}
+		// priorities above the levels declared by the queue are served from the highest one.
+		if levels := def.GetPriorityLevels(); i.Priority >= levels {
+			i.Priority = max(levels, 1) - 1
+		}
+		m := provider.Message[*pb.QueueItem]{Data: i, NotBefore: notBefore, Priority: i.Priority, PartitionKey: i.GetPartitionKey()}
+		if idx < len(in.Deliveries) {
+			m.Attempts = in.Deliveries[idx].Attempts
+			m.Failures = failures(in.Deliveries[idx].Failures)
+		}
+		ms = append(ms, m)
+		ids[idx] = id
+	}
+	// a request is queued entirely or not at all, so the sender can retry it without duplicating items.
+	reply := &pb.QueueReply{Ids: ids}
+	if err := myqueue.AddAll(ms); err != nil {
+		if !unreplicated(err) {
+			forget()
+			return nil, err
+		}
+		// the items are queued, so their keys are kept and the sender is not asked to retry.
+		zerolog.Ctx(s.ctx).Warn().AnErr("error", err).Msgf("queue %s: items queued but not confirmed by the replicas", name)
+		reply.Unreplicated = true
+	}
+	s.backpressure.changed(name, len(ms))
+	s.subscriptions.notify(name)
+	return reply, nil
 }
 
 func (s *Controller) Close() error {
-	return s.cache.Close()
+	var err error
+	if s.cluster != nil {
+		err = s.cluster.stop()
+	}
+	if s.replication != nil {
+		s.replication.stop()
+	}
+	s.retention.stop()
+	return errors.Join(err, s.cache.Close())
+}
+
+// Dequeue leases the next items of a queue. If the queue is empty and the request has a wait time,
+// it waits for items to arrive until the time elapses.
+func (s *Controller) Dequeue(ctx context.Context, in *pb.DequeueRequest) (*pb.DequeueReply, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
+	myqueue, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	visibilityTimeout := s.visibilityTimeout
+	if in.VisibilityTimeout != nil {
+		visibilityTimeout = in.VisibilityTimeout.AsDuration()
+	}
+	n := provider.MaxItems
+	if in.MaxItems != nil {
+		n = min(max(int(*in.MaxItems), 1), provider.MaxItems)
+	}
+	var wait <-chan time.Time
+	var wake <-chan struct{}
+	if in.WaitTime != nil && in.WaitTime.AsDuration() > 0 {
+		timer := time.NewTimer(min(in.WaitTime.AsDuration(), MaxWaitTime))
+		defer timer.Stop()
+		wait = timer.C
+		// the waiter holds no credits, so it is only woken up when items are queued.
+		name := getQueueName(in.Tenant, in.Queue)
+		sub := s.subscriptions.add(name, 0)
+		defer s.subscriptions.remove(name, sub)
+		wake = sub.wake
+	}
+	var poll <-chan time.Time
+	for {
+		ls, err := myqueue.Lease(n, visibilityTimeout)
+		if err != nil && !errors.Is(err, provider.ErrQueueEmpty) {
+			return nil, err
+		}
+		ls = s.deadLetter(in.Tenant, in.Queue, myqueue, ls)
+		ls = s.expire(in.Tenant, in.Queue, myqueue, ls)
+		if len(ls) > 0 || wait == nil {
+			iqs := make([]*pb.DequeuedItem, len(ls))
+			for i, l := range ls {
+				iqs[i] = dequeuedItem(l)
+			}
+			return &pb.DequeueReply{
+				Items: iqs,
+			}, nil
+		}
+		if poll == nil {
+			ticker := time.NewTicker(subscriptionPoll)
+			defer ticker.Stop()
+			poll = ticker.C
+		}
+		select {
+		case <-s.ctx.Done():
+			return &pb.DequeueReply{}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+			wait = nil
+		case <-wake:
+		case <-poll:
+		}
+	}
 }
 
-func (s *Controller) Dequeue(in *pb.DequeueRequest) (*pb.DequeueReply, error) {
+func (s *Controller) Ack(in *pb.AckRequest) (*pb.Void, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
 	myqueue, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
 	if err != nil {
 		return nil, err
 	}
-	i, err := myqueue.Remove()
-	if err != nil && !errors.Is(err, provider.ErrQueueEmpty) {
+	name := getQueueName(in.Tenant, in.Queue)
+	acks, err := ackAll(myqueue, in.Receipts)
+	// the acknowledged items are gone from the node even if the replicas did not confirm it.
+	errs := s.applied(err, in.Tenant, in.Queue)
+	for i, r := range in.Receipts {
+		if acks[i] != nil {
+			errs = errors.Join(errs, acks[i])
+			continue
+		}
+		s.backpressure.changed(name, -1)
+		s.subscriptions.release(name, r)
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return &pb.Void{}, nil
+}
+
+// ackAll acknowledges the receipts and returns the error of each one. Replicated queues wait for their
+// replicas once for all of them.
+func ackAll(q provider.Queue[*pb.QueueItem], receipts []string) ([]error, error) {
+	if r, ok := q.(*replicatedQueue); ok {
+		return r.ackAll(receipts)
+	}
+	errs := make([]error, len(receipts))
+	for i, r := range receipts {
+		errs[i] = q.Ack(r)
+	}
+	return errs, nil
+}
+
+func (s *Controller) Nack(in *pb.NackRequest) (*pb.Void, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
+	myqueue, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
 		return nil, err
 	}
-	var iqs []*pb.QueueItem
-	iqs = append(iqs, i...)
-	return &pb.DequeueReply{
-		Items: iqs,
-	}, nil
+	reason := "nack"
+	if in.Reason != nil {
+		reason = *in.Reason
+	}
+	delay := in.GetDelay().AsDuration()
+	name := getQueueName(in.Tenant, in.Queue)
+	var errs error
+	for _, r := range in.Receipts {
+		// the item is still in the queue, so its depth does not change.
+		if err := myqueue.Nack(r, reason, delay); err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		s.subscriptions.release(name, r)
+	}
+	// the rejected items are visible again, unless they are delayed.
+	if delay == 0 {
+		s.subscriptions.notify(name)
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return &pb.Void{}, nil
+}
+
+func dequeuedItem(l provider.Leased[*pb.QueueItem]) *pb.DequeuedItem {
+	return &pb.DequeuedItem{
+		Item:      l.Data,
+		Receipt:   l.Receipt,
+		VisibleAt: timestamppb.New(l.VisibleAt),
+		Attempts:  l.Attempts,
+	}
 }
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// requeueVisibility is the lease used while items are moved out of a dead-letter queue.
+const requeueVisibility = 1 * time.Minute
+
+// deadLetter moves the items that exceeded the delivery attempts of the queue to its dead-letter queue
+// and returns the ones that can be delivered.
+func (s *Controller) deadLetter(tenant string, queue string, q provider.Queue[*pb.QueueItem], ls []provider.Leased[*pb.QueueItem]) []provider.Leased[*pb.QueueItem] {
+	logger := zerolog.Ctx(s.ctx)
+	def, err := s.cache.GetQueueDef(s.ctx, tenant, queue)
+	if err != nil || def.MaxAttempts == nil || *def.MaxAttempts == 0 {
+		return ls
+	}
+	deliver := make([]provider.Leased[*pb.QueueItem], 0, len(ls))
+	for _, l := range ls {
+		if l.Attempts <= *def.MaxAttempts {
+			deliver = append(deliver, l)
+			continue
+		}
+		if def.DeadLetterQueue == nil {
+			logger.Warn().Msgf("queue %s/%s: discarding item of event %s after %d attempts", tenant, queue, l.Data.Event, l.Attempts-1)
+		} else {
+			if err := s.applied(s.moveToDeadLetter(tenant, queue, *def.DeadLetterQueue, l, l.Failures), tenant, *def.DeadLetterQueue); err != nil {
+				// the item is delivered instead of being lost.
+				logger.Warn().AnErr("error", err).Msgf("queue %s/%s: error moving item to the dead-letter queue %s", tenant, queue, *def.DeadLetterQueue)
+				deliver = append(deliver, l)
+				continue
+			}
+		}
+		if err := s.applied(q.Ack(l.Receipt), tenant, queue); err != nil {
+			logger.Warn().AnErr("error", err).Msgf("queue %s/%s: error removing dead-lettered item", tenant, queue)
+		}
+	}
+	return deliver
+}
+
+// moveToDeadLetter adds a leased item to the dead-letter queue dlq. The item must be acknowledged after that.
+func (s *Controller) moveToDeadLetter(tenant string, queue string, dlq string, l provider.Leased[*pb.QueueItem], failures []provider.Failure) error {
+	q, err := s.cache.GetQueue(s.ctx, tenant, dlq)
+	if err != nil {
+		return err
+	}
+	return q.Add(provider.Message[*pb.QueueItem]{
+		Data:         l.Data,
+		Priority:     l.Priority,
+		Attempts:     l.Attempts - 1,
+		Failures:     failures,
+		Source:       queue,
+		PartitionKey: l.PartitionKey,
+	})
+}
+
+func (s *Controller) DeadLetters(in *pb.DeadLettersRequest) (*pb.DeadLettersReply, error) {
+	dlq, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	limit := provider.MaxItems
+	if in.Limit != nil {
+		limit = int(*in.Limit)
+	}
+	ms, err := dlq.Peek(limit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*pb.DeadLetter, len(ms))
+	for i, m := range ms {
+		items[i] = &pb.DeadLetter{
+			Item:        m.Data,
+			SourceQueue: m.Source,
+			Attempts:    m.Attempts,
+			Failures:    deliveryFailures(m.Failures),
+		}
+	}
+	return &pb.DeadLettersReply{Items: items}, nil
+}
+
+// RequeueDeadLetters moves the items of a dead-letter queue back to the queue they came from,
+// resetting their delivery attempts.
+func (s *Controller) RequeueDeadLetters(in *pb.RequeueDeadLettersRequest) (*pb.RequeueDeadLettersReply, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
+	dlq, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	requeued := uint32(0)
+	for in.Limit == nil || requeued < *in.Limit {
+		n := provider.MaxItems
+		if in.Limit != nil {
+			n = int(*in.Limit - requeued)
+		}
+		ls, err := dlq.Lease(n, requeueVisibility)
+		if err != nil {
+			if errors.Is(err, provider.ErrQueueEmpty) {
+				break
+			}
+			return nil, err
+		}
+		for _, l := range ls {
+			if err := s.requeue(in.Tenant, l); err != nil {
+				return nil, errors.Join(err, dlq.Nack(l.Receipt, err.Error(), 0))
+			}
+			if err := s.applied(dlq.Ack(l.Receipt), in.Tenant, in.Queue); err != nil {
+				return nil, err
+			}
+			requeued++
+		}
+	}
+	return &pb.RequeueDeadLettersReply{Requeued: requeued}, nil
+}
+
+func (s *Controller) requeue(tenant string, l provider.Leased[*pb.QueueItem]) error {
+	if l.Source == "" {
+		return errors.New("the item has no source queue")
+	}
+	q, err := s.cache.GetQueue(s.ctx, tenant, l.Source)
+	if err != nil {
+		return err
+	}
+	// the time to live of the item starts over.
+	l.Data.QueuedAt = timestamppb.Now()
+	err = q.Add(provider.Message[*pb.QueueItem]{
+		Data:         l.Data,
+		Priority:     l.Priority,
+		Failures:     l.Failures,
+		PartitionKey: l.PartitionKey,
+	})
+	if err := s.applied(err, tenant, l.Source); err != nil {
+		return err
+	}
+	s.subscriptions.notify(getQueueName(tenant, l.Source))
+	return nil
+}
+
+func (s *Controller) PurgeDeadLetters(in *pb.PurgeDeadLettersRequest) (*pb.PurgeDeadLettersReply, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
+	dlq, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	n, err := dlq.Purge()
+	if err := s.applied(err, in.Tenant, in.Queue); err != nil {
+		return nil, err
+	}
+	return &pb.PurgeDeadLettersReply{Purged: uint32(n)}, nil
+}
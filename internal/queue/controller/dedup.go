@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDedupWindow is the deduplication window of the queues that do not declare one.
+	DefaultDedupWindow = 5 * time.Minute
+	// dedupPrune is how often the expired keys are removed.
+	dedupPrune = 1 * time.Minute
+)
+
+// dedup remembers the deduplication keys of the items queued recently. The keys are kept in memory,
+// so the window starts over when the queue restarts.
+type dedup struct {
+	mutex   sync.Mutex
+	byQueue map[string]map[string]dedupEntry
+	pruned  time.Time
+}
+
+type dedupEntry struct {
+	id        string
+	expiresAt time.Time
+}
+
+func newDedup() *dedup {
+	return &dedup{
+		byQueue: make(map[string]map[string]dedupEntry),
+		pruned:  time.Now(),
+	}
+}
+
+// reserve returns the ID of the item queued with key within the window, if any. Otherwise, it
+// records id for the key and returns false.
+func (d *dedup) reserve(queue string, key string, id string, window time.Duration, now time.Time) (string, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.prune(now)
+	keys, ok := d.byQueue[queue]
+	if !ok {
+		keys = make(map[string]dedupEntry)
+		d.byQueue[queue] = keys
+	}
+	if e, ok := keys[key]; ok && now.Before(e.expiresAt) {
+		return e.id, true
+	}
+	keys[key] = dedupEntry{id: id, expiresAt: now.Add(window)}
+	return id, false
+}
+
+// forget removes a key reserved for an item that could not be queued.
+func (d *dedup) forget(queue string, key string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.byQueue[queue], key)
+}
+
+func (d *dedup) prune(now time.Time) {
+	if now.Sub(d.pruned) < dedupPrune {
+		return
+	}
+	d.pruned = now
+	for queue, keys := range d.byQueue {
+		for key, e := range keys {
+			if !now.Before(e.expiresAt) {
+				delete(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			delete(d.byQueue, queue)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrPurgeNotConfirmed = errors.New("purge not confirmed: confirm must be the name of the queue")
+
+// Stats returns the statistics of a queue, or of every queue of the tenant if none is requested.
+func (s *Controller) Stats(in *pb.StatsRequest) (*pb.StatsReply, error) {
+	queues := []string{in.Queue}
+	if in.Queue == "" {
+		ids, err := s.cache.QueueIDs(s.ctx, in.Tenant)
+		if err != nil {
+			return nil, err
+		}
+		queues = ids
+	}
+	now := time.Now()
+	stats := make([]*pb.QueueStats, len(queues))
+	for i, queue := range queues {
+		myqueue, err := s.cache.GetQueue(s.ctx, in.Tenant, queue)
+		if err != nil {
+			return nil, err
+		}
+		st, err := myqueue.Stats()
+		if err != nil {
+			return nil, err
+		}
+		stats[i] = &pb.QueueStats{
+			Queue:        queue,
+			Depth:        uint32(st.Depth()),
+			Visible:      uint32(st.Visible),
+			Delayed:      uint32(st.Delayed),
+			Leased:       uint32(st.Leased),
+			Bytes:        st.Bytes,
+			Quarantined:  uint32(st.Quarantined),
+			Backpressure: s.backpressure.applied(getQueueName(in.Tenant, queue)),
+		}
+		if !st.Oldest.IsZero() {
+			stats[i].OldestAge = durationpb.New(now.Sub(st.Oldest))
+		}
+	}
+	return &pb.StatsReply{Queues: stats}, nil
+}
+
+// Peek returns the next items of a queue in the order they are delivered, without leasing them.
+func (s *Controller) Peek(in *pb.PeekRequest) (*pb.PeekReply, error) {
+	myqueue, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	limit := provider.MaxItems
+	if in.Limit != nil {
+		limit = min(int(*in.Limit), provider.MaxItems)
+	}
+	ms, err := myqueue.Peek(limit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*pb.PeekedItem, len(ms))
+	for i, m := range ms {
+		items[i] = &pb.PeekedItem{
+			Item:     m.Data,
+			Attempts: m.Attempts,
+			Failures: deliveryFailures(m.Failures),
+		}
+	}
+	return &pb.PeekReply{Items: items}, nil
+}
+
+// Purge removes every item of a queue, including the delayed and leased ones. The request must
+// confirm the name of the queue.
+func (s *Controller) Purge(in *pb.PurgeRequest) (*pb.PurgeReply, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
+	if in.Confirm != in.Queue {
+		return nil, ErrPurgeNotConfirmed
+	}
+	myqueue, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	n, err := myqueue.Purge()
+	if err := s.applied(err, in.Tenant, in.Queue); err != nil {
+		return nil, err
+	}
+	// the leases of the purged items are gone, so the subscribers get their credits back.
+	s.subscriptions.reset(getQueueName(in.Tenant, in.Queue))
+	zerolog.Ctx(s.ctx).Info().Msgf("queue %s/%s: purged %d items", in.Tenant, in.Queue, n)
+	return &pb.PurgeReply{Purged: uint32(n)}, nil
+}
+
+func deliveryFailures(fs []provider.Failure) []*pb.DeliveryFailure {
+	failures := make([]*pb.DeliveryFailure, len(fs))
+	for i, f := range fs {
+		failures[i] = &pb.DeliveryFailure{
+			Reason: f.Reason,
+			Date:   timestamppb.New(f.Date),
+		}
+	}
+	return failures
+}
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+)
+
+// DefaultDrainInterval is the time between the checks for draining queues that are empty.
+const DefaultDrainInterval = 5 * time.Second
+
+const (
+	archiveDir = "archive"
+	auditFile  = "audit.log"
+)
+
+var ErrQueueDraining = errors.New("the queue is draining because its package was deleted")
+
+// removal is the audit record of a queue whose package was deleted.
+type removal struct {
+	Date   time.Time `json:"date"`
+	Tenant string    `json:"tenant"`
+	Queue  string    `json:"queue"`
+	Policy string    `json:"policy"`
+	// Items and Bytes describe what the queue held when it was removed.
+	Items   int    `json:"items"`
+	Bytes   int64  `json:"bytes"`
+	Archive string `json:"archive,omitempty"`
+}
+
+// wrapper is implemented by the decorators of the queues.
+type wrapper[T any] interface {
+	unwrap() provider.Queue[T]
+}
+
+func unwrap[T any](queue provider.Queue[T]) provider.Queue[T] {
+	for {
+		w, ok := queue.(wrapper[T])
+		if !ok {
+			return queue
+		}
+		queue = w.unwrap()
+	}
+}
+
+// Draining reports whether the package of the queue was deleted and the queue is waiting to be emptied.
+func (q *Cache[T]) Draining(tenant string, queueID string) bool {
+	_, ok := q.draining.Load(getQueueName(tenant, queueID))
+	return ok
+}
+
+// delete applies the lifecycle policy of the queues of a deleted package.
+func (q *Cache[T]) delete(ctx context.Context, tenant string, qs []*pb.QueueDef) {
+	q.lifecycle.Lock()
+	defer q.lifecycle.Unlock()
+	for _, def := range qs {
+		name := getQueueName(tenant, def.ID)
+		if stored, ok := q.defs.Load(name); ok {
+			def = stored
+		}
+		policy := def.GetOnDelete()
+		// the items of the queues with an unknown policy are kept in an archive.
+		if _, ok := pb.QueueDef_Lifecycle_name[int32(policy)]; !ok {
+			policy = pb.QueueDef_Archive
+		}
+		if policy == pb.QueueDef_Drain {
+			if _, ok := q.queues.Load(name); ok {
+				q.drain(ctx, name)
+				continue
+			}
+		}
+		q.remove(ctx, name, policy)
+	}
+}
+
+// drain removes the queue once it is empty, unless its package is deployed again before that.
+func (q *Cache[T]) drain(ctx context.Context, name string) {
+	if _, ok := q.draining.Load(name); ok {
+		return
+	}
+	q.draining.Store(name, struct{}{})
+	zerolog.Ctx(ctx).Info().Msgf("queue %s: draining", name)
+	q.done.Add(1)
+	go func() {
+		defer q.done.Done()
+		ticker := time.NewTicker(q.drainInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-q.ctx.Done():
+				return
+			case <-ticker.C:
+				if q.drained(ctx, name) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// drained removes the queue if it is empty. It returns true when the queue is not draining anymore.
+func (q *Cache[T]) drained(ctx context.Context, name string) bool {
+	q.lifecycle.Lock()
+	defer q.lifecycle.Unlock()
+	if _, ok := q.draining.Load(name); !ok {
+		return true
+	}
+	queue, ok := q.queues.Load(name)
+	if !ok {
+		q.draining.Delete(name)
+		return true
+	}
+	st, err := queue.Stats()
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().AnErr("error", err).Msgf("queue %s: error checking the draining queue", name)
+		return false
+	}
+	if st.Depth() > 0 {
+		return false
+	}
+	q.remove(ctx, name, pb.QueueDef_Drain)
+	return true
+}
+
+// remove drops the queue and deletes its storage, archiving it first if the policy says so. The storage is
+// kept if the archive cannot be written.
+func (q *Cache[T]) remove(ctx context.Context, name string, policy pb.QueueDef_Lifecycle) {
+	logger := zerolog.Ctx(ctx)
+	queue, ok := q.queues.Load(name)
+	q.queues.Delete(name)
+	q.defs.Delete(name)
+	q.draining.Delete(name)
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := closeQueue(queue); err != nil {
+			logger.Warn().AnErr("error", err).Msgf("queue %s: error closing", name)
+		}
+	}()
+	tenant, queueID := splitQueueName(name)
+	r := removal{
+		Date:   time.Now(),
+		Tenant: tenant,
+		Queue:  queueID,
+		Policy: policy.String(),
+	}
+	if st, err := queue.Stats(); err == nil {
+		r.Items = st.Depth()
+		r.Bytes = st.Bytes
+	}
+	if storage, ok := unwrap(queue).(provider.Storage); ok {
+		if policy == pb.QueueDef_Archive && q.dir != "" {
+			archive, err := q.archive(tenant, queueID, r.Date, storage)
+			if err != nil {
+				logger.Error().AnErr("error", err).Msgf("queue %s: error archiving, the storage is kept", name)
+				return
+			}
+			r.Archive = archive
+		}
+		if err := storage.Remove(); err != nil {
+			logger.Error().AnErr("error", err).Msgf("queue %s: error deleting the storage", name)
+			return
+		}
+	}
+	if err := q.audit(r); err != nil {
+		logger.Error().AnErr("error", err).Msgf("queue %s: error writing the audit record", name)
+	}
+	logger.Info().Msgf("queue %s: removed with policy %s, %d items, archive: %q", name, r.Policy, r.Items, r.Archive)
+}
+
+// archive writes the storage of the queue to [dir]/archive/[tenant]/[queue]-[date].tar.gz.
+func (q *Cache[T]) archive(tenant string, queueID string, date time.Time, storage provider.Storage) (string, error) {
+	directory := filepath.Join(q.dir, archiveDir, tenant)
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return "", err
+	}
+	filename := filepath.Join(directory, fmt.Sprintf("%s-%s.tar.gz", queueID, date.UTC().Format("20060102T150405.000000000")))
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	err = storage.Archive(tw)
+	err = errors.Join(err, tw.Close(), gz.Close(), file.Close())
+	if err != nil {
+		return "", errors.Join(err, os.Remove(filename))
+	}
+	return filename, nil
+}
+
+// audit appends the record to [dir]/audit.log, one JSON document per line.
+func (q *Cache[T]) audit(r removal) error {
+	if q.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(q.dir, 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(q.dir, auditFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(b, '\n'))
+	return errors.Join(err, file.Sync(), file.Close())
+}
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/andrescosta/goico/pkg/service"
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+)
+
+const migrationStateFile = "migration.json"
+
+var (
+	ErrMigrationSameStorage    = errors.New("the source and the target of the migration are the same")
+	ErrMigrationNotSupported   = errors.New("the items of the source cannot be exported")
+	ErrMigrationTargetNotEmpty = errors.New("the target queue has items that were not copied by a previous migration")
+	ErrMigrationCount          = errors.New("the number of items of the target does not match the source")
+)
+
+// MigrationOption configures the copy of the queues from a storage provider to another.
+type MigrationOption struct {
+	From Option
+	To   Option
+	// State is the file that records the queues whose copy was started, so a migration can be resumed.
+	// It is [To.Dir]/migration.json when empty.
+	State string
+}
+
+// Migrated describes the copy of a queue.
+type Migrated struct {
+	Tenant string
+	Queue  string
+	// Items is the number of items of the queue, which the target holds after the copy.
+	Items int
+	// Resumed is the number of items copied by a previous run.
+	Resumed int
+}
+
+type migrationState struct {
+	Started []string `json:"started"`
+}
+
+// Migrate copies the items of every queue declared by the packages from the storage of o.From to the
+// storage of o.To, in the order they were queued and with their delivery metadata. The queue service
+// must be stopped while it runs. The source is not modified, so the service can be switched to the
+// target once it succeeds.
+// A migration that was interrupted is resumed by running it again: the items of a queue already in the
+// target are not copied again. The number of items of every queue is verified after it is copied.
+func Migrate(ctx context.Context, d service.GrpcDialer, o MigrationOption) ([]Migrated, error) {
+	if storageOf(o.From) == storageOf(o.To) {
+		return nil, ErrMigrationSameStorage
+	}
+	statePath := o.State
+	if statePath == "" {
+		statePath = filepath.Join(o.To.Dir, migrationStateFile)
+	}
+	state, err := loadMigrationState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	from, err := newMigrationCache(ctx, d, o.From)
+	if err != nil {
+		return nil, err
+	}
+	defer from.Close()
+	to, err := newMigrationCache(ctx, d, o.To)
+	if err != nil {
+		return nil, err
+	}
+	defer to.Close()
+	names := make([]string, 0)
+	err = from.Range(ctx, func(tenant string, def *pb.QueueDef) bool {
+		names = append(names, getQueueName(tenant, def.ID))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+	ms := make([]Migrated, 0, len(names))
+	for _, name := range names {
+		m, err := migrateQueue(ctx, from, to, name, state, statePath)
+		if err != nil {
+			return ms, fmt.Errorf("queue %s: %w", name, err)
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// newMigrationCache returns a cache whose queues decrypt the items they export and encrypt the ones they
+// receive, each with the keys of its own storage.
+func newMigrationCache(ctx context.Context, d service.GrpcDialer, o Option) (*Cache[*pb.QueueItem], error) {
+	sealing, err := newSealing(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCache[*pb.QueueItem](ctx, d, o)
+	if err != nil {
+		return nil, err
+	}
+	c.wrap = sealing.wrap
+	return c, nil
+}
+
+func migrateQueue(ctx context.Context, from *Cache[*pb.QueueItem], to *Cache[*pb.QueueItem], name string, state *migrationState, statePath string) (Migrated, error) {
+	tenant, queueID := splitQueueName(name)
+	m := Migrated{Tenant: tenant, Queue: queueID}
+	source, err := from.GetQueue(ctx, tenant, queueID)
+	if err != nil {
+		return m, err
+	}
+	exporter, ok := source.(provider.Exporter[*pb.QueueItem])
+	if !ok {
+		return m, ErrMigrationNotSupported
+	}
+	target, err := to.GetQueue(ctx, tenant, queueID)
+	if err != nil {
+		return m, err
+	}
+	st, err := target.Stats()
+	if err != nil {
+		return m, err
+	}
+	// the items are copied in order, so the ones already in the target are the first ones of the source.
+	m.Resumed = st.Depth()
+	if !slices.Contains(state.Started, name) {
+		if m.Resumed > 0 {
+			return m, ErrMigrationTargetNotEmpty
+		}
+		state.Started = append(state.Started, name)
+		if err := saveMigrationState(statePath, state); err != nil {
+			return m, err
+		}
+	}
+	err = exporter.Export(func(message provider.Message[*pb.QueueItem]) error {
+		m.Items++
+		if m.Items <= m.Resumed {
+			return nil
+		}
+		return target.Add(message)
+	})
+	if err != nil {
+		return m, err
+	}
+	st, err = target.Stats()
+	if err != nil {
+		return m, err
+	}
+	if st.Depth() != m.Items {
+		return m, fmt.Errorf("%w: %d in the source, %d in the target", ErrMigrationCount, m.Items, st.Depth())
+	}
+	zerolog.Ctx(ctx).Info().Msgf("queue %s: %d items migrated, %d of them by a previous run", name, m.Items, m.Resumed)
+	return m, nil
+}
+
+// storageOf identifies where the queues of o are stored.
+func storageOf(o Option) string {
+	switch {
+	case o.Pebble:
+		return "pebble:" + o.Dir
+	case o.InMemory:
+		return "memory"
+	case o.Log:
+		return "log:" + o.Dir
+	default:
+		return "file:" + o.Dir
+	}
+}
+
+func loadMigrationState(path string) (*migrationState, error) {
+	state := &migrationState{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// saveMigrationState replaces the state file, so it is never left half written.
+func saveMigrationState(path string, state *migrationState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
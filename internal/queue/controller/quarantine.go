@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"errors"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrQuarantineNotSupported = errors.New("the queue does not quarantine items")
+
+// Quarantined returns the items of a queue that could not be decoded when they were dequeued.
+func (s *Controller) Quarantined(in *pb.QuarantinedRequest) (*pb.QuarantinedReply, error) {
+	quarantine, err := s.quarantine(in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	qs, err := quarantine.Quarantined()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*pb.QuarantinedItem, len(qs))
+	for i, q := range qs {
+		items[i] = &pb.QuarantinedItem{
+			ID:    q.ID,
+			Data:  q.Data,
+			Error: q.Error,
+			Date:  timestamppb.New(q.Date),
+		}
+	}
+	return &pb.QuarantinedReply{Items: items}, nil
+}
+
+// RequeueQuarantined queues a quarantined item again. If the request has the repaired item, it is queued
+// as a new one and the quarantined item is deleted.
+func (s *Controller) RequeueQuarantined(in *pb.RequeueQuarantinedRequest) (*pb.Void, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
+	quarantine, err := s.quarantine(in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	if in.Item != nil {
+		// the repaired item is queued first, so it is not lost if the deletion fails.
+		if _, err := s.Queue(&pb.QueueRequest{Tenant: in.Tenant, Queue: in.Queue, Items: []*pb.QueueItem{in.Item}}); err != nil {
+			return nil, err
+		}
+		if err := quarantine.DeleteQuarantined(in.ID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := quarantine.RequeueQuarantined(in.ID); err != nil {
+			return nil, err
+		}
+		s.subscriptions.notify(getQueueName(in.Tenant, in.Queue))
+	}
+	zerolog.Ctx(s.ctx).Info().Msgf("queue %s/%s: quarantined item %s requeued", in.Tenant, in.Queue, in.ID)
+	return &pb.Void{}, nil
+}
+
+func (s *Controller) DeleteQuarantined(in *pb.DeleteQuarantinedRequest) (*pb.Void, error) {
+	if err := s.checkPrimary(); err != nil {
+		return nil, err
+	}
+	quarantine, err := s.quarantine(in.Tenant, in.Queue)
+	if err != nil {
+		return nil, err
+	}
+	if err := quarantine.DeleteQuarantined(in.ID); err != nil {
+		return nil, err
+	}
+	zerolog.Ctx(s.ctx).Info().Msgf("queue %s/%s: quarantined item %s deleted", in.Tenant, in.Queue, in.ID)
+	return &pb.Void{}, nil
+}
+
+func (s *Controller) quarantine(tenant string, queue string) (provider.Quarantine, error) {
+	myqueue, err := s.cache.GetQueue(s.ctx, tenant, queue)
+	if err != nil {
+		return nil, err
+	}
+	// the quarantine is local to the node, the replicas keep the items as they were queued.
+	quarantine, ok := unwrap(myqueue).(provider.Quarantine)
+	if !ok {
+		return nil, ErrQuarantineNotSupported
+	}
+	return quarantine, nil
+}
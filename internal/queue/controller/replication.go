@@ -0,0 +1,902 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andrescosta/goico/pkg/service"
+	"github.com/andrescosta/jobico/internal/api/client"
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// DefaultHeartbeat is the time between the requests the primary sends to the replicas when there are no changes.
+	DefaultHeartbeat = 1 * time.Second
+	// DefaultSyncTimeout is the time a synchronous change waits for the replicas.
+	DefaultSyncTimeout = 5 * time.Second
+	// maxBacklog is the number of changes kept for a replica that is not reachable. The oldest are dropped after
+	// that, and the replica receives a copy of the queues once it is back.
+	maxBacklog = 100_000
+	// maxReplicationBatch is the number of changes sent to a replica at once.
+	maxReplicationBatch = 500
+	// holdVisibility hides the items of a replica, which are released only when it is promoted.
+	holdVisibility = 100 * 365 * 24 * time.Hour
+	// pendingAckTTL is the time an acknowledgement of an item not seen by a replica is kept.
+	pendingAckTTL = 10 * time.Minute
+)
+
+var (
+	ErrReplica           = errors.New("the node is a replica")
+	ErrNotReplica        = errors.New("the node is not a replica")
+	ErrReplicaOutOfSync  = errors.New("the replica is out of sync")
+	ErrReplicaSyncTimout = errors.New("timeout waiting for the replica")
+)
+
+// ReplicationOption configures the replication of the queues of a primary node to its replicas.
+type ReplicationOption struct {
+	// Replicas are the addresses of the nodes that receive the changes while this node is the primary.
+	Replicas []string
+	// Sync waits for the replicas to apply a change before replying to the client.
+	Sync bool
+	// Replica starts the node as a replica, which only accepts changes from the primary until it is promoted.
+	Replica bool
+	// FailoverTimeout promotes a replica that does not hear from the primary for that long. Zero disables it.
+	FailoverTimeout time.Duration
+	// Heartbeat is the time between the requests sent to the replicas when there are no changes.
+	Heartbeat time.Duration
+	// SyncTimeout is the time a synchronous change waits for the replicas.
+	SyncTimeout time.Duration
+}
+
+// replication sends the queued and acknowledged items of a primary node to its replicas, which keep every
+// item leased until they are promoted. The changes are kept in order in a backlog per replica, so a
+// replica that is down receives them once it is back. A replica that missed changes, because its backlog
+// was full or it was restarted, receives a copy of every queue instead.
+// Leases and nacks are not replicated, so the attempts and failures of an item are the ones it had when it
+// was queued: after a promotion they are counted again from there towards the maxAttempts of its queue.
+type replication struct {
+	node    string
+	stream  string
+	option  ReplicationOption
+	dialer  service.GrpcDialer
+	replica atomic.Bool
+	links   []*replicaLink
+	cancel  context.CancelFunc
+	done    sync.WaitGroup
+	// snap is held for writing while the queues are copied, so the copy includes every change numbered
+	// before it and none of the ones after.
+	snap sync.RWMutex
+
+	// the state of a replica, guarded by mu.
+	mu       sync.Mutex
+	applied  map[string]uint64
+	held     map[string]map[string]string
+	pending  map[string]map[string]time.Time
+	waiting  map[string]struct{}
+	loaded   bool
+	lastSeen time.Time
+	// resyncing is the stream whose copy is being received, and copied the number of entries received.
+	resyncing string
+	copied    uint64
+}
+
+// replicaLink is the backlog of changes of a replica.
+type replicaLink struct {
+	addr     string
+	mu       sync.Mutex
+	backlog  []*pb.ReplicationEntry
+	next     uint64
+	acked    uint64
+	lost     uint64
+	progress chan struct{}
+	wake     chan struct{}
+	failing  bool
+}
+
+func newReplication(d service.GrpcDialer, node string, o ReplicationOption) (*replication, error) {
+	stream, err := provider.NewID()
+	if err != nil {
+		return nil, err
+	}
+	if o.Heartbeat == 0 {
+		o.Heartbeat = DefaultHeartbeat
+	}
+	if o.SyncTimeout == 0 {
+		o.SyncTimeout = DefaultSyncTimeout
+	}
+	r := &replication{
+		node:    node,
+		stream:  stream,
+		option:  o,
+		dialer:  d,
+		applied: make(map[string]uint64),
+		held:    make(map[string]map[string]string),
+		pending: make(map[string]map[string]time.Time),
+		waiting: make(map[string]struct{}),
+	}
+	r.replica.Store(o.Replica)
+	for _, addr := range o.Replicas {
+		r.links = append(r.links, &replicaLink{
+			addr:     addr,
+			progress: make(chan struct{}),
+			wake:     make(chan struct{}, 1),
+		})
+	}
+	return r, nil
+}
+
+func (s *Controller) startReplication() {
+	r := s.replication
+	ctx, cancel := context.WithCancel(s.ctx)
+	r.cancel = cancel
+	for _, l := range r.links {
+		r.done.Add(1)
+		go func(l *replicaLink) {
+			defer r.done.Done()
+			s.send(ctx, l)
+		}(l)
+	}
+	if r.option.FailoverTimeout > 0 {
+		r.done.Add(1)
+		go func() {
+			defer r.done.Done()
+			s.watchPrimary(ctx)
+		}()
+	}
+}
+
+func (r *replication) stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.done.Wait()
+}
+
+// checkPrimary fails while the node is a replica, so clients do not change its queues.
+func (s *Controller) checkPrimary() error {
+	if s.replication != nil && s.replication.replica.Load() {
+		return ErrReplica
+	}
+	return nil
+}
+
+// replicate applies a change to a queue and adds the entries it returns to the backlog of every replica.
+// If the replication is synchronous, it waits for the replicas to apply them.
+func (r *replication) replicate(change func() ([]*pb.ReplicationEntry, error)) error {
+	seqs, err := r.apply(change)
+	if err != nil {
+		return err
+	}
+	return r.wait(seqs)
+}
+
+// apply applies a change to a queue and adds the entries it returns to the backlog of every replica. It
+// returns the number of the last entry in each backlog, or nil if there are none.
+func (r *replication) apply(change func() ([]*pb.ReplicationEntry, error)) ([]uint64, error) {
+	if r.replica.Load() {
+		_, err := change()
+		return nil, err
+	}
+	r.snap.RLock()
+	defer r.snap.RUnlock()
+	es, err := change()
+	if err != nil || len(es) == 0 {
+		return nil, err
+	}
+	seqs := make([]uint64, len(r.links))
+	for i, l := range r.links {
+		seqs[i] = l.append(es)
+	}
+	return seqs, nil
+}
+
+// wait waits for the replicas to apply the entries up to seqs, if the replication is synchronous.
+func (r *replication) wait(seqs []uint64) error {
+	if !r.option.Sync || seqs == nil {
+		return nil
+	}
+	var err error
+	for i, l := range r.links {
+		err = errors.Join(err, l.wait(seqs[i], r.option.SyncTimeout))
+	}
+	return err
+}
+
+// append adds the entries to the backlog and returns the number of the last one.
+func (l *replicaLink) append(es []*pb.ReplicationEntry) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range es {
+		if len(l.backlog) >= maxBacklog {
+			l.backlog = l.backlog[1:]
+			l.lost = l.next - uint64(len(l.backlog))
+		}
+		l.backlog = append(l.backlog, e)
+		l.next++
+	}
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+	return l.next
+}
+
+// unreplicated reports whether the change was applied by the node but not confirmed by its replicas.
+func unreplicated(err error) bool {
+	return errors.Is(err, ErrReplicaSyncTimout) || errors.Is(err, ErrReplicaOutOfSync)
+}
+
+// applied returns nil if the change was applied by the node but not confirmed by its replicas, which is
+// logged: the change is done, so it must not be undone or applied again.
+func (s *Controller) applied(err error, tenant string, queue string) error {
+	if !unreplicated(err) {
+		return err
+	}
+	zerolog.Ctx(s.ctx).Warn().AnErr("error", err).Msgf("queue %s/%s: change applied but not confirmed by the replicas", tenant, queue)
+	return nil
+}
+
+func (l *replicaLink) wait(seq uint64, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		l.mu.Lock()
+		acked, lost, progress := l.acked, l.lost, l.progress
+		l.mu.Unlock()
+		// a change dropped from the backlog is applied with the copy of the queues.
+		if seq <= acked {
+			return nil
+		}
+		if seq <= lost {
+			return fmt.Errorf("%w: %s", ErrReplicaOutOfSync, l.addr)
+		}
+		select {
+		case <-progress:
+		case <-timer.C:
+			return fmt.Errorf("%w: %s", ErrReplicaSyncTimout, l.addr)
+		}
+	}
+}
+
+// batch returns the number of the first change of the backlog and up to n changes.
+func (l *replicaLink) batch(n int) (uint64, []*pb.ReplicationEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := l.backlog[:min(n, len(l.backlog))]
+	return l.next - uint64(len(l.backlog)) + 1, entries
+}
+
+func (l *replicaLink) ack(n int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// the backlog may have dropped some of the changes sent, if it was full.
+	n = min(n, len(l.backlog))
+	l.backlog = l.backlog[n:]
+	l.acked = l.next - uint64(len(l.backlog))
+	close(l.progress)
+	l.progress = make(chan struct{})
+	return len(l.backlog) > 0
+}
+
+// reset empties the backlog when the queues are copied, and returns the number of the last change.
+func (l *replicaLink) reset() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.backlog = nil
+	return l.next
+}
+
+// confirm records that the replica applied the changes up to seq.
+func (l *replicaLink) confirm(seq uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.acked = max(l.acked, seq)
+	close(l.progress)
+	l.progress = make(chan struct{})
+}
+
+// send pushes the backlog of a replica as it grows, and a heartbeat when there are no changes. A replica that
+// missed some of the changes receives a copy of the queues instead.
+func (s *Controller) send(ctx context.Context, l *replicaLink) {
+	r := s.replication
+	logger := zerolog.Ctx(ctx)
+	ticker := time.NewTicker(r.option.Heartbeat)
+	defer ticker.Stop()
+	var peer *client.Queue
+	defer func() {
+		if peer != nil {
+			_ = peer.Close()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-l.wake:
+		case <-ticker.C:
+		}
+		if r.replica.Load() {
+			continue
+		}
+		for more := true; more && ctx.Err() == nil; {
+			var err error
+			if peer == nil {
+				peer, err = client.NewQueueForNode(ctx, r.dialer, l.addr)
+			}
+			first, entries := l.batch(maxReplicationBatch)
+			if err == nil {
+				err = peer.Replicate(ctx, &pb.ReplicateRequest{
+					Primary: r.node,
+					Stream:  r.stream,
+					First:   first,
+					Entries: entries,
+				})
+			}
+			if status.Code(err) == codes.FailedPrecondition {
+				logger.Warn().Msgf("replica %s missed some changes, sending a copy of the queues", l.addr)
+				err = s.resync(ctx, l, peer)
+				entries = nil
+			}
+			if err != nil {
+				if !l.failing && ctx.Err() == nil {
+					logger.Warn().AnErr("error", err).Msgf("replica %s is not reachable", l.addr)
+				}
+				l.failing = true
+				// the replica is dialed again, in case it was restarted.
+				if peer != nil {
+					_ = peer.Close()
+					peer = nil
+				}
+				break
+			}
+			if l.failing {
+				logger.Info().Msgf("replica %s is reachable again", l.addr)
+				l.failing = false
+			}
+			more = l.ack(len(entries))
+		}
+	}
+}
+
+// resync sends a copy of every queue to a replica. The copy replaces the items of the replica and
+// includes the changes of the backlog, which is emptied.
+func (s *Controller) resync(ctx context.Context, l *replicaLink, peer *client.Queue) error {
+	r := s.replication
+	r.snap.Lock()
+	es, err := s.copyQueues()
+	if err != nil {
+		r.snap.Unlock()
+		return err
+	}
+	covered := l.reset()
+	r.snap.Unlock()
+	for first := 0; first == 0 || first < len(es); first += maxReplicationBatch {
+		last := min(first+maxReplicationBatch, len(es))
+		err := peer.Replicate(ctx, &pb.ReplicateRequest{
+			Primary: r.node,
+			Stream:  r.stream,
+			First:   uint64(first) + 1,
+			Entries: es[first:last],
+			Resync:  &pb.Resync{Covered: covered, Last: last == len(es)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	l.confirm(covered)
+	zerolog.Ctx(ctx).Info().Msgf("replica %s received a copy of %d items", l.addr, len(es))
+	return nil
+}
+
+// copyQueues returns an entry that adds every item of the queues of the node.
+func (s *Controller) copyQueues() ([]*pb.ReplicationEntry, error) {
+	names, err := s.queueNames()
+	if err != nil {
+		return nil, err
+	}
+	es := make([]*pb.ReplicationEntry, 0)
+	for _, name := range names {
+		tenant, queue := splitQueueName(name)
+		myqueue, err := s.cache.GetQueue(s.ctx, tenant, queue)
+		if err != nil {
+			return nil, err
+		}
+		exporter, ok := myqueue.(provider.Exporter[*pb.QueueItem])
+		if !ok {
+			return nil, fmt.Errorf("%w: queue %s", ErrMigrationNotSupported, name)
+		}
+		err = exporter.Export(func(m provider.Message[*pb.QueueItem]) error {
+			es = append(es, addEntry(tenant, queue, m))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return es, nil
+}
+
+// queueNames returns the names of the queues of the node.
+func (s *Controller) queueNames() ([]string, error) {
+	names := make([]string, 0)
+	err := s.cache.Range(s.ctx, func(tenant string, def *pb.QueueDef) bool {
+		names = append(names, getQueueName(tenant, def.ID))
+		return true
+	})
+	return names, err
+}
+
+// Replicate applies the changes sent by the primary.
+func (s *Controller) Replicate(in *pb.ReplicateRequest) (*pb.Void, error) {
+	r := s.replication
+	if r == nil || !r.replica.Load() {
+		return nil, ErrNotReplica
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastSeen = time.Now()
+	if !r.loaded {
+		// the leases do not survive a restart, so every item is held again.
+		if err := s.cache.Range(s.ctx, func(tenant string, def *pb.QueueDef) bool {
+			r.waiting[getQueueName(tenant, def.ID)] = struct{}{}
+			return true
+		}); err != nil {
+			return nil, err
+		}
+		r.loaded = true
+	}
+	if in.Resync != nil {
+		return s.applyCopy(in)
+	}
+	// the changes of an unknown stream may have been missed, as the ones after a gap.
+	applied, ok := r.applied[in.Stream]
+	if !ok || in.First > applied+1 {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s: expected change %d of %s, got %d", ErrReplicaOutOfSync, applied+1, in.Stream, in.First)
+	}
+	for i, e := range in.Entries {
+		seq := in.First + uint64(i)
+		if seq <= applied {
+			continue
+		}
+		if err := s.apply(e); err != nil {
+			return nil, err
+		}
+		r.applied[in.Stream] = seq
+	}
+	return s.holdWaiting()
+}
+
+// applyCopy replaces the items of the replica with the copy of the queues of the primary. The copy is
+// received in order, and it is started again if a request is missed.
+func (s *Controller) applyCopy(in *pb.ReplicateRequest) (*pb.Void, error) {
+	r := s.replication
+	if in.First == 1 {
+		names, err := s.queueNames()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			tenant, queue := splitQueueName(name)
+			myqueue, err := s.cache.GetQueue(s.ctx, tenant, queue)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := myqueue.Purge(); err != nil {
+				return nil, err
+			}
+		}
+		r.held = make(map[string]map[string]string)
+		r.pending = make(map[string]map[string]time.Time)
+		r.waiting = make(map[string]struct{})
+		r.resyncing = in.Stream
+		r.copied = 0
+		delete(r.applied, in.Stream)
+	}
+	if r.resyncing != in.Stream || in.First != r.copied+1 {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s: expected entry %d of the copy, got %d", ErrReplicaOutOfSync, r.copied+1, in.First)
+	}
+	for _, e := range in.Entries {
+		if err := s.apply(e); err != nil {
+			return nil, err
+		}
+		r.copied++
+	}
+	if in.Resync.Last {
+		r.applied[in.Stream] = in.Resync.Covered
+		r.resyncing = ""
+		zerolog.Ctx(s.ctx).Info().Msgf("the replica received a copy of %d items from %s", r.copied, in.Primary)
+	}
+	return s.holdWaiting()
+}
+
+// holdWaiting holds the items of the queues that received changes.
+func (s *Controller) holdWaiting() (*pb.Void, error) {
+	r := s.replication
+	r.prunePending()
+	for name := range r.waiting {
+		if err := s.hold(name); err != nil {
+			return nil, err
+		}
+	}
+	return &pb.Void{}, nil
+}
+
+func (s *Controller) apply(e *pb.ReplicationEntry) error {
+	r := s.replication
+	name := getQueueName(e.Tenant, e.Queue)
+	myqueue, err := s.cache.GetQueue(s.ctx, e.Tenant, e.Queue)
+	if err != nil {
+		return err
+	}
+	switch e.Operation {
+	case pb.ReplicationEntry_Add:
+		m := provider.Message[*pb.QueueItem]{
+			Data:         e.Item,
+			Priority:     e.Item.Priority,
+			Attempts:     e.Attempts,
+			Failures:     failures(e.Failures),
+			Source:       e.Source,
+			PartitionKey: e.Item.GetPartitionKey(),
+		}
+		if e.Item.NotBefore != nil {
+			m.NotBefore = e.Item.NotBefore.AsTime()
+		}
+		if err := myqueue.Add(m); err != nil {
+			return err
+		}
+		r.waiting[name] = struct{}{}
+	case pb.ReplicationEntry_Ack:
+		if receipt, ok := r.held[name][e.ItemID]; ok {
+			delete(r.held[name], e.ItemID)
+			if err := myqueue.Ack(receipt); err != nil && !errors.Is(err, provider.ErrReceiptUnknown) {
+				return err
+			}
+			return nil
+		}
+		// the item is acknowledged once it is held.
+		if r.pending[name] == nil {
+			r.pending[name] = make(map[string]time.Time)
+		}
+		r.pending[name][e.ItemID] = time.Now()
+	case pb.ReplicationEntry_Purge:
+		if _, err := myqueue.Purge(); err != nil {
+			return err
+		}
+		delete(r.held, name)
+		delete(r.pending, name)
+		delete(r.waiting, name)
+	}
+	return nil
+}
+
+// hold leases the visible items of a queue of a replica, so they can be acknowledged by their ID. The queue
+// is checked again with the next changes while it has delayed items, or items of a blocked partition key.
+func (s *Controller) hold(name string) error {
+	r := s.replication
+	tenant, queue := splitQueueName(name)
+	myqueue, err := s.cache.GetQueue(s.ctx, tenant, queue)
+	if errors.Is(err, ErrQueueUnknown) {
+		delete(r.waiting, name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for {
+		ls, err := myqueue.Lease(provider.MaxItems, holdVisibility)
+		if err != nil && !errors.Is(err, provider.ErrQueueEmpty) {
+			return err
+		}
+		if len(ls) == 0 {
+			break
+		}
+		for _, l := range ls {
+			id := l.Data.ID
+			if _, ok := r.pending[name][id]; ok {
+				delete(r.pending[name], id)
+				if err := myqueue.Ack(l.Receipt); err != nil {
+					return err
+				}
+				continue
+			}
+			if r.held[name] == nil {
+				r.held[name] = make(map[string]string)
+			}
+			r.held[name][id] = l.Receipt
+		}
+	}
+	st, err := myqueue.Stats()
+	if err != nil {
+		return err
+	}
+	if st.Visible+st.Delayed > 0 {
+		r.waiting[name] = struct{}{}
+	} else {
+		delete(r.waiting, name)
+	}
+	return nil
+}
+
+func (r *replication) prunePending() {
+	now := time.Now()
+	for name, ids := range r.pending {
+		for id, since := range ids {
+			if now.Sub(since) > pendingAckTTL {
+				delete(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			delete(r.pending, name)
+		}
+	}
+}
+
+// Promote turns a replica into the primary. The items it holds become visible, and the changes are sent
+// to its own replicas from then on.
+func (s *Controller) Promote() (*pb.Void, error) {
+	r := s.replication
+	if r == nil || !r.replica.Load() {
+		return nil, ErrNotReplica
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs error
+	for name, items := range r.held {
+		tenant, queue := splitQueueName(name)
+		myqueue, err := s.cache.GetQueue(s.ctx, tenant, queue)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		for _, receipt := range items {
+			if err := myqueue.Nack(receipt, "", 0); err != nil && !errors.Is(err, provider.ErrReceiptUnknown) {
+				errs = errors.Join(errs, err)
+			}
+		}
+	}
+	r.held = make(map[string]map[string]string)
+	r.pending = make(map[string]map[string]time.Time)
+	r.waiting = make(map[string]struct{})
+	r.replica.Store(false)
+	for _, l := range r.links {
+		select {
+		case l.wake <- struct{}{}:
+		default:
+		}
+	}
+	zerolog.Ctx(s.ctx).Info().Msg("the replica was promoted to primary")
+	if errs != nil {
+		return nil, errs
+	}
+	return &pb.Void{}, nil
+}
+
+// watchPrimary promotes the replica when the primary is silent for longer than the failover timeout.
+// It waits to hear from the primary once, so a replica is not promoted while the primary is starting.
+func (s *Controller) watchPrimary(ctx context.Context) {
+	r := s.replication
+	logger := zerolog.Ctx(ctx)
+	ticker := time.NewTicker(max(r.option.FailoverTimeout/4, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.mu.Lock()
+		silent := r.replica.Load() && !r.lastSeen.IsZero() && time.Since(r.lastSeen) > r.option.FailoverTimeout
+		r.mu.Unlock()
+		if !silent {
+			continue
+		}
+		logger.Warn().Msgf("no changes from the primary for %s, promoting the replica", r.option.FailoverTimeout)
+		if _, err := s.Promote(); err != nil && !errors.Is(err, ErrNotReplica) {
+			logger.Warn().AnErr("error", err).Msg("error promoting the replica")
+		}
+	}
+}
+
+// replicatedQueue sends the changes of a queue to the replicas while the node is the primary.
+type replicatedQueue struct {
+	provider.Queue[*pb.QueueItem]
+	tenant      string
+	queue       string
+	replication *replication
+	mu          sync.Mutex
+	// leased maps the receipts to the IDs of the items, because the replicas acknowledge them by ID.
+	leased map[string]leasedID
+}
+
+type leasedID struct {
+	id    string
+	until time.Time
+}
+
+func newReplicatedQueue(name string, q provider.Queue[*pb.QueueItem], r *replication) *replicatedQueue {
+	tenant, queue := splitQueueName(name)
+	return &replicatedQueue{
+		Queue:       q,
+		tenant:      tenant,
+		queue:       queue,
+		replication: r,
+		leased:      make(map[string]leasedID),
+	}
+}
+
+func (q *replicatedQueue) Add(m provider.Message[*pb.QueueItem]) error {
+	return q.AddAll([]provider.Message[*pb.QueueItem]{m})
+}
+
+// AddAll replicates the items once all of them are added.
+func (q *replicatedQueue) AddAll(ms []provider.Message[*pb.QueueItem]) error {
+	return q.replication.replicate(func() ([]*pb.ReplicationEntry, error) {
+		if err := q.Queue.AddAll(ms); err != nil {
+			return nil, err
+		}
+		es := make([]*pb.ReplicationEntry, len(ms))
+		for i, m := range ms {
+			es[i] = addEntry(q.tenant, q.queue, m)
+		}
+		return es, nil
+	})
+}
+
+// addEntry returns the entry that adds the item to the queue of a replica.
+func addEntry(tenant string, queue string, m provider.Message[*pb.QueueItem]) *pb.ReplicationEntry {
+	item := proto.Clone(m.Data).(*pb.QueueItem)
+	item.Priority = m.Priority
+	item.NotBefore = nil
+	if !m.NotBefore.IsZero() {
+		item.NotBefore = timestamppb.New(m.NotBefore)
+	}
+	item.PartitionKey = nil
+	if m.PartitionKey != "" {
+		item.PartitionKey = &m.PartitionKey
+	}
+	return &pb.ReplicationEntry{
+		Operation: pb.ReplicationEntry_Add,
+		Tenant:    tenant,
+		Queue:     queue,
+		Item:      item,
+		Attempts:  m.Attempts,
+		Failures:  deliveryFailures(m.Failures),
+		Source:    m.Source,
+	}
+}
+
+func (q *replicatedQueue) Lease(n int, visibility time.Duration) ([]provider.Leased[*pb.QueueItem], error) {
+	ls, err := q.Queue.Lease(n, visibility)
+	if err != nil || q.replication.replica.Load() {
+		return ls, err
+	}
+	now := time.Now()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for receipt, l := range q.leased {
+		if now.After(l.until) {
+			delete(q.leased, receipt)
+		}
+	}
+	for _, l := range ls {
+		q.leased[l.Receipt] = leasedID{id: l.Data.ID, until: l.VisibleAt}
+	}
+	return ls, nil
+}
+
+func (q *replicatedQueue) Ack(receipt string) error {
+	return q.replication.replicate(func() ([]*pb.ReplicationEntry, error) {
+		e, err := q.ack(receipt)
+		if err != nil || e == nil {
+			return nil, err
+		}
+		return []*pb.ReplicationEntry{e}, nil
+	})
+}
+
+// ackAll acknowledges the receipts and waits for the replicas once for all of them. It returns the error
+// of each receipt and the error of the replicas.
+func (q *replicatedQueue) ackAll(receipts []string) ([]error, error) {
+	errs := make([]error, len(receipts))
+	seqs, err := q.replication.apply(func() ([]*pb.ReplicationEntry, error) {
+		es := make([]*pb.ReplicationEntry, 0, len(receipts))
+		for i, receipt := range receipts {
+			e, err := q.ack(receipt)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			if e != nil {
+				es = append(es, e)
+			}
+		}
+		return es, nil
+	})
+	if err != nil {
+		return errs, err
+	}
+	return errs, q.replication.wait(seqs)
+}
+
+// ack acknowledges the receipt and returns the entry that acknowledges the item in the replicas.
+func (q *replicatedQueue) ack(receipt string) (*pb.ReplicationEntry, error) {
+	if err := q.Queue.Ack(receipt); err != nil {
+		return nil, err
+	}
+	q.mu.Lock()
+	l, ok := q.leased[receipt]
+	delete(q.leased, receipt)
+	q.mu.Unlock()
+	if !ok || l.id == "" {
+		return nil, nil
+	}
+	return &pb.ReplicationEntry{
+		Operation: pb.ReplicationEntry_Ack,
+		Tenant:    q.tenant,
+		Queue:     q.queue,
+		ItemID:    l.id,
+	}, nil
+}
+
+func (q *replicatedQueue) Nack(receipt string, reason string, delay time.Duration) error {
+	q.mu.Lock()
+	delete(q.leased, receipt)
+	q.mu.Unlock()
+	return q.Queue.Nack(receipt, reason, delay)
+}
+
+func (q *replicatedQueue) Purge() (int, error) {
+	var n int
+	err := q.replication.replicate(func() ([]*pb.ReplicationEntry, error) {
+		var err error
+		if n, err = q.Queue.Purge(); err != nil {
+			return nil, err
+		}
+		q.mu.Lock()
+		q.leased = make(map[string]leasedID)
+		q.mu.Unlock()
+		return []*pb.ReplicationEntry{{
+			Operation: pb.ReplicationEntry_Purge,
+			Tenant:    q.tenant,
+			Queue:     q.queue,
+		}}, nil
+	})
+	return n, err
+}
+
+func (q *replicatedQueue) Export(fn func(provider.Message[*pb.QueueItem]) error) error {
+	exporter, ok := q.Queue.(provider.Exporter[*pb.QueueItem])
+	if !ok {
+		return ErrMigrationNotSupported
+	}
+	return exporter.Export(fn)
+}
+
+func (q *replicatedQueue) Close() error {
+	return closeQueue(q.Queue)
+}
+
+func (q *replicatedQueue) unwrap() provider.Queue[*pb.QueueItem] {
+	return q.Queue
+}
+
+func failures(fs []*pb.DeliveryFailure) []provider.Failure {
+	failures := make([]provider.Failure, len(fs))
+	for i, f := range fs {
+		failures[i] = provider.Failure{
+			Reason: f.Reason,
+			Date:   f.Date.AsTime(),
+		}
+	}
+	return failures
+}
@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/andrescosta/goico/pkg/collection"
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultExpirationInterval is the time between the checks for expired items.
+const DefaultExpirationInterval = 30 * time.Second
+
+const (
+	reasonMaxLength = "maxLength"
+	reasonMaxBytes  = "maxBytes"
+	reasonExpired   = "expired"
+)
+
+var ErrQueueFull = errors.New("the queue is full")
+
+// retention removes the items that exceed the limits of their queue or whose time to live elapsed.
+type retention struct {
+	interval time.Duration
+	// locks serialize the check of the limits of a queue with the addition of its new items.
+	locks     *collection.SyncMap[string, *sync.Mutex]
+	evictions metric.Int64Counter
+	cancel    context.CancelFunc
+	done      sync.WaitGroup
+}
+
+func newRetention(interval time.Duration) (*retention, error) {
+	if interval == 0 {
+		interval = DefaultExpirationInterval
+	}
+	evictions, err := otel.Meter("github.com/andrescosta/jobico/queue").Int64Counter("queue.evictions",
+		metric.WithDescription("Items removed from a queue because they exceeded its limits or expired."),
+		metric.WithUnit("{item}"))
+	if err != nil {
+		return nil, err
+	}
+	return &retention{
+		interval:  interval,
+		locks:     collection.NewSyncMap[string, *sync.Mutex](),
+		evictions: evictions,
+	}, nil
+}
+
+// lock locks the limits of a queue and returns the function that unlocks them.
+func (r *retention) lock(name string) func() {
+	mu := r.locks.LoadOrStore(name, &sync.Mutex{})
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (r *retention) stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.done.Wait()
+}
+
+func (s *Controller) startExpiring() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.retention.cancel = cancel
+	s.retention.done.Add(1)
+	go func() {
+		defer s.retention.done.Done()
+		ticker := time.NewTicker(s.retention.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.expireAll(ctx)
+			}
+		}
+	}()
+}
+
+// limited reports whether the queue has a limit on its length or size.
+func limited(def *pb.QueueDef) bool {
+	return def.GetMaxLength() > 0 || def.GetMaxBytes() > 0
+}
+
+// makeRoom checks that the items fit in the queue. If they do not and the queue drops the oldest items,
+// the next items to be delivered are removed until they fit. The size of the items is estimated, because
+// the providers store them in their own format, and the items removed to free bytes are assumed to be of
+// the average size of the queue.
+func (s *Controller) makeRoom(tenant string, queue string, q provider.Queue[*pb.QueueItem], def *pb.QueueDef, items []*pb.QueueItem) error {
+	length := len(items)
+	size := int64(0)
+	for _, i := range items {
+		size += int64(proto.Size(i))
+	}
+	maxLength, maxBytes := int(def.GetMaxLength()), int64(def.GetMaxBytes())
+	if (maxLength > 0 && length > maxLength) || (maxBytes > 0 && size > maxBytes) {
+		return ErrQueueFull
+	}
+	// the stats walk the items of some providers, so they are read once.
+	st, err := q.Stats()
+	if err != nil {
+		return err
+	}
+	overLength := maxLength > 0 && st.Depth()+length > maxLength
+	overBytes := maxBytes > 0 && st.Bytes+size > maxBytes
+	if !overLength && !overBytes {
+		return nil
+	}
+	if def.GetOverflow() != pb.QueueDef_DropOldest || st.Depth() == 0 {
+		return ErrQueueFull
+	}
+	n, reason := 0, reasonMaxLength
+	if overLength {
+		n = st.Depth() + length - maxLength
+	}
+	if overBytes {
+		average := max(st.Bytes/int64(st.Depth()), 1)
+		if m := int((st.Bytes + size - maxBytes + average - 1) / average); m > n {
+			n, reason = m, reasonMaxBytes
+		}
+	}
+	dropped := 0
+	defer func() {
+		if dropped > 0 {
+			zerolog.Ctx(s.ctx).Warn().Msgf("queue %s/%s: %d items dropped to make room", tenant, queue, dropped)
+		}
+	}()
+	for dropped < n {
+		ls, err := q.Lease(n-dropped, requeueVisibility)
+		if errors.Is(err, provider.ErrQueueEmpty) || (err == nil && len(ls) == 0) {
+			// the rest of the items are delayed or being processed.
+			return ErrQueueFull
+		}
+		if err != nil {
+			return err
+		}
+		for _, l := range ls {
+			if err := s.applied(q.Ack(l.Receipt), tenant, queue); err != nil {
+				return err
+			}
+			s.evicted(tenant, queue, reason, false)
+			dropped++
+		}
+	}
+	return nil
+}
+
+// expire removes the leased items whose time to live elapsed, moving them to the dead-letter queue if the
+// queue says so, and returns the rest.
+func (s *Controller) expire(tenant string, queue string, q provider.Queue[*pb.QueueItem], ls []provider.Leased[*pb.QueueItem]) []provider.Leased[*pb.QueueItem] {
+	logger := zerolog.Ctx(s.ctx)
+	def, err := s.cache.GetQueueDef(s.ctx, tenant, queue)
+	if err != nil || def.GetMessageTTLSeconds() == 0 {
+		return ls
+	}
+	ttl := time.Duration(def.GetMessageTTLSeconds()) * time.Second
+	now := time.Now()
+	deliver := make([]provider.Leased[*pb.QueueItem], 0, len(ls))
+	for _, l := range ls {
+		if !expired(l.Data, ttl, now) {
+			deliver = append(deliver, l)
+			continue
+		}
+		deadLettered := def.GetDeadLetterExpired() && def.DeadLetterQueue != nil
+		if deadLettered {
+			failures := append(l.Failures, provider.Failure{Reason: reasonExpired, Date: now})
+			if err := s.applied(s.moveToDeadLetter(tenant, queue, *def.DeadLetterQueue, l, failures), tenant, *def.DeadLetterQueue); err != nil {
+				// the item is delivered instead of being lost.
+				logger.Warn().AnErr("error", err).Msgf("queue %s/%s: error moving expired item to the dead-letter queue %s", tenant, queue, *def.DeadLetterQueue)
+				deliver = append(deliver, l)
+				continue
+			}
+		}
+		if err := s.applied(q.Ack(l.Receipt), tenant, queue); err != nil {
+			logger.Warn().AnErr("error", err).Msgf("queue %s/%s: error removing expired item", tenant, queue)
+			continue
+		}
+		s.evicted(tenant, queue, reasonExpired, deadLettered)
+	}
+	return deliver
+}
+
+// expireAll removes the expired items of the queues that are not consumed.
+func (s *Controller) expireAll(ctx context.Context) {
+	// the items of a replica are removed by its primary.
+	if s.checkPrimary() != nil {
+		return
+	}
+	logger := zerolog.Ctx(ctx)
+	queues := make(map[string]time.Duration)
+	err := s.cache.Range(ctx, func(tenant string, def *pb.QueueDef) bool {
+		if def.GetMessageTTLSeconds() > 0 {
+			queues[getQueueName(tenant, def.ID)] = time.Duration(def.GetMessageTTLSeconds()) * time.Second
+		}
+		return true
+	})
+	if err != nil {
+		logger.Warn().AnErr("error", err).Msg("error listing the queues to expire")
+		return
+	}
+	for name, ttl := range queues {
+		if ctx.Err() != nil {
+			return
+		}
+		tenant, queue := splitQueueName(name)
+		if err := s.sweep(tenant, queue, ttl); err != nil {
+			logger.Warn().AnErr("error", err).Msgf("queue %s: error removing expired items", name)
+		}
+	}
+}
+
+// sweep removes the expired items at the head of a queue. Items wait in the order they were queued, so
+// it stops at the first one that did not expire. The items behind it expire when they are leased.
+func (s *Controller) sweep(tenant string, queue string, ttl time.Duration) error {
+	q, err := s.cache.GetQueue(s.ctx, tenant, queue)
+	if err != nil {
+		return err
+	}
+	for {
+		ms, err := q.Peek(provider.MaxItems)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		n := 0
+		for n < len(ms) && expired(ms[n].Data, ttl, now) {
+			n++
+		}
+		if n == 0 {
+			return nil
+		}
+		ls, err := q.Lease(n, requeueVisibility)
+		if errors.Is(err, provider.ErrQueueEmpty) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		rest := s.expire(tenant, queue, q, ls)
+		// the items that did not expire are released without counting the attempt.
+		for _, l := range rest {
+			err = errors.Join(err, q.Nack(l.Receipt, "", 0))
+		}
+		if err != nil || len(rest) == len(ls) {
+			return err
+		}
+	}
+}
+
+func (s *Controller) evicted(tenant string, queue string, reason string, deadLettered bool) {
+	s.retention.evictions.Add(s.ctx, 1, metric.WithAttributes(
+		attribute.String("tenant", tenant),
+		attribute.String("queue", queue),
+		attribute.String("reason", reason),
+		attribute.Bool("deadLettered", deadLettered),
+	))
+}
+
+func expired(item *pb.QueueItem, ttl time.Duration, now time.Time) bool {
+	return item.QueuedAt != nil && now.Sub(item.QueuedAt.AsTime()) > ttl
+}
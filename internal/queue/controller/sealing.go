@@ -0,0 +1,421 @@
+package controller
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/proto"
+)
+
+const keyringFile = "keys.json"
+
+var (
+	ErrMasterKeyMismatch = errors.New("the data keys were wrapped by another master key")
+	ErrEncryptionKey     = errors.New("the item is encrypted but no master key is configured")
+	ErrDataKeyUnknown    = errors.New("the data key of the tenant is unknown")
+)
+
+// EncryptionOption configures the encryption of the data of the items at rest. Every tenant has its own
+// data key, which is stored wrapped by the master key in [Dir]/keys.json.
+type EncryptionOption struct {
+	// MasterKey is an AES key of 16, 24 or 32 bytes. The items are not encrypted if it is empty.
+	MasterKey []byte
+	// PreviousKey is the master key before a rotation. The data keys wrapped by it are wrapped again by
+	// MasterKey when the queues are opened. The data of the items is not rewritten.
+	PreviousKey []byte
+}
+
+// the encoder and the decoder are only used to compress whole buffers, which is safe to do concurrently.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// sealing compresses and encrypts the data of the items before the providers store it, and reverses it
+// when they return the items.
+type sealing struct {
+	compress bool
+	keys     *keyring
+}
+
+func newSealing(ctx context.Context, o Option) (*sealing, error) {
+	s := &sealing{compress: o.Compression}
+	if len(o.Encryption.MasterKey) > 0 {
+		path := ""
+		if !o.InMemory {
+			path = filepath.Join(o.Dir, keyringFile)
+		}
+		keys, err := openKeyring(ctx, path, o.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = keys
+	}
+	return s, nil
+}
+
+func (s *sealing) wrap(name string, q provider.Queue[*pb.QueueItem]) provider.Queue[*pb.QueueItem] {
+	tenant, _ := splitQueueName(name)
+	return &sealedQueue{Queue: q, tenant: tenant, sealing: s}
+}
+
+func (s *sealing) seal(tenant string, item *pb.QueueItem) (*pb.QueueItem, error) {
+	if !s.compress && s.keys == nil {
+		return item, nil
+	}
+	// the item is shared with the caller, which may replicate it.
+	item = proto.Clone(item).(*pb.QueueItem)
+	if s.compress {
+		if data := zstdEncoder.EncodeAll(item.Data, nil); len(data) < len(item.Data) {
+			item.Data = data
+			item.Compressed = true
+		}
+	}
+	if s.keys != nil {
+		aead, err := s.keys.dataKey(tenant)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(item.Data)+aead.Overhead())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		item.Data = aead.Seal(nonce, nonce, item.Data, additionalData(tenant, item))
+		item.Encrypted = true
+	}
+	return item, nil
+}
+
+func (s *sealing) open(tenant string, item *pb.QueueItem) (*pb.QueueItem, error) {
+	if !item.Compressed && !item.Encrypted {
+		return item, nil
+	}
+	item = proto.Clone(item).(*pb.QueueItem)
+	if item.Encrypted {
+		if s.keys == nil {
+			return nil, ErrEncryptionKey
+		}
+		aead, ok := s.keys.existing(tenant)
+		if !ok {
+			return nil, fmt.Errorf("item %s: %w", item.ID, ErrDataKeyUnknown)
+		}
+		if len(item.Data) < aead.NonceSize() {
+			return nil, fmt.Errorf("item %s: encrypted data too short", item.ID)
+		}
+		nonce, sealed := item.Data[:aead.NonceSize()], item.Data[aead.NonceSize():]
+		data, err := aead.Open(nil, nonce, sealed, additionalData(tenant, item))
+		if err != nil {
+			return nil, fmt.Errorf("item %s: %w", item.ID, err)
+		}
+		item.Data = data
+		item.Encrypted = false
+	}
+	if item.Compressed {
+		data, err := zstdDecoder.DecodeAll(item.Data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("item %s: %w", item.ID, err)
+		}
+		item.Data = data
+		item.Compressed = false
+	}
+	return item, nil
+}
+
+// additionalData binds the encrypted data to the item, so it cannot be swapped with the data of another one.
+func additionalData(tenant string, item *pb.QueueItem) []byte {
+	return []byte(tenant + "/" + item.ID)
+}
+
+// sealedQueue stores the data of the items compressed and encrypted.
+type sealedQueue struct {
+	provider.Queue[*pb.QueueItem]
+	tenant  string
+	sealing *sealing
+}
+
+func (q *sealedQueue) Add(m provider.Message[*pb.QueueItem]) error {
+	return q.AddAll([]provider.Message[*pb.QueueItem]{m})
+}
+
+func (q *sealedQueue) AddAll(ms []provider.Message[*pb.QueueItem]) error {
+	sealed := make([]provider.Message[*pb.QueueItem], len(ms))
+	for i, m := range ms {
+		item, err := q.sealing.seal(q.tenant, m.Data)
+		if err != nil {
+			return err
+		}
+		m.Data = item
+		sealed[i] = m
+	}
+	return q.Queue.AddAll(sealed)
+}
+
+// Lease opens the data of the leased items. The items that cannot be opened are released with the
+// error as the failure, and the next items are leased in their place, so they do not hold back the queue.
+func (q *sealedQueue) Lease(n int, visibility time.Duration) ([]provider.Leased[*pb.QueueItem], error) {
+	n = min(n, provider.MaxItems)
+	opened := make([]provider.Leased[*pb.QueueItem], 0, n)
+	failed := make([]provider.Leased[*pb.QueueItem], 0)
+	reasons := make([]string, 0)
+	for len(opened) < n {
+		ls, err := q.Queue.Lease(n-len(opened), visibility)
+		if err != nil {
+			if len(opened) == 0 && len(failed) == 0 {
+				return ls, err
+			}
+			break
+		}
+		before := len(opened)
+		for _, l := range ls {
+			item, err := q.sealing.open(q.tenant, l.Data)
+			if err != nil {
+				failed = append(failed, l)
+				reasons = append(reasons, err.Error())
+				continue
+			}
+			l.Data = item
+			opened = append(opened, l)
+		}
+		if len(ls) == 0 || len(opened)-before == len(ls) {
+			break
+		}
+	}
+	// the failed items are released once the rest were leased, so they are not leased again in their
+	// place. If they cannot be released, they are visible again when the lease expires.
+	for i, l := range failed {
+		_ = q.Queue.Nack(l.Receipt, reasons[i], 0)
+	}
+	if len(opened) == 0 {
+		return nil, provider.ErrQueueEmpty
+	}
+	return opened, nil
+}
+
+// Peek returns the items that cannot be opened as they are stored, still flagged as compressed or
+// encrypted.
+func (q *sealedQueue) Peek(n int) ([]provider.Message[*pb.QueueItem], error) {
+	ms, err := q.Queue.Peek(n)
+	if err != nil {
+		return ms, err
+	}
+	for i := range ms {
+		if item, err := q.sealing.open(q.tenant, ms[i].Data); err == nil {
+			ms[i].Data = item
+		}
+	}
+	return ms, nil
+}
+
+func (q *sealedQueue) Export(fn func(provider.Message[*pb.QueueItem]) error) error {
+	exporter, ok := q.Queue.(provider.Exporter[*pb.QueueItem])
+	if !ok {
+		return ErrMigrationNotSupported
+	}
+	return exporter.Export(func(m provider.Message[*pb.QueueItem]) error {
+		item, err := q.sealing.open(q.tenant, m.Data)
+		if err != nil {
+			return err
+		}
+		m.Data = item
+		return fn(m)
+	})
+}
+
+func (q *sealedQueue) Close() error {
+	return closeQueue(q.Queue)
+}
+
+func (q *sealedQueue) unwrap() provider.Queue[*pb.QueueItem] {
+	return q.Queue
+}
+
+// keyring holds the data keys of the tenants. They are created the first time an item of the tenant is
+// encrypted.
+type keyring struct {
+	// path is the file the wrapped keys are stored in. They are only kept in memory if it is empty.
+	path   string
+	master cipher.AEAD
+	mu     sync.Mutex
+	keys   map[string]cipher.AEAD
+	stored keyringData
+}
+
+type keyringData struct {
+	// Master identifies the master key that wrapped the keys.
+	Master string `json:"master"`
+	// Keys holds the wrapped data key of every tenant.
+	Keys map[string][]byte `json:"keys"`
+}
+
+func openKeyring(ctx context.Context, path string, o EncryptionOption) (*keyring, error) {
+	master, err := newAEAD(o.MasterKey)
+	if err != nil {
+		return nil, err
+	}
+	k := &keyring{
+		path:   path,
+		master: master,
+		keys:   make(map[string]cipher.AEAD),
+		stored: keyringData{Master: fingerprint(o.MasterKey), Keys: make(map[string][]byte)},
+	}
+	if path == "" {
+		return k, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return k, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored keyringData
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return nil, err
+	}
+	switch {
+	case stored.Master == k.stored.Master:
+		for tenant, wrapped := range stored.Keys {
+			if _, err := k.unwrapKey(master, tenant, wrapped); err != nil {
+				return nil, err
+			}
+		}
+	case len(stored.Keys) == 0:
+	case len(o.PreviousKey) > 0 && stored.Master == fingerprint(o.PreviousKey):
+		previous, err := newAEAD(o.PreviousKey)
+		if err != nil {
+			return nil, err
+		}
+		for tenant, wrapped := range stored.Keys {
+			key, err := k.unwrapKey(previous, tenant, wrapped)
+			if err != nil {
+				return nil, err
+			}
+			if err := k.wrapKey(tenant, key); err != nil {
+				return nil, err
+			}
+		}
+		if err := k.save(); err != nil {
+			return nil, err
+		}
+		zerolog.Ctx(ctx).Info().Msgf("%d data keys wrapped by the new master key", len(stored.Keys))
+	default:
+		return nil, ErrMasterKeyMismatch
+	}
+	return k, nil
+}
+
+// dataKey returns the cipher of the tenant, creating its key if it does not have one.
+func (k *keyring) dataKey(tenant string) (cipher.AEAD, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if aead, ok := k.keys[tenant]; ok {
+		return aead, nil
+	}
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	if err := k.wrapKey(tenant, key); err != nil {
+		return nil, err
+	}
+	if err := k.save(); err != nil {
+		delete(k.keys, tenant)
+		delete(k.stored.Keys, tenant)
+		return nil, err
+	}
+	return k.keys[tenant], nil
+}
+
+func (k *keyring) existing(tenant string) (cipher.AEAD, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	aead, ok := k.keys[tenant]
+	return aead, ok
+}
+
+func (k *keyring) wrapKey(tenant string, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, k.master.NonceSize(), k.master.NonceSize()+len(key)+k.master.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	k.stored.Keys[tenant] = k.master.Seal(nonce, nonce, key, []byte(tenant))
+	k.keys[tenant] = aead
+	return nil
+}
+
+func (k *keyring) unwrapKey(master cipher.AEAD, tenant string, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < master.NonceSize() {
+		return nil, fmt.Errorf("data key of %s: too short", tenant)
+	}
+	key, err := master.Open(nil, wrapped[:master.NonceSize()], wrapped[master.NonceSize():], []byte(tenant))
+	if err != nil {
+		return nil, fmt.Errorf("data key of %s: %w", tenant, err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	k.stored.Keys[tenant] = wrapped
+	k.keys[tenant] = aead
+	return key, nil
+}
+
+// save writes the wrapped keys to a temporary file that replaces the previous one.
+func (k *keyring) save() error {
+	if k.path == "" {
+		return nil
+	}
+	b, err := json.Marshal(k.stored)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(k.path), keyringFile+".*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(b)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err = errors.Join(err, file.Close()); err != nil {
+		return errors.Join(err, os.Remove(file.Name()))
+	}
+	return os.Rename(file.Name(), k.path)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// fingerprint identifies a master key without revealing it.
+func fingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
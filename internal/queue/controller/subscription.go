@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/queue/provider"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/metadata"
+)
+
+// subscriptionPoll is how often a subscription looks for the items that become visible without being
+// queued, such as the delayed ones or the ones whose lease expired.
+const subscriptionPoll = 1 * time.Second
+
+type subscriptions struct {
+	mutex   sync.Mutex
+	byQueue map[string]map[*subscription]struct{}
+}
+
+// subscription tracks the items pushed to a subscriber that were not acknowledged or rejected yet.
+type subscription struct {
+	maxInFlight int
+	mutex       sync.Mutex
+	inFlight    map[string]time.Time
+	wake        chan struct{}
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{
+		byQueue: make(map[string]map[*subscription]struct{}),
+	}
+}
+
+func (s *subscriptions) add(queue string, maxInFlight int) *subscription {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sub := &subscription{
+		maxInFlight: maxInFlight,
+		inFlight:    make(map[string]time.Time),
+		wake:        make(chan struct{}, 1),
+	}
+	subs, ok := s.byQueue[queue]
+	if !ok {
+		subs = make(map[*subscription]struct{})
+		s.byQueue[queue] = subs
+	}
+	subs[sub] = struct{}{}
+	return sub
+}
+
+func (s *subscriptions) remove(queue string, sub *subscription) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.byQueue[queue], sub)
+	if len(s.byQueue[queue]) == 0 {
+		delete(s.byQueue, queue)
+	}
+}
+
+// notify wakes up the subscribers of a queue that has new visible items.
+func (s *subscriptions) notify(queue string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for sub := range s.byQueue[queue] {
+		sub.signal()
+	}
+}
+
+// release gives back the credit of an item to the subscriber it was pushed to.
+func (s *subscriptions) release(queue string, receipt string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for sub := range s.byQueue[queue] {
+		if sub.release(receipt) {
+			sub.signal()
+			return
+		}
+	}
+}
+
+// reset gives back the credits of every item pushed to the subscribers of a queue.
+func (s *subscriptions) reset(queue string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for sub := range s.byQueue[queue] {
+		sub.mutex.Lock()
+		sub.inFlight = make(map[string]time.Time)
+		sub.mutex.Unlock()
+		sub.signal()
+	}
+}
+
+func (s *subscription) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// credits returns the number of items that can be pushed. The items whose lease expired do not count.
+func (s *subscription) credits(now time.Time) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for receipt, visibleAt := range s.inFlight {
+		if now.After(visibleAt) {
+			delete(s.inFlight, receipt)
+		}
+	}
+	return s.maxInFlight - len(s.inFlight)
+}
+
+func (s *subscription) track(receipt string, visibleAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.inFlight[receipt] = visibleAt
+}
+
+func (s *subscription) release(receipt string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.inFlight[receipt]; !ok {
+		return false
+	}
+	delete(s.inFlight, receipt)
+	return true
+}
+
+// Subscribe pushes the items of a queue as they become visible until the subscriber or the server ends.
+func (s *Controller) Subscribe(in *pb.SubscribeRequest, stream pb.Queue_SubscribeServer) error {
+	if err := s.checkPrimary(); err != nil {
+		return err
+	}
+	myqueue, err := s.cache.GetQueue(s.ctx, in.Tenant, in.Queue)
+	if err != nil {
+		return err
+	}
+	visibilityTimeout := s.visibilityTimeout
+	if in.VisibilityTimeout != nil {
+		visibilityTimeout = in.VisibilityTimeout.AsDuration()
+	}
+	name := getQueueName(in.Tenant, in.Queue)
+	sub := s.subscriptions.add(name, max(int(in.MaxInFlight), 1))
+	defer s.subscriptions.remove(name, sub)
+	// the header tells the subscriber that the subscription was accepted.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	poll := time.NewTicker(subscriptionPoll)
+	defer poll.Stop()
+	for {
+		if err := s.push(in, myqueue, sub, stream, visibilityTimeout); err != nil {
+			return err
+		}
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case <-stream.Context().Done():
+			return nil
+		case <-sub.wake:
+		case <-poll.C:
+		}
+	}
+}
+
+func (s *Controller) push(in *pb.SubscribeRequest, myqueue provider.Queue[*pb.QueueItem], sub *subscription,
+	stream pb.Queue_SubscribeServer, visibilityTimeout time.Duration,
+) error {
+	credits := sub.credits(time.Now())
+	if credits <= 0 {
+		return nil
+	}
+	ls, err := myqueue.Lease(credits, visibilityTimeout)
+	if err != nil {
+		if errors.Is(err, provider.ErrQueueEmpty) {
+			return nil
+		}
+		zerolog.Ctx(s.ctx).Warn().AnErr("error", err).Msgf("queue %s/%s: error leasing items for a subscriber", in.Tenant, in.Queue)
+		return nil
+	}
+	ls = s.deadLetter(in.Tenant, in.Queue, myqueue, ls)
+	ls = s.expire(in.Tenant, in.Queue, myqueue, ls)
+	for i, l := range ls {
+		sub.track(l.Receipt, l.VisibleAt)
+		if err := stream.Send(dequeuedItem(l)); err != nil {
+			// the items that were not pushed are released without counting the attempt.
+			for _, l := range ls[i:] {
+				sub.release(l.Receipt)
+				err = errors.Join(err, myqueue.Nack(l.Receipt, "", 0))
+			}
+			return err
+		}
+	}
+	return nil
+}
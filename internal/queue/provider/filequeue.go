@@ -1,95 +1,846 @@
 package provider
 
 import (
+	"archive/tar"
 	"bytes"
 	"encoding/gob"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/andrescosta/goico/pkg/ioutil"
 )
 
 const (
-	preffix = "qdata"
-	suffix  = ".q"
-	dataDir = "data"
+	preffix       = "qdata"
+	suffix        = ".q"
+	leaseSuffix   = ".lease"
+	delayedSuffix = ".delayed"
+	// files that cannot be decoded are renamed to [file].error and kept apart from the queue.
+	quarantineSuffix = ".error"
+	// batches are written to a [batch].tmp directory that is renamed to [batch] once it is complete.
+	batchPrefix   = "batch-"
+	stagingSuffix = ".tmp"
+	dataDir       = "data"
 )
 
+// FileQueue stores every item in its own file. The visible items are named [file].q for the lowest priority
+// and [file].q[priority] for the rest, and they are served by modification time.
 type FileQueue[T any] struct {
 	directory string
+	aging     time.Duration
 	mutex     sync.Mutex
+	leases    map[string]fileLease[T]
+	// stamp is the last write time put in the file names, so the items written at the same time keep their order.
+	stamp int64
 }
 
-func NewFileQueue[T any](dir string, id string) (*FileQueue[T], error) {
+type timedFile struct {
+	file  ioutil.File
+	since time.Time
+}
+
+type fileLease[T any] struct {
+	filename  string
+	message   Message[T]
+	visibleAt time.Time
+	// reason is the failure recorded when the lease expires.
+	reason string
+}
+
+// NewFileQueue returns a queue stored in a directory of dir. aging is the waiting time after which an item
+// is served as if it had one more level of priority.
+func NewFileQueue[T any](dir string, id string, aging time.Duration) (*FileQueue[T], error) {
 	directory := queueDirectory(dir, dataDir, id)
 	if err := os.MkdirAll(directory, 0o700); err != nil {
 		return nil, err
 	}
-	return &FileQueue[T]{directory: directory}, nil
+	if err := restoreBatches(directory); err != nil {
+		return nil, err
+	}
+	// leases do not survive a restart, so the items leased by a previous instance become visible again.
+	if err := restoreLeased(directory); err != nil {
+		return nil, err
+	}
+	return &FileQueue[T]{
+		directory: directory,
+		aging:     aging,
+		leases:    make(map[string]fileLease[T]),
+	}, nil
 }
 
-func (f *FileQueue[T]) Add(data T) error {
-	return f.writeData(data)
+func (f *FileQueue[T]) Add(m Message[T]) error {
+	return f.writeData(m)
 }
 
-func (f *FileQueue[T]) Remove() ([]T, error) {
-	return f.readAndRemove()
+// AddAll writes the items to a staging directory that is renamed once all of them are written, so a crash
+// leaves either all of them or none. Then they are moved to the queue. If the move is interrupted by a crash,
+// it is completed when the queue is opened again. If it fails, the items already moved are removed.
+func (f *FileQueue[T]) AddAll(ms []Message[T]) error {
+	switch len(ms) {
+	case 0:
+		return nil
+	case 1:
+		return f.writeData(ms[0])
+	}
+	bs := make([][]byte, len(ms))
+	for i, m := range ms {
+		b, err := encode(m)
+		if err != nil {
+			return err
+		}
+		bs[i] = b
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	staging, err := os.MkdirTemp(f.directory, batchPrefix+"*"+stagingSuffix)
+	if err != nil {
+		return err
+	}
+	for i, m := range ms {
+		f.stamp = max(f.stamp+1, time.Now().UnixNano())
+		if _, err := ioutil.WriteToRandomFile(staging, fmt.Sprintf("%s%019d-", preffix, f.stamp), fileSuffix(m), bs[i]); err != nil {
+			return errors.Join(err, os.RemoveAll(staging))
+		}
+	}
+	batch := strings.TrimSuffix(staging, stagingSuffix)
+	if err := os.Rename(staging, batch); err != nil {
+		return errors.Join(err, os.RemoveAll(staging))
+	}
+	moved, err := moveBatch(f.directory, batch)
+	if err != nil {
+		return errors.Join(err, dropBatch(f.directory, batch, moved))
+	}
+	return nil
 }
 
-func (f *FileQueue[T]) readAndRemove() ([]T, error) {
-	// sync the access to the "queue"
+func (f *FileQueue[T]) Lease(n int, visibility time.Duration) ([]Leased[T], error) {
+	return f.readAndLease(min(n, MaxItems), visibility)
+}
+
+func (f *FileQueue[T]) Ack(receipt string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.releaseExpired(time.Now()); err != nil {
+		return err
+	}
+	l, ok := f.leases[receipt]
+	if !ok {
+		return ErrReceiptUnknown
+	}
+	if err := os.Remove(l.filename + leaseSuffix); err != nil {
+		return err
+	}
+	delete(f.leases, receipt)
+	return nil
+}
+
+func (f *FileQueue[T]) Nack(receipt string, reason string, delay time.Duration) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
-	files, err := ioutil.ReadOldestFiles(f.directory, preffix, suffix, MaxItems)
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return err
+	}
+	l, ok := f.leases[receipt]
+	if !ok {
+		return ErrReceiptUnknown
+	}
+	if delay > 0 {
+		held, err := NewID()
+		if err != nil {
+			return err
+		}
+		l.visibleAt, l.reason = now.Add(delay), reason
+		delete(f.leases, receipt)
+		f.leases[held] = l
+		return nil
+	}
+	if err := f.restore(l, reason); err != nil {
+		return err
+	}
+	delete(f.leases, receipt)
+	return nil
+}
+
+func (f *FileQueue[T]) Peek(n int) ([]Message[T], error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return nil, err
+	}
+	if err := f.promoteDue(now); err != nil {
+		return nil, err
+	}
+	files, err := f.next(n, now, nil)
 	if err != nil {
-		var d []T
-		return d, errors.Join(errors.New("error removing file"), err)
+		return nil, err
 	}
-	if len(files) == 0 {
-		var d []T
-		return d, ErrQueueEmpty
+	ms := make([]Message[T], 0, len(files))
+	for _, file := range files {
+		m, err := decode[T](file.Bytes)
+		if err != nil {
+			continue
+		}
+		ms = append(ms, m)
 	}
-	ts := make([]T, len(files))
-	for i, f := range files {
-		bdata := f.Bytes
-		filename := f.Name
-		buffer := bytes.NewBuffer(bdata)
-		decoder := gob.NewDecoder(buffer)
-		var data T
-		if err := decoder.Decode(&data); err != nil {
-			var d []T
-			// if the file cannot be decoded, we rename it to [file].error for further processing
-			if errR := os.Rename(filename, filename+".error"); errR != nil {
-				err = errors.Join(errR, err)
+	return ms, nil
+}
+
+func (f *FileQueue[T]) Purge() (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	entries, err := os.ReadDir(f.directory)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !isItem(name) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(f.directory, name)); err != nil {
+			return n, err
+		}
+		n++
+	}
+	f.leases = make(map[string]fileLease[T])
+	return n, nil
+}
+
+func (f *FileQueue[T]) Stats() (Stats, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return Stats{}, err
+	}
+	if err := f.promoteDue(now); err != nil {
+		return Stats{}, err
+	}
+	entries, err := os.ReadDir(f.directory)
+	if err != nil {
+		return Stats{}, err
+	}
+	s := Stats{}
+	for _, e := range entries {
+		name := e.Name()
+		if _, ok := quarantinedID(name); ok && !e.IsDir() {
+			s.Quarantined++
+			continue
+		}
+		if e.IsDir() || !isItem(name) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return Stats{}, err
+		}
+		s.Bytes += info.Size()
+		switch {
+		case strings.HasSuffix(name, delayedSuffix):
+			s.Delayed++
+			continue
+		case strings.HasSuffix(name, leaseSuffix):
+			s.Leased++
+		default:
+			s.Visible++
+		}
+		// the modification time is the time the item is waiting since.
+		s.waiting(info.ModTime())
+	}
+	return s, nil
+}
+
+func (f *FileQueue[T]) readAndLease(n int, visibility time.Duration) ([]Leased[T], error) {
+	// sync the access to the "queue"
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		var d []Leased[T]
+		return d, err
+	}
+	if err := f.promoteDue(now); err != nil {
+		var d []Leased[T]
+		return d, err
+	}
+	visibleAt := now.Add(visibility)
+	ts := make([]Leased[T], 0, n)
+	for len(ts) < n {
+		files, err := f.next(n-len(ts), now, f.leasedKeys())
+		if err != nil {
+			var d []Leased[T]
+			return d, errors.Join(errors.New("error removing file"), err)
+		}
+		quarantined := false
+		for _, file := range files {
+			filename := file.Name
+			m, err := decode[T](file.Bytes)
+			if err != nil {
+				// the file is set aside, so the rest of the batch is still delivered.
+				if err := quarantine(filename, now); err != nil {
+					var d []Leased[T]
+					return d, err
+				}
+				quarantined = true
+				continue
+			}
+			receipt, err := NewID()
+			if err != nil {
+				var d []Leased[T]
+				return d, err
 			}
-			return d, errors.Join(errors.New("error decoding"), err)
+			// the leased file keeps its modification time, so it preserves its position when it is restored.
+			if err := os.Rename(filename, filename+leaseSuffix); err != nil {
+				var d []Leased[T]
+				return d, err
+			}
+			m.Attempts++
+			f.leases[receipt] = fileLease[T]{filename: filename, message: m, visibleAt: visibleAt, reason: ReasonLeaseExpired}
+			ts = append(ts, Leased[T]{Message: m, Receipt: receipt, VisibleAt: visibleAt})
 		}
-		if err := os.Remove(filename); err != nil {
-			var t []T
-			return t, err
+		// the files that were quarantined leave room for the next ones.
+		if !quarantined {
+			break
 		}
-		ts[i] = data
+	}
+	if len(ts) == 0 {
+		var d []Leased[T]
+		return d, ErrQueueEmpty
 	}
 	return ts, nil
 }
 
-func (f *FileQueue[T]) writeData(data T) error {
-	buffer := bytes.NewBuffer(make([]byte, 0))
-	encoder := gob.NewEncoder(buffer)
-	if err := encoder.Encode(data); err != nil {
-		return errors.Join(errors.New("error encoding"), err)
+func (f *FileQueue[T]) Archive(w *tar.Writer) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return archiveDirectory(w, f.directory)
+}
+
+func (f *FileQueue[T]) Remove() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.leases = make(map[string]fileLease[T])
+	return os.RemoveAll(f.directory)
+}
+
+// Export calls fn for every item in the order of the modification time of its file. The files that
+// cannot be decoded are skipped.
+func (f *FileQueue[T]) Export(fn func(Message[T]) error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	entries, err := os.ReadDir(f.directory)
+	if err != nil {
+		return err
+	}
+	type exported struct {
+		name    string
+		since   time.Time
+		message Message[T]
+	}
+	items := make([]exported, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !isItem(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		b, err := os.ReadFile(filepath.Join(f.directory, e.Name()))
+		if err != nil {
+			return err
+		}
+		m, err := decode[T](b)
+		if err != nil {
+			continue
+		}
+		items = append(items, exported{name: e.Name(), since: info.ModTime(), message: m})
+	}
+	slices.SortFunc(items, func(a, b exported) int {
+		if c := a.since.Compare(b.since); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	for _, item := range items {
+		if err := fn(item.message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Quarantined returns the files that could not be decoded, oldest first.
+func (f *FileQueue[T]) Quarantined() ([]Quarantined, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	entries, err := os.ReadDir(f.directory)
+	if err != nil {
+		return nil, err
+	}
+	qs := make([]Quarantined, 0)
+	for _, e := range entries {
+		id, ok := quarantinedID(e.Name())
+		if e.IsDir() || !ok {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		b, err := os.ReadFile(filepath.Join(f.directory, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		q := Quarantined{ID: id, Data: b, Date: info.ModTime()}
+		// the error is not stored, decoding the file again returns it.
+		if _, err := decode[T](b); err != nil {
+			q.Error = err.Error()
+		}
+		qs = append(qs, q)
+	}
+	slices.SortFunc(qs, func(a, b Quarantined) int {
+		return a.Date.Compare(b.Date)
+	})
+	return qs, nil
+}
+
+func (f *FileQueue[T]) RequeueQuarantined(id string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	filename, err := f.quarantinedFile(id)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if _, err := decode[T](b); err != nil {
+		return errors.Join(errors.New("error decoding"), err)
+	}
+	// it is served after the items that are already visible.
+	now := time.Now()
+	if err := os.Chtimes(filename, now, now); err != nil {
+		return err
+	}
+	return os.Rename(filename, strings.TrimSuffix(filename, quarantineSuffix))
+}
+
+func (f *FileQueue[T]) DeleteQuarantined(id string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	filename, err := f.quarantinedFile(id)
+	if err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
+
+func (f *FileQueue[T]) quarantinedFile(id string) (string, error) {
+	if _, ok := priorityOf(id); !ok || filepath.Base(id) != id {
+		return "", ErrQuarantinedUnknown
 	}
+	filename := filepath.Join(f.directory, id+quarantineSuffix)
+	if _, err := os.Stat(filename); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrQuarantinedUnknown
+		}
+		return "", err
+	}
+	return filename, nil
+}
 
+// next reads up to n visible files in the order they are served. If blocked is not nil, the files whose
+// partition key is in it are skipped.
+func (f *FileQueue[T]) next(n int, now time.Time, blocked map[string]struct{}) ([]ioutil.File, error) {
+	entries, err := os.ReadDir(f.directory)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[uint32]struct{})
+	for _, e := range entries {
+		if p, ok := priorityOf(e.Name()); ok && !e.IsDir() {
+			present[p] = struct{}{}
+		}
+	}
+	priorities := make([]uint32, 0, len(present))
+	for p := range present {
+		priorities = append(priorities, p)
+	}
+	slices.Sort(priorities)
+	slices.Reverse(priorities)
+	levels := make([]level, len(priorities))
+	files := make([][]ioutil.File, len(priorities))
+	for i, p := range priorities {
+		fs, l, err := f.readLevel(p, n, blocked)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = fs
+		levels[i] = l
+	}
+	picks := schedule(levels, n, now, f.aging, blocked)
+	next := make([]ioutil.File, len(picks))
+	for i, pk := range picks {
+		next[i] = files[pk.level][pk.index]
+	}
+	return next, nil
+}
+
+// readLevel reads the oldest files of a priority level. If blocked is not nil, it reads more files until
+// n of them have a partition key that is not blocked or the level is exhausted.
+func (f *FileQueue[T]) readLevel(priority uint32, n int, blocked map[string]struct{}) ([]ioutil.File, level, error) {
+	timed, err := f.levelFiles(priority)
+	if err != nil {
+		return nil, level{}, err
+	}
+	read := 0
+	for size := n; ; size *= 2 {
+		size = min(size, len(timed))
+		for ; read < size; read++ {
+			b, err := os.ReadFile(timed[read].file.Name)
+			if err != nil {
+				return nil, level{}, err
+			}
+			timed[read].file.Bytes = b
+		}
+		fs := make([]ioutil.File, size)
+		since := make([]time.Time, size)
+		for j, t := range timed[:size] {
+			fs[j] = t.file
+			since[j] = t.since
+		}
+		l := level{priority: priority, since: since}
+		if blocked == nil {
+			return fs, l, nil
+		}
+		l.keys = make([]string, len(fs))
+		free := 0
+		for j, file := range fs {
+			// the files that cannot be decoded are handled when they are leased.
+			if m, err := decode[T](file.Bytes); err == nil {
+				l.keys[j] = m.PartitionKey
+			}
+			if !l.blocked(j, blocked) {
+				free++
+			}
+		}
+		if free >= n || size == len(timed) {
+			return fs, l, nil
+		}
+	}
+}
+
+// levelFiles lists the visible files of a priority level in the order they are served: by modification time,
+// and by the write stamp in their names when it is the same. The staging directories of the batches are
+// not read.
+func (f *FileQueue[T]) levelFiles(priority uint32) ([]timedFile, error) {
+	entries, err := os.ReadDir(f.directory)
+	if err != nil {
+		return nil, err
+	}
+	timed := make([]timedFile, 0, len(entries))
+	for _, e := range entries {
+		if p, ok := priorityOf(e.Name()); !ok || p != priority || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		timed = append(timed, timedFile{file: ioutil.File{Name: filepath.Join(f.directory, e.Name())}, since: info.ModTime()})
+	}
+	slices.SortFunc(timed, func(a, b timedFile) int {
+		if c := a.since.Compare(b.since); c != 0 {
+			return c
+		}
+		return strings.Compare(a.file.Name, b.file.Name)
+	})
+	return timed, nil
+}
+
+// leasedKeys returns the partition keys of the leased files.
+func (f *FileQueue[T]) leasedKeys() map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, l := range f.leases {
+		if key := l.message.PartitionKey; key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// releaseExpired makes visible again the items whose lease has expired.
+func (f *FileQueue[T]) releaseExpired(now time.Time) error {
+	for receipt, l := range f.leases {
+		if now.After(l.visibleAt) {
+			if err := f.restore(l, l.reason); err != nil {
+				return err
+			}
+			delete(f.leases, receipt)
+		}
+	}
+	return nil
+}
+
+// promoteDue makes visible the delayed files that are due. Their modification time is set to
+// the time they became due, so they are positioned after the items that were visible before.
+func (f *FileQueue[T]) promoteDue(now time.Time) error {
+	entries, err := os.ReadDir(f.directory)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), delayedSuffix) {
+			continue
+		}
+		name, notBefore, err := parseDelayed(e.Name())
+		if err != nil {
+			// it is not a file written by the queue
+			continue
+		}
+		if notBefore.After(now) {
+			continue
+		}
+		delayed := filepath.Join(f.directory, e.Name())
+		if err := os.Chtimes(delayed, notBefore, notBefore); err != nil {
+			return err
+		}
+		if err := os.Rename(delayed, filepath.Join(f.directory, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restore persists the delivery attempt and makes the leased file visible again.
+func (f *FileQueue[T]) restore(l fileLease[T], reason string) error {
+	leased := l.filename + leaseSuffix
+	info, err := os.Stat(leased)
+	if err != nil {
+		return err
+	}
+	l.message.fail(reason)
+	b, err := encode(l.message)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(leased, b, 0o600); err != nil {
+		return err
+	}
+	// the original modification time is kept to preserve the position of the item in the queue.
+	if err := os.Chtimes(leased, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(leased, l.filename)
+}
+
+func (f *FileQueue[T]) writeData(m Message[T]) error {
+	b, err := encode(m)
+	if err != nil {
+		return err
+	}
+	fsuffix := fileSuffix(m)
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
-	if _, err := ioutil.WriteToRandomFile(f.directory, preffix, suffix, buffer.Bytes()); err != nil {
+	f.stamp = max(f.stamp+1, time.Now().UnixNano())
+	if _, err := ioutil.WriteToRandomFile(f.directory, fmt.Sprintf("%s%019d-", preffix, f.stamp), fsuffix, b); err != nil {
 		return err
 	}
 	return nil
 }
 
+func fileSuffix[T any](m Message[T]) string {
+	fsuffix := levelSuffix(m.Priority)
+	if !m.due(time.Now()) {
+		// delayed files are named [file].q.[not before].delayed so they are not read until they are due.
+		fsuffix = fmt.Sprintf("%s.%d%s", fsuffix, m.NotBefore.UnixNano(), delayedSuffix)
+	}
+	return fsuffix
+}
+
+func parseDelayed(filename string) (string, time.Time, error) {
+	name := strings.TrimSuffix(filename, delayedSuffix)
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return "", time.Time{}, errors.New("invalid delayed file name")
+	}
+	if _, ok := priorityOf(name[:i]); !ok {
+		return "", time.Time{}, errors.New("invalid delayed file name")
+	}
+	nanos, err := strconv.ParseInt(name[i+1:], 10, 64)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return name[:i], time.Unix(0, nanos), nil
+}
+
+func levelSuffix(priority uint32) string {
+	if priority == 0 {
+		return suffix
+	}
+	return fmt.Sprintf("%s%d", suffix, priority)
+}
+
+// priorityOf returns the priority of a visible item file.
+func priorityOf(name string) (uint32, bool) {
+	i := strings.LastIndex(name, suffix)
+	if !strings.HasPrefix(name, preffix) || i < 0 {
+		return 0, false
+	}
+	level := name[i+len(suffix):]
+	if level == "" {
+		return 0, true
+	}
+	p, err := strconv.ParseUint(level, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(p), true
+}
+
+// isItem reports whether the file holds an item, either visible, leased or delayed.
+func isItem(name string) bool {
+	if strings.HasSuffix(name, delayedSuffix) {
+		_, _, err := parseDelayed(name)
+		return err == nil
+	}
+	_, ok := priorityOf(strings.TrimSuffix(name, leaseSuffix))
+	return ok
+}
+
+// quarantine renames a file that cannot be decoded to [file].error. Its modification time is set to the
+// time it was quarantined.
+func quarantine(filename string, now time.Time) error {
+	if err := os.Chtimes(filename, now, now); err != nil {
+		return err
+	}
+	return os.Rename(filename, filename+quarantineSuffix)
+}
+
+// quarantinedID returns the ID of a quarantined file, which is the name of the file it was.
+func quarantinedID(name string) (string, bool) {
+	id, ok := strings.CutSuffix(name, quarantineSuffix)
+	if !ok {
+		return "", false
+	}
+	if _, ok := priorityOf(id); !ok {
+		return "", false
+	}
+	return id, true
+}
+
+func encode[T any](m Message[T]) ([]byte, error) {
+	buffer := bytes.NewBuffer(make([]byte, 0))
+	encoder := gob.NewEncoder(buffer)
+	if err := encoder.Encode(m); err != nil {
+		return nil, errors.Join(errors.New("error encoding"), err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func decode[T any](b []byte) (Message[T], error) {
+	var m Message[T]
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&m); err != nil {
+		// files written before the delivery metadata was introduced only contain the payload.
+		var data T
+		if errL := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); errL != nil {
+			return m, err
+		}
+		return Message[T]{Data: data}, nil
+	}
+	return m, nil
+}
+
+func restoreLeased(directory string) error {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), leaseSuffix) {
+			continue
+		}
+		if _, ok := priorityOf(strings.TrimSuffix(e.Name(), leaseSuffix)); !ok {
+			continue
+		}
+		filename := filepath.Join(directory, strings.TrimSuffix(e.Name(), leaseSuffix))
+		if err := os.Rename(filename+leaseSuffix, filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreBatches moves the items of the complete batches to the queue and deletes the incomplete ones.
+func restoreBatches(directory string) error {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), batchPrefix) {
+			continue
+		}
+		batch := filepath.Join(directory, e.Name())
+		if strings.HasSuffix(e.Name(), stagingSuffix) {
+			err = os.RemoveAll(batch)
+		} else {
+			_, err = moveBatch(directory, batch)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveBatch moves the items of a batch to the queue and returns the names of the ones it moved.
+func moveBatch(directory string, batch string) ([]string, error) {
+	entries, err := os.ReadDir(batch)
+	if err != nil {
+		return nil, err
+	}
+	moved := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if err := os.Rename(filepath.Join(batch, e.Name()), filepath.Join(directory, e.Name())); err != nil {
+			return moved, err
+		}
+		moved = append(moved, e.Name())
+	}
+	return moved, os.Remove(batch)
+}
+
+// dropBatch removes a batch whose move failed, and the items that were already moved to the queue.
+// The batch is renamed back to a staging directory first, so it is deleted when the queue is opened
+// again if it cannot be removed now.
+func dropBatch(directory string, batch string, moved []string) error {
+	staging := batch + stagingSuffix
+	err := os.Rename(batch, staging)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		staging = batch
+	} else {
+		err = nil
+	}
+	for _, name := range moved {
+		if errR := os.Remove(filepath.Join(directory, name)); errR != nil && !errors.Is(errR, os.ErrNotExist) {
+			err = errors.Join(err, errR)
+		}
+	}
+	return errors.Join(err, os.RemoveAll(staging))
+}
+
 func queueDirectory(baseDir string, dataDir string, id string) string {
 	return filepath.Join(baseDir, dataDir, id)
 }
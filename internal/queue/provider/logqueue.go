@@ -0,0 +1,913 @@
+package provider
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	logDir             = "log"
+	segmentSuffix      = ".seg"
+	offsetFile         = "offset"
+	recordHeaderSize   = 8
+	DefaultSegmentSize = 64 << 20
+)
+
+var ErrCorruptedLog = errors.New("corrupted log record")
+
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes every write to disk before returning.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval flushes the writes at most once per interval.
+	SyncInterval
+	// SyncNever leaves the flushing to the operating system.
+	SyncNever
+)
+
+type LogOption struct {
+	// SegmentSize is the size after which a new segment is started. The segments before the read offset are deleted,
+	// and the items left in the oldest one are copied to the new one when most of the log is no longer needed.
+	SegmentSize  int64
+	Sync         SyncPolicy
+	SyncInterval time.Duration
+}
+
+// LogQueue stores the items in segmented append-only logs. A new version of an item is appended every time
+// its delivery metadata changes and a tombstone when it is acknowledged, so replaying the segments from the
+// read offset rebuilds the queue. Only the metadata is kept in memory.
+type LogQueue[T any] struct {
+	directory string
+	option    LogOption
+	aging     time.Duration
+	mutex     sync.Mutex
+	nextID    uint64
+	entries   map[uint64]*logEntry[T]
+	// levels holds the visible items of every priority sorted by the time they are waiting.
+	levels map[uint32][]*logEntry[T]
+	// delayed is sorted by NotBefore.
+	delayed  []*logEntry[T]
+	leases   map[string]logLease
+	segments []uint64
+	files    map[uint64]*os.File
+	active   uint64
+	size     int64
+	lastSync time.Time
+}
+
+type logEntry[T any] struct {
+	id    uint64
+	loc   location
+	since time.Time
+	// message holds the delivery metadata. The data is read from the log when the item is delivered.
+	message Message[T]
+}
+
+type location struct {
+	segment  uint64
+	position int64
+	size     int64
+}
+
+type logLease struct {
+	id        uint64
+	visibleAt time.Time
+	// reason is the failure recorded when the lease expires.
+	reason string
+}
+
+type logRecord[T any] struct {
+	ID      uint64
+	Since   time.Time
+	Deleted bool
+	Message Message[T]
+	// Pending is the number of records of the same batch that follow this one. The records of a batch
+	// are only applied once its last record is read.
+	Pending int
+}
+
+type batchedRecord[T any] struct {
+	record logRecord[T]
+	loc    location
+}
+
+// logOffset is the location of the oldest record needed to rebuild the queue.
+type logOffset struct {
+	Segment  uint64
+	Position int64
+	NextID   uint64
+}
+
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	switch strings.ToLower(s) {
+	case "always":
+		return SyncAlways, nil
+	case "interval":
+		return SyncInterval, nil
+	case "never":
+		return SyncNever, nil
+	default:
+		return 0, fmt.Errorf("unknown sync policy %q", s)
+	}
+}
+
+// NewLogQueue returns a queue stored in a directory of dir. aging is the waiting time after which an item
+// is served as if it had one more level of priority.
+func NewLogQueue[T any](dir string, id string, aging time.Duration, option LogOption) (*LogQueue[T], error) {
+	directory := queueDirectory(dir, logDir, id)
+	if err := os.MkdirAll(directory, 0o700); err != nil {
+		return nil, err
+	}
+	if option.SegmentSize == 0 {
+		option.SegmentSize = DefaultSegmentSize
+	}
+	f := &LogQueue[T]{
+		directory: directory,
+		option:    option,
+		aging:     aging,
+		entries:   make(map[uint64]*logEntry[T]),
+		levels:    make(map[uint32][]*logEntry[T]),
+		delayed:   make([]*logEntry[T], 0),
+		leases:    make(map[string]logLease),
+		files:     make(map[uint64]*os.File),
+	}
+	// leases do not survive a restart, so the items leased by a previous instance become visible again.
+	if err := f.load(); err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+	return f, nil
+}
+
+func (f *LogQueue[T]) Add(m Message[T]) error {
+	return f.AddAll([]Message[T]{m})
+}
+
+// AddAll appends the records of the items with a single write to one segment. If the write is torn, the
+// records of the batch that made it to disk are discarded when the log is replayed.
+func (f *LogQueue[T]) AddAll(ms []Message[T]) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	rs := make([]logRecord[T], len(ms))
+	for i, m := range ms {
+		rs[i] = logRecord[T]{ID: f.nextID + uint64(i), Since: now, Message: m, Pending: len(ms) - i - 1}
+	}
+	locs, err := f.appendAll(rs)
+	if err != nil {
+		return err
+	}
+	f.nextID += uint64(len(ms))
+	var empty T
+	for i, r := range rs {
+		e := &logEntry[T]{id: r.ID, loc: locs[i], since: now, message: r.Message}
+		e.message.Data = empty
+		f.entries[e.id] = e
+		if !e.message.due(now) {
+			j := sort.Search(len(f.delayed), func(j int) bool { return f.delayed[j].message.NotBefore.After(e.message.NotBefore) })
+			f.delayed = slices.Insert(f.delayed, j, e)
+			continue
+		}
+		f.restore(e)
+	}
+	return nil
+}
+
+func (f *LogQueue[T]) Lease(n int, visibility time.Duration) ([]Leased[T], error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return nil, err
+	}
+	f.promoteDue(now)
+	es := f.next(min(n, MaxItems), now, f.leasedKeys())
+	if len(es) == 0 {
+		var t []Leased[T]
+		return t, ErrQueueEmpty
+	}
+	ms, err := f.read(es)
+	if err != nil {
+		return nil, err
+	}
+	visibleAt := now.Add(visibility)
+	ls := make([]Leased[T], len(es))
+	for i, e := range es {
+		receipt, err := NewID()
+		if err != nil {
+			return nil, err
+		}
+		f.take(e)
+		e.message.Attempts++
+		ms[i].Attempts = e.message.Attempts
+		f.leases[receipt] = logLease{id: e.id, visibleAt: visibleAt, reason: ReasonLeaseExpired}
+		ls[i] = Leased[T]{Message: ms[i], Receipt: receipt, VisibleAt: visibleAt}
+	}
+	return ls, nil
+}
+
+func (f *LogQueue[T]) Ack(receipt string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.releaseExpired(time.Now()); err != nil {
+		return err
+	}
+	l, ok := f.leases[receipt]
+	if !ok {
+		return ErrReceiptUnknown
+	}
+	if _, err := f.append(logRecord[T]{ID: l.id, Deleted: true}); err != nil {
+		return err
+	}
+	delete(f.leases, receipt)
+	delete(f.entries, l.id)
+	if len(f.entries) == 0 {
+		return f.compact()
+	}
+	return nil
+}
+
+func (f *LogQueue[T]) Nack(receipt string, reason string, delay time.Duration) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return err
+	}
+	l, ok := f.leases[receipt]
+	if !ok {
+		return ErrReceiptUnknown
+	}
+	if delay > 0 {
+		held, err := NewID()
+		if err != nil {
+			return err
+		}
+		delete(f.leases, receipt)
+		f.leases[held] = logLease{id: l.id, visibleAt: now.Add(delay), reason: reason}
+		return nil
+	}
+	e := f.entries[l.id]
+	e.message.fail(reason)
+	if reason != "" {
+		if err := f.rewrite(e); err != nil {
+			return err
+		}
+	}
+	delete(f.leases, receipt)
+	f.restore(e)
+	return nil
+}
+
+func (f *LogQueue[T]) Peek(n int) ([]Message[T], error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return nil, err
+	}
+	f.promoteDue(now)
+	return f.read(f.next(n, now, nil))
+}
+
+func (f *LogQueue[T]) Purge() (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	n := len(f.entries)
+	f.entries = make(map[uint64]*logEntry[T])
+	f.levels = make(map[uint32][]*logEntry[T])
+	f.delayed = make([]*logEntry[T], 0)
+	f.leases = make(map[string]logLease)
+	if err := f.roll(); err != nil {
+		return n, err
+	}
+	return n, f.compact()
+}
+
+func (f *LogQueue[T]) Stats() (Stats, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return Stats{}, err
+	}
+	f.promoteDue(now)
+	s := Stats{
+		Delayed: len(f.delayed),
+		Leased:  len(f.leases),
+	}
+	s.Visible = len(f.entries) - s.Delayed - s.Leased
+	for _, e := range f.entries {
+		s.Bytes += e.loc.size
+		if e.message.due(now) {
+			s.waiting(e.since)
+		}
+	}
+	return s, nil
+}
+
+// Close closes the segments. The queue cannot be used afterwards.
+func (f *LogQueue[T]) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var err error
+	for _, file := range f.files {
+		if f.option.Sync != SyncNever {
+			err = errors.Join(err, file.Sync())
+		}
+		err = errors.Join(err, file.Close())
+	}
+	f.files = make(map[uint64]*os.File)
+	return err
+}
+
+func (f *LogQueue[T]) Archive(w *tar.Writer) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	// the writes that were not flushed yet must be in the archive.
+	if file, ok := f.files[f.active]; ok {
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+	return archiveDirectory(w, f.directory)
+}
+
+// Export calls fn for every item in the order they were queued, with the data read from the log.
+func (f *LogQueue[T]) Export(fn func(Message[T]) error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	es := make([]*logEntry[T], 0, len(f.entries))
+	for _, e := range f.entries {
+		es = append(es, e)
+	}
+	slices.SortFunc(es, func(a, b *logEntry[T]) int {
+		if c := a.since.Compare(b.since); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+	for _, e := range es {
+		r, err := f.readRecord(e.loc)
+		if err != nil {
+			return err
+		}
+		if err := fn(r.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *LogQueue[T]) Remove() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var err error
+	for _, file := range f.files {
+		err = errors.Join(err, file.Close())
+	}
+	f.files = make(map[uint64]*os.File)
+	f.entries = make(map[uint64]*logEntry[T])
+	f.levels = make(map[uint32][]*logEntry[T])
+	f.delayed = make([]*logEntry[T], 0)
+	f.leases = make(map[string]logLease)
+	return errors.Join(err, os.RemoveAll(f.directory))
+}
+
+// next returns up to n visible entries in the order they are served. If blocked is not nil, the entries
+// whose partition key is in it are skipped.
+func (f *LogQueue[T]) next(n int, now time.Time, blocked map[string]struct{}) []*logEntry[T] {
+	priorities := make([]uint32, 0, len(f.levels))
+	for p := range f.levels {
+		priorities = append(priorities, p)
+	}
+	slices.Sort(priorities)
+	slices.Reverse(priorities)
+	levels := make([]level, len(priorities))
+	for i, p := range priorities {
+		es := f.levels[p]
+		size := min(n, len(es))
+		// the items of the blocked keys are skipped, so the rest of the level may be needed.
+		if len(blocked) > 0 {
+			size = len(es)
+		}
+		since := make([]time.Time, size)
+		keys := make([]string, size)
+		for j := range since {
+			since[j] = es[j].since
+			keys[j] = es[j].message.PartitionKey
+		}
+		levels[i] = level{priority: p, since: since, keys: keys}
+	}
+	picks := schedule(levels, n, now, f.aging, blocked)
+	es := make([]*logEntry[T], len(picks))
+	for i, pk := range picks {
+		es[i] = f.levels[priorities[pk.level]][pk.index]
+	}
+	return es
+}
+
+// leasedKeys returns the partition keys of the leased entries.
+func (f *LogQueue[T]) leasedKeys() map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, l := range f.leases {
+		if key := f.entries[l.id].message.PartitionKey; key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// take removes a visible entry.
+func (f *LogQueue[T]) take(e *logEntry[T]) {
+	es := f.levels[e.message.Priority]
+	i := slices.Index(es, e)
+	if i < 0 {
+		return
+	}
+	es = slices.Delete(es, i, i+1)
+	if len(es) == 0 {
+		delete(f.levels, e.message.Priority)
+		return
+	}
+	f.levels[e.message.Priority] = es
+}
+
+// restore makes an entry visible keeping its position in the queue.
+func (f *LogQueue[T]) restore(e *logEntry[T]) {
+	es := f.levels[e.message.Priority]
+	i := sort.Search(len(es), func(i int) bool { return before(e, es[i]) })
+	f.levels[e.message.Priority] = slices.Insert(es, i, e)
+}
+
+// releaseExpired makes visible again the items whose lease has expired.
+func (f *LogQueue[T]) releaseExpired(now time.Time) error {
+	for receipt, l := range f.leases {
+		if now.After(l.visibleAt) {
+			e := f.entries[l.id]
+			e.message.fail(l.reason)
+			if l.reason != "" {
+				if err := f.rewrite(e); err != nil {
+					return err
+				}
+			}
+			delete(f.leases, receipt)
+			f.restore(e)
+		}
+	}
+	return nil
+}
+
+// promoteDue makes visible the delayed entries that are due, in NotBefore order.
+func (f *LogQueue[T]) promoteDue(now time.Time) {
+	n := 0
+	for n < len(f.delayed) && f.delayed[n].message.due(now) {
+		e := f.delayed[n]
+		e.since = e.message.NotBefore
+		f.restore(e)
+		n++
+	}
+	f.delayed = f.delayed[n:]
+}
+
+// read returns the messages of the entries with their data.
+func (f *LogQueue[T]) read(es []*logEntry[T]) ([]Message[T], error) {
+	ms := make([]Message[T], len(es))
+	for i, e := range es {
+		r, err := f.readRecord(e.loc)
+		if err != nil {
+			return nil, err
+		}
+		m := e.message
+		m.Data = r.Message.Data
+		ms[i] = m
+	}
+	return ms, nil
+}
+
+// rewrite appends a new version of the entry with its current metadata.
+func (f *LogQueue[T]) rewrite(e *logEntry[T]) error {
+	r, err := f.readRecord(e.loc)
+	if err != nil {
+		return err
+	}
+	m := e.message
+	m.Data = r.Message.Data
+	return f.write(e, m)
+}
+
+func (f *LogQueue[T]) write(e *logEntry[T], m Message[T]) error {
+	loc, err := f.append(logRecord[T]{ID: e.id, Since: e.since, Message: m})
+	if err != nil {
+		return err
+	}
+	var empty T
+	e.loc = loc
+	e.message = m
+	e.message.Data = empty
+	return nil
+}
+
+func (f *LogQueue[T]) append(r logRecord[T]) (location, error) {
+	locs, err := f.appendAll([]logRecord[T]{r})
+	if err != nil {
+		return location{}, err
+	}
+	return locs[0], nil
+}
+
+// appendAll writes the records at once. They are never split across segments.
+func (f *LogQueue[T]) appendAll(rs []logRecord[T]) ([]location, error) {
+	frames, sizes, err := encodeRecords(rs)
+	if err != nil {
+		return nil, err
+	}
+	if f.size > 0 && f.size+int64(len(frames)) > f.option.SegmentSize {
+		if err := f.roll(); err != nil {
+			return nil, err
+		}
+		if err := f.relocate(); err != nil {
+			return nil, err
+		}
+		if err := f.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return f.writeFrames(frames, sizes)
+}
+
+// writeFrames writes the encoded records to the active segment and returns their locations.
+func (f *LogQueue[T]) writeFrames(frames []byte, sizes []int64) ([]location, error) {
+	if _, err := f.files[f.active].Write(frames); err != nil {
+		return nil, err
+	}
+	locs := make([]location, len(sizes))
+	for i, size := range sizes {
+		locs[i] = location{segment: f.active, position: f.size, size: size}
+		f.size += size
+	}
+	return locs, f.flush(time.Now())
+}
+
+func (f *LogQueue[T]) flush(now time.Time) error {
+	switch f.option.Sync {
+	case SyncAlways:
+		return f.files[f.active].Sync()
+	case SyncInterval:
+		if now.Sub(f.lastSync) >= f.option.SyncInterval {
+			f.lastSync = now
+			return f.files[f.active].Sync()
+		}
+	}
+	return nil
+}
+
+func (f *LogQueue[T]) readRecord(loc location) (logRecord[T], error) {
+	var r logRecord[T]
+	file, ok := f.files[loc.segment]
+	if !ok {
+		return r, fmt.Errorf("segment %d not found", loc.segment)
+	}
+	frame := make([]byte, loc.size)
+	if _, err := file.ReadAt(frame, loc.position); err != nil {
+		return r, err
+	}
+	return decodeRecord[T](frame[:recordHeaderSize], frame[recordHeaderSize:])
+}
+
+// roll starts a new segment.
+func (f *LogQueue[T]) roll() error {
+	if file, ok := f.files[f.active]; ok && f.option.Sync != SyncNever {
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+	return f.openSegment(f.active + 1)
+}
+
+func (f *LogQueue[T]) openSegment(segment uint64) error {
+	file, err := os.OpenFile(segmentName(f.directory, segment), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Join(err, file.Close())
+	}
+	f.files[segment] = file
+	f.segments = append(f.segments, segment)
+	f.active = segment
+	f.size = info.Size()
+	return nil
+}
+
+// relocate copies the records of the items still in the oldest segment to the active one, so the compaction
+// deletes the segment even if those items are never acknowledged. It is only done while at least half of the
+// log holds records that are no longer needed, so the items are not copied over and over when the queue grows:
+// the log stays within about twice the size of its items.
+func (f *LogQueue[T]) relocate() error {
+	oldest := f.segments[0]
+	if oldest == f.active {
+		return nil
+	}
+	var used, live int64
+	for _, segment := range f.segments {
+		info, err := f.files[segment].Stat()
+		if err != nil {
+			return err
+		}
+		used += info.Size()
+	}
+	es := make([]*logEntry[T], 0)
+	for _, e := range f.entries {
+		live += e.loc.size
+		if e.loc.segment == oldest {
+			es = append(es, e)
+		}
+	}
+	if used <= 2*live+f.option.SegmentSize || len(es) == 0 {
+		return nil
+	}
+	slices.SortFunc(es, func(a, b *logEntry[T]) int { return cmp.Compare(a.loc.position, b.loc.position) })
+	rs := make([]logRecord[T], len(es))
+	for i, e := range es {
+		r, err := f.readRecord(e.loc)
+		if err != nil {
+			return err
+		}
+		// the batch of the record was already applied.
+		r.Pending = 0
+		rs[i] = r
+	}
+	frames, sizes, err := encodeRecords(rs)
+	if err != nil {
+		return err
+	}
+	locs, err := f.writeFrames(frames, sizes)
+	if err != nil {
+		return err
+	}
+	for i, e := range es {
+		e.loc = locs[i]
+	}
+	return nil
+}
+
+// compact persists the read offset and deletes the segments before it.
+func (f *LogQueue[T]) compact() error {
+	offset := logOffset{Segment: f.active, Position: f.size, NextID: f.nextID}
+	for _, e := range f.entries {
+		if e.loc.segment < offset.Segment || (e.loc.segment == offset.Segment && e.loc.position < offset.Position) {
+			offset.Segment = e.loc.segment
+			offset.Position = e.loc.position
+		}
+	}
+	if err := f.writeOffset(offset); err != nil {
+		return err
+	}
+	for len(f.segments) > 0 && f.segments[0] < offset.Segment {
+		segment := f.segments[0]
+		if err := f.files[segment].Close(); err != nil {
+			return err
+		}
+		delete(f.files, segment)
+		if err := os.Remove(segmentName(f.directory, segment)); err != nil {
+			return err
+		}
+		f.segments = f.segments[1:]
+	}
+	return nil
+}
+
+func (f *LogQueue[T]) writeOffset(offset logOffset) error {
+	b, err := json.Marshal(offset)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(f.directory, offsetFile)
+	file, err := os.Create(filename + ".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(b)
+	if err == nil && f.option.Sync != SyncNever {
+		err = file.Sync()
+	}
+	if err = errors.Join(err, file.Close()); err != nil {
+		return err
+	}
+	return os.Rename(filename+".tmp", filename)
+}
+
+// load replays the segments from the read offset. The last version of every item wins and
+// the acknowledged ones are dropped. A torn record at the end of the last segment is truncated.
+func (f *LogQueue[T]) load() error {
+	var offset logOffset
+	b, err := os.ReadFile(filepath.Join(f.directory, offsetFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &offset); err != nil {
+			return err
+		}
+	}
+	segments, err := listSegments(f.directory)
+	if err != nil {
+		return err
+	}
+	latest := make(map[uint64]*logEntry[T])
+	f.nextID = offset.NextID
+	for i, segment := range segments {
+		if segment < offset.Segment {
+			// it was compacted but not deleted.
+			if err := os.Remove(segmentName(f.directory, segment)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := f.openSegment(segment); err != nil {
+			return err
+		}
+		start := int64(0)
+		if segment == offset.Segment {
+			start = offset.Position
+		}
+		apply := func(r logRecord[T], loc location) {
+			f.nextID = max(f.nextID, r.ID+1)
+			if r.Deleted {
+				delete(latest, r.ID)
+				return
+			}
+			var empty T
+			r.Message.Data = empty
+			latest[r.ID] = &logEntry[T]{id: r.ID, loc: loc, since: r.Since, message: r.Message}
+		}
+		var batch []batchedRecord[T]
+		end, err := f.scan(segment, start, func(r logRecord[T], loc location) {
+			if r.Pending > 0 {
+				batch = append(batch, batchedRecord[T]{record: r, loc: loc})
+				return
+			}
+			for _, b := range batch {
+				apply(b.record, b.loc)
+			}
+			batch = batch[:0]
+			apply(r, loc)
+		})
+		if err != nil && (!errors.Is(err, ErrCorruptedLog) || i < len(segments)-1) {
+			return err
+		}
+		torn := err != nil
+		if len(batch) > 0 {
+			// the write of the batch was torn, so none of its records are applied.
+			end = batch[0].loc.position
+			torn = true
+		}
+		if torn && i == len(segments)-1 {
+			if err := f.files[segment].Truncate(end); err != nil {
+				return err
+			}
+			f.size = end
+		}
+	}
+	if len(f.segments) == 0 {
+		if err := f.openSegment(max(offset.Segment, 1)); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	for _, e := range latest {
+		f.entries[e.id] = e
+		if !e.message.due(now) {
+			f.delayed = append(f.delayed, e)
+			continue
+		}
+		if e.message.NotBefore.After(e.since) {
+			e.since = e.message.NotBefore
+		}
+		f.levels[e.message.Priority] = append(f.levels[e.message.Priority], e)
+	}
+	for _, es := range f.levels {
+		slices.SortFunc(es, func(a, b *logEntry[T]) int { return compare(a, b) })
+	}
+	slices.SortFunc(f.delayed, func(a, b *logEntry[T]) int { return a.message.NotBefore.Compare(b.message.NotBefore) })
+	return nil
+}
+
+// encodeRecords returns the frames of the records, each one a header with the size and the checksum of its
+// payload, and their sizes.
+func encodeRecords[T any](rs []logRecord[T]) ([]byte, []int64, error) {
+	var frames []byte
+	sizes := make([]int64, len(rs))
+	for i, r := range rs {
+		var buffer bytes.Buffer
+		if err := gob.NewEncoder(&buffer).Encode(r); err != nil {
+			return nil, nil, errors.Join(errors.New("error encoding"), err)
+		}
+		payload := buffer.Bytes()
+		header := make([]byte, recordHeaderSize)
+		binary.LittleEndian.PutUint32(header[0:4], uint32(len(payload)))
+		binary.LittleEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+		frames = append(append(frames, header...), payload...)
+		sizes[i] = int64(recordHeaderSize + len(payload))
+	}
+	return frames, sizes, nil
+}
+
+// scan reads the records of a segment from start and returns the position after the last valid one.
+func (f *LogQueue[T]) scan(segment uint64, start int64, fn func(logRecord[T], location)) (int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(f.files[segment], start, math.MaxInt64-start))
+	position := start
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return position, nil
+			}
+			return position, errors.Join(ErrCorruptedLog, err)
+		}
+		payload := make([]byte, binary.LittleEndian.Uint32(header[0:4]))
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return position, errors.Join(ErrCorruptedLog, err)
+		}
+		r, err := decodeRecord[T](header, payload)
+		if err != nil {
+			return position, err
+		}
+		size := int64(recordHeaderSize + len(payload))
+		fn(r, location{segment: segment, position: position, size: size})
+		position += size
+	}
+}
+
+func decodeRecord[T any](header []byte, payload []byte) (logRecord[T], error) {
+	var r logRecord[T]
+	if binary.LittleEndian.Uint32(header[4:8]) != crc32.ChecksumIEEE(payload) {
+		return r, ErrCorruptedLog
+	}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&r); err != nil {
+		return r, errors.Join(ErrCorruptedLog, err)
+	}
+	return r, nil
+}
+
+func listSegments(directory string) ([]uint64, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]uint64, 0)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), segmentSuffix) {
+			continue
+		}
+		segment, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	slices.Sort(segments)
+	return segments, nil
+}
+
+func segmentName(directory string, segment uint64) string {
+	return filepath.Join(directory, fmt.Sprintf("%020d%s", segment, segmentSuffix))
+}
+
+// before orders the entries by the time they are waiting and then by arrival.
+func before[T any](a *logEntry[T], b *logEntry[T]) bool {
+	return compare(a, b) < 0
+}
+
+func compare[T any](a *logEntry[T], b *logEntry[T]) int {
+	if c := a.since.Compare(b.since); c != 0 {
+		return c
+	}
+	switch {
+	case a.id < b.id:
+		return -1
+	case a.id > b.id:
+		return 1
+	}
+	return 0
+}
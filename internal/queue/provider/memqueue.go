@@ -1,28 +1,320 @@
 package provider
 
 import (
-	"github.com/andrescosta/goico/pkg/collection"
+	"cmp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
 )
 
 type MemBasedQueue[T any] struct {
-	q *collection.SyncQueue[T]
+	mutex sync.Mutex
+	seq   uint64
+	aging time.Duration
+	// levels holds the visible items of every priority, in arrival order.
+	levels map[uint32][]memEntry[T]
+	leases map[string]memLease[T]
+	// delayed is sorted by NotBefore.
+	delayed []Message[T]
 }
 
-func NewMemBasedQueue[T any]() (*MemBasedQueue[T], error) {
+type memEntry[T any] struct {
+	seq     uint64
+	since   time.Time
+	message Message[T]
+}
+
+type memLease[T any] struct {
+	entry     memEntry[T]
+	visibleAt time.Time
+	// reason is the failure recorded when the lease expires.
+	reason string
+}
+
+// NewMemBasedQueue returns a queue kept in memory. aging is the waiting time after which an item is
+// served as if it had one more level of priority.
+func NewMemBasedQueue[T any](aging time.Duration) (*MemBasedQueue[T], error) {
 	return &MemBasedQueue[T]{
-		q: collection.NewSyncQueue[T](),
+		aging:   aging,
+		levels:  make(map[uint32][]memEntry[T]),
+		leases:  make(map[string]memLease[T]),
+		delayed: make([]Message[T], 0),
 	}, nil
 }
 
-func (f *MemBasedQueue[T]) Add(data T) error {
-	f.q.Queue(data)
+func (f *MemBasedQueue[T]) Add(m Message[T]) error {
+	return f.AddAll([]Message[T]{m})
+}
+
+func (f *MemBasedQueue[T]) AddAll(ms []Message[T]) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	f.promoteDue(now)
+	for _, m := range ms {
+		if !m.due(now) {
+			i := sort.Search(len(f.delayed), func(i int) bool { return f.delayed[i].NotBefore.After(m.NotBefore) })
+			f.delayed = slices.Insert(f.delayed, i, m)
+			continue
+		}
+		f.append(m, now)
+	}
 	return nil
 }
 
-func (f *MemBasedQueue[T]) Remove() ([]T, error) {
-	if f.q.Size() == 0 {
-		var t []T
+func (f *MemBasedQueue[T]) Lease(n int, visibility time.Duration) ([]Leased[T], error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	f.releaseExpired(now)
+	f.promoteDue(now)
+	es := f.next(min(n, MaxItems), now, true)
+	if len(es) == 0 {
+		var t []Leased[T]
 		return t, ErrQueueEmpty
 	}
-	return f.q.DequeueN(MaxItems), nil
+	ls := make([]Leased[T], len(es))
+	visibleAt := now.Add(visibility)
+	for i, e := range es {
+		receipt, err := NewID()
+		if err != nil {
+			return nil, err
+		}
+		e.message.Attempts++
+		f.leases[receipt] = memLease[T]{entry: e, visibleAt: visibleAt, reason: ReasonLeaseExpired}
+		ls[i] = Leased[T]{Message: e.message, Receipt: receipt, VisibleAt: visibleAt}
+	}
+	return ls, nil
+}
+
+func (f *MemBasedQueue[T]) Ack(receipt string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.releaseExpired(time.Now())
+	if _, ok := f.leases[receipt]; !ok {
+		return ErrReceiptUnknown
+	}
+	delete(f.leases, receipt)
+	return nil
+}
+
+func (f *MemBasedQueue[T]) Nack(receipt string, reason string, delay time.Duration) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	f.releaseExpired(now)
+	l, ok := f.leases[receipt]
+	if !ok {
+		return ErrReceiptUnknown
+	}
+	if delay > 0 {
+		held, err := NewID()
+		if err != nil {
+			return err
+		}
+		delete(f.leases, receipt)
+		f.leases[held] = memLease[T]{entry: l.entry, visibleAt: now.Add(delay), reason: reason}
+		return nil
+	}
+	delete(f.leases, receipt)
+	l.entry.message.fail(reason)
+	f.restore(l.entry)
+	return nil
+}
+
+func (f *MemBasedQueue[T]) Peek(n int) ([]Message[T], error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	f.releaseExpired(now)
+	f.promoteDue(now)
+	es := f.next(n, now, false)
+	ms := make([]Message[T], len(es))
+	for i, e := range es {
+		ms[i] = e.message
+	}
+	return ms, nil
+}
+
+func (f *MemBasedQueue[T]) Purge() (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	n := len(f.leases) + len(f.delayed)
+	for _, es := range f.levels {
+		n += len(es)
+	}
+	f.levels = make(map[uint32][]memEntry[T])
+	f.leases = make(map[string]memLease[T])
+	f.delayed = make([]Message[T], 0)
+	return n, nil
+}
+
+func (f *MemBasedQueue[T]) Stats() (Stats, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	f.releaseExpired(now)
+	f.promoteDue(now)
+	s := Stats{}
+	// the size is the one the messages have when they are encoded by the persistent providers.
+	add := func(m Message[T]) error {
+		b, err := encode(m)
+		if err != nil {
+			return err
+		}
+		s.Bytes += int64(len(b))
+		return nil
+	}
+	for _, es := range f.levels {
+		for _, e := range es {
+			if err := add(e.message); err != nil {
+				return Stats{}, err
+			}
+			s.Visible++
+			s.waiting(e.since)
+		}
+	}
+	for _, l := range f.leases {
+		if err := add(l.entry.message); err != nil {
+			return Stats{}, err
+		}
+		s.Leased++
+		s.waiting(l.entry.since)
+	}
+	for _, m := range f.delayed {
+		if err := add(m); err != nil {
+			return Stats{}, err
+		}
+		s.Delayed++
+	}
+	return s, nil
+}
+
+// Export calls fn for every visible or leased item in arrival order, followed by the delayed ones in
+// NotBefore order.
+func (f *MemBasedQueue[T]) Export(fn func(Message[T]) error) error {
+	f.mutex.Lock()
+	es := make([]memEntry[T], 0, len(f.leases))
+	for _, l := range f.leases {
+		es = append(es, l.entry)
+	}
+	for _, level := range f.levels {
+		es = append(es, level...)
+	}
+	delayed := slices.Clone(f.delayed)
+	f.mutex.Unlock()
+	slices.SortFunc(es, func(a, b memEntry[T]) int { return cmp.Compare(a.seq, b.seq) })
+	for _, e := range es {
+		if err := fn(e.message); err != nil {
+			return err
+		}
+	}
+	for _, m := range delayed {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next returns up to n visible entries in the order they are served. If remove is set, they are removed
+// from the queue to be leased, skipping the entries that share a partition key with a leased one.
+func (f *MemBasedQueue[T]) next(n int, now time.Time, remove bool) []memEntry[T] {
+	var blocked map[string]struct{}
+	if remove {
+		blocked = f.leasedKeys()
+	}
+	priorities := make([]uint32, 0, len(f.levels))
+	for p := range f.levels {
+		priorities = append(priorities, p)
+	}
+	slices.Sort(priorities)
+	slices.Reverse(priorities)
+	levels := make([]level, len(priorities))
+	for i, p := range priorities {
+		es := f.levels[p]
+		size := min(n, len(es))
+		// the items of the leased keys are skipped, so the rest of the level may be needed.
+		if len(blocked) > 0 {
+			size = len(es)
+		}
+		since := make([]time.Time, size)
+		keys := make([]string, size)
+		for j := range since {
+			since[j] = es[j].since
+			keys[j] = es[j].message.PartitionKey
+		}
+		levels[i] = level{priority: p, since: since, keys: keys}
+	}
+	picks := schedule(levels, n, now, f.aging, blocked)
+	es := make([]memEntry[T], len(picks))
+	for i, pk := range picks {
+		es[i] = f.levels[priorities[pk.level]][pk.index]
+	}
+	if remove {
+		for _, e := range es {
+			f.take(e)
+		}
+	}
+	return es
+}
+
+// leasedKeys returns the partition keys of the leased entries.
+func (f *MemBasedQueue[T]) leasedKeys() map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, l := range f.leases {
+		if key := l.entry.message.PartitionKey; key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// take removes a visible entry.
+func (f *MemBasedQueue[T]) take(e memEntry[T]) {
+	es := f.levels[e.message.Priority]
+	i := slices.IndexFunc(es, func(o memEntry[T]) bool { return o.seq == e.seq })
+	if i < 0 {
+		return
+	}
+	es = slices.Delete(es, i, i+1)
+	if len(es) == 0 {
+		delete(f.levels, e.message.Priority)
+		return
+	}
+	f.levels[e.message.Priority] = es
+}
+
+// releaseExpired makes visible again the items whose lease has expired.
+func (f *MemBasedQueue[T]) releaseExpired(now time.Time) {
+	for receipt, l := range f.leases {
+		if now.After(l.visibleAt) {
+			delete(f.leases, receipt)
+			l.entry.message.fail(l.reason)
+			f.restore(l.entry)
+		}
+	}
+}
+
+// promoteDue appends the delayed messages that are due, in NotBefore order.
+func (f *MemBasedQueue[T]) promoteDue(now time.Time) {
+	n := 0
+	for n < len(f.delayed) && f.delayed[n].due(now) {
+		f.append(f.delayed[n], f.delayed[n].NotBefore)
+		n++
+	}
+	f.delayed = f.delayed[n:]
+}
+
+func (f *MemBasedQueue[T]) append(m Message[T], since time.Time) {
+	f.seq++
+	f.levels[m.Priority] = append(f.levels[m.Priority], memEntry[T]{seq: f.seq, since: since, message: m})
+}
+
+// restore puts back an entry keeping the original arrival order.
+func (f *MemBasedQueue[T]) restore(e memEntry[T]) {
+	es := f.levels[e.message.Priority]
+	i := sort.Search(len(es), func(i int) bool { return es[i].seq > e.seq })
+	f.levels[e.message.Priority] = slices.Insert(es, i, e)
 }
@@ -0,0 +1,616 @@
+package provider
+
+import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/rs/zerolog"
+)
+
+const (
+	keySeq     = 's'
+	keyVisible = 'v'
+	keyDelayed = 'd'
+	keyLeased  = 'l'
+)
+
+// PebbleStore is the database shared by the queues stored in Pebble.
+type PebbleStore struct {
+	db *pebble.DB
+}
+
+// PebbleQueue stores the items of a queue in a PebbleStore. Every item is keyed by the queue and a
+// monotonic sequence, under a different range for the visible, delayed and leased ones:
+//
+//	[queue]v[priority][seq] -> visible
+//	[queue]d[not before][seq] -> delayed
+//	[queue]l[seq] -> leased
+//
+// Every change is an atomic batch written with sync, so the queue survives crashes.
+type PebbleQueue[T any] struct {
+	db     *pebble.DB
+	prefix []byte
+	aging  time.Duration
+	mutex  sync.Mutex
+	seq    uint64
+	leases map[string]pebbleLease
+}
+
+type pebbleLease struct {
+	seq       uint64
+	key       string
+	visibleAt time.Time
+	// reason is the failure recorded when the lease expires.
+	reason string
+}
+
+type pebbleRecord[T any] struct {
+	Since   time.Time
+	Message Message[T]
+}
+
+type pebbleItem[T any] struct {
+	key    []byte
+	seq    uint64
+	record pebbleRecord[T]
+}
+
+type pebbleLog struct {
+	ctx context.Context
+}
+
+func (d *pebbleLog) Infof(format string, args ...interface{}) {
+	zerolog.Ctx(d.ctx).Debug().Msgf(format, args...)
+}
+
+func (d *pebbleLog) Fatalf(format string, args ...interface{}) {
+	zerolog.Ctx(d.ctx).Fatal().Msgf(format, args...)
+}
+
+// NewPebbleStore opens the database stored in dir. If inMemory is set, nothing is written to disk.
+func NewPebbleStore(ctx context.Context, dir string, inMemory bool) (*PebbleStore, error) {
+	opts := &pebble.Options{
+		Logger: &pebbleLog{ctx: ctx},
+	}
+	if inMemory {
+		opts.FS = vfs.NewMem()
+	}
+	db, err := pebble.Open(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &PebbleStore{db: db}, nil
+}
+
+func (s *PebbleStore) Close() error {
+	return s.db.Close()
+}
+
+// NewPebbleQueue returns the queue id of the store. aging is the waiting time after which an item is
+// served as if it had one more level of priority.
+func NewPebbleQueue[T any](store *PebbleStore, id string, aging time.Duration) (*PebbleQueue[T], error) {
+	prefix := binary.BigEndian.AppendUint16(nil, uint16(len(id)))
+	prefix = append(prefix, id...)
+	f := &PebbleQueue[T]{
+		db:     store.db,
+		prefix: prefix,
+		aging:  aging,
+		leases: make(map[string]pebbleLease),
+	}
+	seq, closer, err := f.db.Get(f.key(keySeq))
+	switch {
+	case err == nil:
+		f.seq = binary.BigEndian.Uint64(seq)
+		if err := closer.Close(); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, pebble.ErrNotFound):
+		return nil, err
+	}
+	// leases do not survive a restart, so the items leased by a previous instance become visible again.
+	if err := f.restoreLeased(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *PebbleQueue[T]) Add(m Message[T]) error {
+	return f.AddAll([]Message[T]{m})
+}
+
+func (f *PebbleQueue[T]) AddAll(ms []Message[T]) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	b := f.db.NewBatch()
+	defer b.Close()
+	seq := f.seq
+	for _, m := range ms {
+		seq++
+		key := f.visibleKey(m.Priority, seq)
+		if !m.due(now) {
+			key = f.delayedKey(m.NotBefore, seq)
+		}
+		if err := f.set(b, key, pebbleRecord[T]{Since: now, Message: m}); err != nil {
+			return err
+		}
+	}
+	if err := b.Set(f.key(keySeq), binary.BigEndian.AppendUint64(nil, seq), nil); err != nil {
+		return err
+	}
+	if err := b.Commit(pebble.Sync); err != nil {
+		return err
+	}
+	f.seq = seq
+	return nil
+}
+
+func (f *PebbleQueue[T]) Lease(n int, visibility time.Duration) ([]Leased[T], error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return nil, err
+	}
+	if err := f.promoteDue(now); err != nil {
+		return nil, err
+	}
+	items, err := f.next(min(n, MaxItems), now, f.leasedKeys())
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		var t []Leased[T]
+		return t, ErrQueueEmpty
+	}
+	b := f.db.NewBatch()
+	defer b.Close()
+	visibleAt := now.Add(visibility)
+	ls := make([]Leased[T], len(items))
+	receipts := make([]string, len(items))
+	for i, item := range items {
+		receipt, err := NewID()
+		if err != nil {
+			return nil, err
+		}
+		item.record.Message.Attempts++
+		if err := b.Delete(item.key, nil); err != nil {
+			return nil, err
+		}
+		if err := f.set(b, f.leasedKey(item.seq), item.record); err != nil {
+			return nil, err
+		}
+		receipts[i] = receipt
+		ls[i] = Leased[T]{Message: item.record.Message, Receipt: receipt, VisibleAt: visibleAt}
+	}
+	if err := b.Commit(pebble.Sync); err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		f.leases[receipts[i]] = pebbleLease{seq: item.seq, key: item.record.Message.PartitionKey, visibleAt: visibleAt, reason: ReasonLeaseExpired}
+	}
+	return ls, nil
+}
+
+func (f *PebbleQueue[T]) Ack(receipt string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.releaseExpired(time.Now()); err != nil {
+		return err
+	}
+	l, ok := f.leases[receipt]
+	if !ok {
+		return ErrReceiptUnknown
+	}
+	if err := f.db.Delete(f.leasedKey(l.seq), pebble.Sync); err != nil {
+		return err
+	}
+	delete(f.leases, receipt)
+	return nil
+}
+
+func (f *PebbleQueue[T]) Nack(receipt string, reason string, delay time.Duration) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return err
+	}
+	l, ok := f.leases[receipt]
+	if !ok {
+		return ErrReceiptUnknown
+	}
+	if delay > 0 {
+		held, err := NewID()
+		if err != nil {
+			return err
+		}
+		l.visibleAt, l.reason = now.Add(delay), reason
+		delete(f.leases, receipt)
+		f.leases[held] = l
+		return nil
+	}
+	if err := f.restore(l.seq, reason); err != nil {
+		return err
+	}
+	delete(f.leases, receipt)
+	return nil
+}
+
+func (f *PebbleQueue[T]) Peek(n int) ([]Message[T], error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return nil, err
+	}
+	if err := f.promoteDue(now); err != nil {
+		return nil, err
+	}
+	items, err := f.next(n, now, nil)
+	if err != nil {
+		return nil, err
+	}
+	ms := make([]Message[T], len(items))
+	for i, item := range items {
+		ms[i] = item.record.Message
+	}
+	return ms, nil
+}
+
+func (f *PebbleQueue[T]) Purge() (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	n := 0
+	b := f.db.NewBatch()
+	defer b.Close()
+	for _, kind := range []byte{keyVisible, keyDelayed, keyLeased} {
+		lower := f.key(kind)
+		upper := f.key(kind + 1)
+		err := f.scan(lower, upper, func(_ []byte, _ []byte) (bool, error) {
+			n++
+			return true, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		if err := b.DeleteRange(lower, upper, nil); err != nil {
+			return 0, err
+		}
+	}
+	if err := b.Commit(pebble.Sync); err != nil {
+		return 0, err
+	}
+	f.leases = make(map[string]pebbleLease)
+	return n, nil
+}
+
+// Archive writes every record of the queue, named by its key without the prefix of the queue.
+func (f *PebbleQueue[T]) Archive(w *tar.Writer) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	return f.scan(f.prefix, f.key(0xff), func(key []byte, value []byte) (bool, error) {
+		header := &tar.Header{
+			Name:    hex.EncodeToString(key[len(f.prefix):]),
+			Mode:    0o600,
+			Size:    int64(len(value)),
+			ModTime: now,
+		}
+		if err := w.WriteHeader(header); err != nil {
+			return false, err
+		}
+		_, err := w.Write(value)
+		return err == nil, err
+	})
+}
+
+// Export calls fn for every item in the order they were queued.
+func (f *PebbleQueue[T]) Export(fn func(Message[T]) error) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	items := make([]pebbleItem[T], 0)
+	for _, kind := range []byte{keyVisible, keyDelayed, keyLeased} {
+		err := f.scan(f.key(kind), f.key(kind+1), func(key []byte, value []byte) (bool, error) {
+			r, err := decodePebble[T](value)
+			if err != nil {
+				return false, err
+			}
+			// every key ends with the sequence of the item.
+			items = append(items, pebbleItem[T]{key: key, seq: binary.BigEndian.Uint64(key[len(key)-8:]), record: r})
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	slices.SortFunc(items, func(a, b pebbleItem[T]) int {
+		if c := a.record.Since.Compare(b.record.Since); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.seq, b.seq)
+	})
+	for _, item := range items {
+		if err := fn(item.record.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove deletes every record of the queue, including its sequence.
+func (f *PebbleQueue[T]) Remove() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.db.DeleteRange(f.prefix, f.key(0xff), pebble.Sync); err != nil {
+		return err
+	}
+	f.leases = make(map[string]pebbleLease)
+	return nil
+}
+
+func (f *PebbleQueue[T]) Stats() (Stats, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	now := time.Now()
+	if err := f.releaseExpired(now); err != nil {
+		return Stats{}, err
+	}
+	if err := f.promoteDue(now); err != nil {
+		return Stats{}, err
+	}
+	s := Stats{}
+	for _, kind := range []byte{keyVisible, keyDelayed, keyLeased} {
+		err := f.scan(f.key(kind), f.key(kind+1), func(_ []byte, value []byte) (bool, error) {
+			s.Bytes += int64(len(value))
+			switch kind {
+			case keyDelayed:
+				s.Delayed++
+				return true, nil
+			case keyLeased:
+				s.Leased++
+			default:
+				s.Visible++
+			}
+			r, err := decodePebble[T](value)
+			if err != nil {
+				return false, err
+			}
+			s.waiting(r.Since)
+			return true, nil
+		})
+		if err != nil {
+			return Stats{}, err
+		}
+	}
+	return s, nil
+}
+
+// next reads up to n visible items in the order they are served. If blocked is not nil, the items whose
+// partition key is in it are skipped.
+func (f *PebbleQueue[T]) next(n int, now time.Time, blocked map[string]struct{}) ([]pebbleItem[T], error) {
+	byLevel := make(map[uint32][]pebbleItem[T])
+	lower := f.key(keyVisible)
+	upper := f.key(keyVisible + 1)
+	for {
+		var priority uint32
+		found := false
+		err := f.scan(lower, upper, func(key []byte, value []byte) (bool, error) {
+			p, seq := f.parseVisibleKey(key)
+			if found && p != priority {
+				return false, nil
+			}
+			priority, found = p, true
+			r, err := decodePebble[T](value)
+			if err != nil {
+				return false, err
+			}
+			if _, ok := blocked[r.Message.PartitionKey]; ok && r.Message.PartitionKey != "" {
+				return true, nil
+			}
+			byLevel[p] = append(byLevel[p], pebbleItem[T]{key: key, seq: seq, record: r})
+			return len(byLevel[p]) < n, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !found || priority == 0 {
+			break
+		}
+		// levels are stored from the highest priority down, so the next one starts at priority-1.
+		lower = f.visibleKey(priority-1, 0)
+	}
+	priorities := make([]uint32, 0, len(byLevel))
+	for p := range byLevel {
+		priorities = append(priorities, p)
+	}
+	slices.Sort(priorities)
+	slices.Reverse(priorities)
+	levels := make([]level, len(priorities))
+	for i, p := range priorities {
+		since := make([]time.Time, len(byLevel[p]))
+		keys := make([]string, len(byLevel[p]))
+		for j, item := range byLevel[p] {
+			since[j] = item.record.Since
+			keys[j] = item.record.Message.PartitionKey
+		}
+		levels[i] = level{priority: p, since: since, keys: keys}
+	}
+	picks := schedule(levels, n, now, f.aging, blocked)
+	items := make([]pebbleItem[T], len(picks))
+	for i, pk := range picks {
+		items[i] = byLevel[priorities[pk.level]][pk.index]
+	}
+	return items, nil
+}
+
+// leasedKeys returns the partition keys of the leased items.
+func (f *PebbleQueue[T]) leasedKeys() map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, l := range f.leases {
+		if l.key != "" {
+			keys[l.key] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// releaseExpired makes visible again the items whose lease has expired.
+func (f *PebbleQueue[T]) releaseExpired(now time.Time) error {
+	for receipt, l := range f.leases {
+		if now.After(l.visibleAt) {
+			if err := f.restore(l.seq, l.reason); err != nil {
+				return err
+			}
+			delete(f.leases, receipt)
+		}
+	}
+	return nil
+}
+
+// promoteDue makes visible the delayed items that are due. They get a new sequence, so they are
+// positioned after the items that were visible before.
+func (f *PebbleQueue[T]) promoteDue(now time.Time) error {
+	b := f.db.NewBatch()
+	defer b.Close()
+	lower := f.key(keyDelayed)
+	upper := f.delayedKey(now, ^uint64(0))
+	err := f.scan(lower, upper, func(key []byte, value []byte) (bool, error) {
+		r, err := decodePebble[T](value)
+		if err != nil {
+			return false, err
+		}
+		r.Since = r.Message.NotBefore
+		f.seq++
+		if err := b.Delete(key, nil); err != nil {
+			return false, err
+		}
+		return true, f.set(b, f.visibleKey(r.Message.Priority, f.seq), r)
+	})
+	if err != nil {
+		return err
+	}
+	if b.Empty() {
+		return nil
+	}
+	if err := b.Set(f.key(keySeq), binary.BigEndian.AppendUint64(nil, f.seq), nil); err != nil {
+		return err
+	}
+	return b.Commit(pebble.Sync)
+}
+
+// restore records the delivery attempt and makes the leased item visible again in its original position.
+func (f *PebbleQueue[T]) restore(seq uint64, reason string) error {
+	key := f.leasedKey(seq)
+	value, closer, err := f.db.Get(key)
+	if err != nil {
+		return err
+	}
+	r, err := decodePebble[T](value)
+	if err := errors.Join(err, closer.Close()); err != nil {
+		return err
+	}
+	r.Message.fail(reason)
+	b := f.db.NewBatch()
+	defer b.Close()
+	if err := b.Delete(key, nil); err != nil {
+		return err
+	}
+	if err := f.set(b, f.visibleKey(r.Message.Priority, seq), r); err != nil {
+		return err
+	}
+	return b.Commit(pebble.Sync)
+}
+
+func (f *PebbleQueue[T]) restoreLeased() error {
+	b := f.db.NewBatch()
+	defer b.Close()
+	err := f.scan(f.key(keyLeased), f.key(keyLeased+1), func(key []byte, value []byte) (bool, error) {
+		r, err := decodePebble[T](value)
+		if err != nil {
+			return false, err
+		}
+		seq := binary.BigEndian.Uint64(key[len(key)-8:])
+		if err := b.Delete(key, nil); err != nil {
+			return false, err
+		}
+		return true, f.set(b, f.visibleKey(r.Message.Priority, seq), r)
+	})
+	if err != nil {
+		return err
+	}
+	return b.Commit(pebble.Sync)
+}
+
+// scan calls fn with a copy of every key and value in [lower, upper) until it returns false.
+func (f *PebbleQueue[T]) scan(lower []byte, upper []byte, fn func([]byte, []byte) (bool, error)) error {
+	iter, err := f.db.NewIter(&pebble.IterOptions{LowerBound: lower, UpperBound: upper})
+	if err != nil {
+		return err
+	}
+	for valid := iter.First(); valid; valid = iter.Next() {
+		value, err := iter.ValueAndErr()
+		if err != nil {
+			return errors.Join(err, iter.Close())
+		}
+		ok, err := fn(slices.Clone(iter.Key()), slices.Clone(value))
+		if err != nil {
+			return errors.Join(err, iter.Close())
+		}
+		if !ok {
+			break
+		}
+	}
+	return errors.Join(iter.Error(), iter.Close())
+}
+
+func (f *PebbleQueue[T]) set(b *pebble.Batch, key []byte, r pebbleRecord[T]) error {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(r); err != nil {
+		return errors.Join(errors.New("error encoding"), err)
+	}
+	return b.Set(key, buffer.Bytes(), nil)
+}
+
+func decodePebble[T any](value []byte) (pebbleRecord[T], error) {
+	var r pebbleRecord[T]
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&r); err != nil {
+		return r, errors.Join(errors.New("error decoding"), err)
+	}
+	return r, nil
+}
+
+func (f *PebbleQueue[T]) key(kind byte) []byte {
+	return append(slices.Clone(f.prefix), kind)
+}
+
+func (f *PebbleQueue[T]) visibleKey(priority uint32, seq uint64) []byte {
+	// the priority is inverted so the highest one comes first.
+	key := binary.BigEndian.AppendUint32(f.key(keyVisible), ^priority)
+	return binary.BigEndian.AppendUint64(key, seq)
+}
+
+func (f *PebbleQueue[T]) parseVisibleKey(key []byte) (uint32, uint64) {
+	key = key[len(f.prefix)+1:]
+	return ^binary.BigEndian.Uint32(key[:4]), binary.BigEndian.Uint64(key[4:])
+}
+
+func (f *PebbleQueue[T]) delayedKey(notBefore time.Time, seq uint64) []byte {
+	key := binary.BigEndian.AppendUint64(f.key(keyDelayed), uint64(notBefore.UnixNano()))
+	return binary.BigEndian.AppendUint64(key, seq)
+}
+
+func (f *PebbleQueue[T]) leasedKey(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(f.key(keyLeased), seq)
+}
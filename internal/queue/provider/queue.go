@@ -1,12 +1,206 @@
 package provider
 
-import "errors"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
 
-var ErrQueueEmpty = errors.New("queue empty")
+var (
+	ErrQueueEmpty         = errors.New("queue empty")
+	ErrReceiptUnknown     = errors.New("receipt unknown or lease expired")
+	ErrQuarantinedUnknown = errors.New("quarantined item unknown")
+)
 
-const MaxItems = 100
+const (
+	MaxItems           = 100
+	ReasonLeaseExpired = "lease expired"
+)
 
 type Queue[T any] interface {
-	Add(data T) error
-	Remove() ([]T, error)
+	Add(m Message[T]) error
+	// AddAll adds the messages atomically: either all of them are added or none is.
+	AddAll(ms []Message[T]) error
+	// Lease returns up to n of the next visible items, capped to MaxItems, and hides them from
+	// other consumers until they are acknowledged or the visibility timeout elapses.
+	Lease(n int, visibility time.Duration) ([]Leased[T], error)
+	Ack(receipt string) error
+	// Nack makes the item visible again recording the failure. An empty reason releases
+	// the item without counting the delivery attempt. If delay is positive, the item stays leased,
+	// blocking its partition key, until the delay elapses. Its receipt is no longer valid.
+	Nack(receipt string, reason string, delay time.Duration) error
+	Peek(n int) ([]Message[T], error)
+	Purge() (int, error)
+	Stats() (Stats, error)
+}
+
+// Quarantine is implemented by the providers that set aside the items they cannot decode, so they do not
+// block the rest of the queue. The quarantined items are kept until they are requeued or deleted.
+type Quarantine interface {
+	Quarantined() ([]Quarantined, error)
+	// RequeueQuarantined decodes the item again and makes it visible if it succeeds.
+	RequeueQuarantined(id string) error
+	DeleteQuarantined(id string) error
+}
+
+// Quarantined is an item that could not be decoded.
+type Quarantined struct {
+	ID string
+	// Data holds the bytes as they are stored by the provider.
+	Data []byte
+	// Error is the reason the item could not be decoded.
+	Error string
+	// Date is the time the item was quarantined.
+	Date time.Time
+}
+
+// Stats describes the items held by a queue.
+type Stats struct {
+	Visible int
+	Delayed int
+	Leased  int
+	// Quarantined is the number of items that could not be decoded. They are not part of the depth.
+	Quarantined int
+	// Bytes is the size of the items as they are stored by the provider.
+	Bytes int64
+	// Oldest is the time since the oldest visible or leased item is waiting to be delivered. It is zero
+	// if there are none.
+	Oldest time.Time
+}
+
+// Message is what the providers store: the payload plus the delivery metadata kept by the queue.
+type Message[T any] struct {
+	Data T
+	// NotBefore holds the message back until that time. The zero value means it is visible right away.
+	NotBefore time.Time
+	// Priority is the level the message is served from. Higher levels are served first.
+	Priority uint32
+	Attempts uint32
+	Failures []Failure
+	// Source is the queue the message was dead-lettered from.
+	Source string
+	// PartitionKey is shared by the messages that are leased one at a time, in the order they become visible.
+	PartitionKey string
+}
+
+type Failure struct {
+	Reason string
+	Date   time.Time
+}
+
+type Leased[T any] struct {
+	Message[T]
+	Receipt   string
+	VisibleAt time.Time
+}
+
+// level is a priority level of a queue: the time since its visible items are waiting, oldest first.
+type level struct {
+	priority uint32
+	since    []time.Time
+	// keys holds the partition keys of the items. It is only needed when the keys are blocked.
+	keys []string
+}
+
+// pick is an item chosen by schedule: the index of its level and its position in the level.
+type pick struct {
+	level int
+	index int
+}
+
+// schedule returns the order in which up to n items are served.
+// Higher priorities are served first, but an item gains one level for every aging interval it waits so
+// a steady flow of urgent items does not starve the lower levels. Zero aging means strict priorities.
+// Levels must be sorted from the highest priority to the lowest.
+// If blocked is not nil, the items whose partition key is in it are skipped and the keys of the chosen
+// items are added to it, so items sharing a key are never leased at the same time.
+func schedule(levels []level, n int, now time.Time, aging time.Duration, blocked map[string]struct{}) []pick {
+	heads := make([]int, len(levels))
+	picks := make([]pick, 0, n)
+	for len(picks) < n {
+		next := -1
+		var nextPriority uint64
+		for i, l := range levels {
+			for blocked != nil && heads[i] < len(l.since) && l.blocked(heads[i], blocked) {
+				heads[i]++
+			}
+			if heads[i] == len(l.since) {
+				continue
+			}
+			since := l.since[heads[i]]
+			p := effectivePriority(l.priority, since, now, aging)
+			if next < 0 || p > nextPriority || (p == nextPriority && since.Before(levels[next].since[heads[next]])) {
+				next, nextPriority = i, p
+			}
+		}
+		if next < 0 {
+			break
+		}
+		picks = append(picks, pick{level: next, index: heads[next]})
+		if blocked != nil {
+			if key := levels[next].key(heads[next]); key != "" {
+				blocked[key] = struct{}{}
+			}
+		}
+		heads[next]++
+	}
+	return picks
+}
+
+func (l level) key(i int) string {
+	if i < len(l.keys) {
+		return l.keys[i]
+	}
+	return ""
+}
+
+func (l level) blocked(i int, blocked map[string]struct{}) bool {
+	key := l.key(i)
+	if key == "" {
+		return false
+	}
+	_, ok := blocked[key]
+	return ok
+}
+
+func effectivePriority(priority uint32, since time.Time, now time.Time, aging time.Duration) uint64 {
+	p := uint64(priority)
+	if aging > 0 && now.After(since) {
+		p += uint64(now.Sub(since) / aging)
+	}
+	return p
+}
+
+// Depth is the number of items that were not acknowledged yet.
+func (s Stats) Depth() int {
+	return s.Visible + s.Delayed + s.Leased
+}
+
+// waiting records an item that is waiting since the given time.
+func (s *Stats) waiting(since time.Time) {
+	if s.Oldest.IsZero() || since.Before(s.Oldest) {
+		s.Oldest = since
+	}
+}
+
+func (m *Message[T]) due(now time.Time) bool {
+	return !m.NotBefore.After(now)
+}
+
+func (m *Message[T]) fail(reason string) {
+	if reason == "" {
+		m.Attempts--
+		return
+	}
+	m.Failures = append(m.Failures, Failure{Reason: reason, Date: time.Now()})
+}
+
+// NewID returns a random ID. It is used for the receipts of the leases and the IDs of the items.
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Storage is implemented by the providers that keep the items out of the memory, so they are not lost
+// when the queue is dropped.
+type Storage interface {
+	// Archive writes the stored items to w, as they are stored.
+	Archive(w *tar.Writer) error
+	// Remove deletes the stored items. The queue cannot be used after that.
+	Remove() error
+}
+
+// Exporter is implemented by the providers whose items can be copied to another provider.
+type Exporter[T any] interface {
+	// Export calls fn for every item, visible, delayed or leased, in the order they were queued. It stops
+	// at the first error returned by fn.
+	Export(fn func(Message[T]) error) error
+}
+
+// archiveDirectory writes the files of a directory to w.
+func archiveDirectory(w *tar.Writer, directory string) error {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		if err := w.WriteHeader(header); err != nil {
+			return err
+		}
+		file, err := os.Open(filepath.Join(directory, e.Name()))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, file)
+		if errC := file.Close(); err == nil {
+			err = errC
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
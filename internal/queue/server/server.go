@@ -27,10 +27,66 @@ func (s *Server) Close() error {
 	return s.controller.Close()
 }
 
-func (s *Server) Queue(_ context.Context, in *pb.QueueRequest) (*pb.Void, error) {
+func (s *Server) Queue(_ context.Context, in *pb.QueueRequest) (*pb.QueueReply, error) {
 	return s.controller.Queue(in)
 }
 
-func (s *Server) Dequeue(_ context.Context, in *pb.DequeueRequest) (*pb.DequeueReply, error) {
-	return s.controller.Dequeue(in)
+func (s *Server) Dequeue(ctx context.Context, in *pb.DequeueRequest) (*pb.DequeueReply, error) {
+	return s.controller.Dequeue(ctx, in)
+}
+
+func (s *Server) Ack(_ context.Context, in *pb.AckRequest) (*pb.Void, error) {
+	return s.controller.Ack(in)
+}
+
+func (s *Server) Nack(_ context.Context, in *pb.NackRequest) (*pb.Void, error) {
+	return s.controller.Nack(in)
+}
+
+func (s *Server) DeadLetters(_ context.Context, in *pb.DeadLettersRequest) (*pb.DeadLettersReply, error) {
+	return s.controller.DeadLetters(in)
+}
+
+func (s *Server) RequeueDeadLetters(_ context.Context, in *pb.RequeueDeadLettersRequest) (*pb.RequeueDeadLettersReply, error) {
+	return s.controller.RequeueDeadLetters(in)
+}
+
+func (s *Server) PurgeDeadLetters(_ context.Context, in *pb.PurgeDeadLettersRequest) (*pb.PurgeDeadLettersReply, error) {
+	return s.controller.PurgeDeadLetters(in)
+}
+
+func (s *Server) Subscribe(in *pb.SubscribeRequest, stream pb.Queue_SubscribeServer) error {
+	return s.controller.Subscribe(in, stream)
+}
+
+func (s *Server) Stats(_ context.Context, in *pb.StatsRequest) (*pb.StatsReply, error) {
+	return s.controller.Stats(in)
+}
+
+func (s *Server) Peek(_ context.Context, in *pb.PeekRequest) (*pb.PeekReply, error) {
+	return s.controller.Peek(in)
+}
+
+func (s *Server) Purge(_ context.Context, in *pb.PurgeRequest) (*pb.PurgeReply, error) {
+	return s.controller.Purge(in)
+}
+
+func (s *Server) Quarantined(_ context.Context, in *pb.QuarantinedRequest) (*pb.QuarantinedReply, error) {
+	return s.controller.Quarantined(in)
+}
+
+func (s *Server) RequeueQuarantined(_ context.Context, in *pb.RequeueQuarantinedRequest) (*pb.Void, error) {
+	return s.controller.RequeueQuarantined(in)
+}
+
+func (s *Server) DeleteQuarantined(_ context.Context, in *pb.DeleteQuarantinedRequest) (*pb.Void, error) {
+	return s.controller.DeleteQuarantined(in)
+}
+
+func (s *Server) Replicate(_ context.Context, in *pb.ReplicateRequest) (*pb.Void, error) {
+	return s.controller.Replicate(in)
+}
+
+func (s *Server) Promote(_ context.Context, _ *pb.Void) (*pb.Void, error) {
+	return s.controller.Promote()
 }
@@ -115,6 +115,7 @@ func (l *FileLogRecorder) AddExecution(ex *pb.JobExecution) error {
 		Str("Queue", ex.Queue).
 		Uint64("Code", ex.Result.Code).
 		Str("Result", ex.Result.Message).
+		Uint32("Attempt", ex.Attempt).
 		Send()
 	return nil
 }
@@ -86,6 +86,7 @@ func (m *MemRecorder) AddExecution(ex *pb.JobExecution) error {
 		Str("Queue", ex.Queue).
 		Uint64("Code", ex.Result.Code).
 		Str("Result", ex.Result.Message).
+		Uint32("Attempt", ex.Attempt).
 		Send()
 	return nil
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/andrescosta/goico/pkg/test"
 	"github.com/andrescosta/jobico/internal/api/client"
 	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/listener"
 )
 
 type event struct {
@@ -37,6 +38,7 @@ type eventTenantV2 struct {
 
 type errSend struct {
 	StatusCode int
+	RetryAfter string
 }
 
 type testClient struct {
@@ -58,6 +60,7 @@ type Result struct {
 	Queue        string `json:"Queue"`
 	Code         int    `json:"Code"`
 	ResultString string `json:"Result"`
+	Attempt      int    `json:"Attempt"`
 	ResultJSON   eventTenantV1
 }
 
@@ -124,6 +127,60 @@ func newErrorTestPackage() *pb.JobPackage {
 	return newPackage(SchemaRefIDs{"sch1", "sch1_ok", "sch1_error"}, "runerror1")
 }
 
+// newWorkflowTestPackage returns a package with a workflow that starts with event_id_1, fans out to two
+// branches that are joined, and routes to notify or skip depending on the lastName of the event.
+func newWorkflowTestPackage() *pb.JobPackage {
+	p := newTestPackage()
+	p.Runtimes = append(p.Runtimes, &pb.RuntimeDef{
+		ID:           "runtime_error_1",
+		ModuleRef:    "runerror1",
+		MainFuncName: strptr("event"),
+		Type:         pb.RuntimeType_Wasm10,
+		Platform:     pb.Platform_TinyGO.Enum(),
+	})
+	steps := map[string]string{
+		"fetch":  "runtime_id_1",
+		"check":  "runtime_error_1",
+		"join":   "runtime_id_1",
+		"notify": "runtime_id_1",
+		"skip":   "runtime_id_1",
+	}
+	for step, runtime := range steps {
+		p.Jobs = append(p.Jobs, &pb.JobDef{
+			Event: &pb.EventDef{
+				ID:            "event_" + step,
+				DataType:      pb.DataType_Json,
+				SupplierQueue: "queue_id_1",
+				Runtime:       runtime,
+				Schema:        &pb.SchemaDef{SchemaRef: "sch1"},
+			},
+		})
+	}
+	var zero, minError, maxError uint64 = 0, 500, 599
+	p.Workflows = []*pb.WorkflowDef{
+		{
+			ID: "wf",
+			Steps: []*pb.StepDef{
+				{
+					ID:    "start",
+					Event: "event_id_1",
+					Routes: []*pb.RouteDef{
+						{MinCode: &zero, MaxCode: &zero, Next: []string{"fetch", "check"}},
+						{JsonPath: strptr("$.lastName"), Equals: strptr("connor"), Next: []string{"notify"}},
+						{JsonPath: strptr("$['lastName']"), Equals: strptr("smith"), Next: []string{"skip"}},
+					},
+				},
+				{ID: "fetch", Event: "event_fetch", Routes: []*pb.RouteDef{{Next: []string{"join"}}}},
+				{ID: "check", Event: "event_check", Routes: []*pb.RouteDef{{MinCode: &minError, MaxCode: &maxError, Next: []string{"join"}}}},
+				{ID: "join", Event: "event_join", Join: true},
+				{ID: "notify", Event: "event_notify"},
+				{ID: "skip", Event: "event_skip"},
+			},
+		},
+	}
+	return p
+}
+
 func newPackage(schemaRefIDs SchemaRefIDs, runtimeRef string) *pb.JobPackage {
 	p := pb.JobPackage{
 		ID:     "job_id_1",
@@ -298,20 +355,31 @@ func (e errSend) Error() string {
 }
 
 func (s *testClient) sendEvent(url *url.URL, e []byte) error {
+	_, err := s.sendEventWithHeader(url, e, http.Header{})
+	return err
+}
+
+// sendEventWithHeader returns the IDs assigned to the events.
+func (s *testClient) sendEventWithHeader(url *url.URL, e []byte, header http.Header) ([]string, error) {
 	r := &http.Request{
 		Method: "POST",
 		URL:    url,
+		Header: header,
 		Body:   io.NopCloser(bytes.NewReader(e)),
 	}
 	re, err := s.httpClient.Do(r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer re.Body.Close()
 	if re.StatusCode != http.StatusOK {
-		return errSend{StatusCode: re.StatusCode}
+		return nil, errSend{StatusCode: re.StatusCode, RetryAfter: re.Header.Get(listener.RetryAfterHeader)}
 	}
-	return nil
+	reply := listener.PostReply{}
+	if err := json.NewDecoder(re.Body).Decode(&reply); err != nil {
+		return nil, err
+	}
+	return reply.IDs, nil
 }
 
 func (s *testClient) addTenant(tenant string) error {
@@ -337,7 +405,7 @@ func (s *testClient) AllPackages() ([]*pb.JobPackage, error) {
 	return t, nil
 }
 
-func (s *testClient) dequeue(tenant string, queue string) ([]*pb.QueueItem, error) {
+func (s *testClient) dequeue(tenant string, queue string) ([]*pb.DequeuedItem, error) {
 	ctx, cancel := context.WithTimeout(s.ctx, 50*time.Second)
 	defer cancel()
 	for {
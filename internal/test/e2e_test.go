@@ -3,17 +3,35 @@ package test
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/andrescosta/goico/pkg/env"
+	"github.com/andrescosta/goico/pkg/service"
 	"github.com/andrescosta/goico/pkg/test"
+	queuesvc "github.com/andrescosta/jobico/cmd/queue/service"
+	"github.com/andrescosta/jobico/internal/api/client"
 	pb "github.com/andrescosta/jobico/internal/api/types"
+	"github.com/andrescosta/jobico/internal/executor/wasm"
+	"github.com/andrescosta/jobico/internal/listener"
+	queuectl "github.com/andrescosta/jobico/internal/queue/controller"
+	"github.com/andrescosta/jobico/internal/queue/provider"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const sendEventURL = "http://listener:1/events/%s/%s"
@@ -34,12 +52,23 @@ var (
 	//go:embed testdata/error.wasm
 	wasmError []byte
 
+	//go:embed testdata/sleeper.wasm
+	wasmSleeper []byte
+
+	// emitter.wasm emits its event twice to event_emitted, once to an unknown event and once an
+	// invalid event to event_emitted. It returns the status of the last two emits as 10*unknown+invalid.
+	// Its source is testdata/emitter.wat.
+	//go:embed testdata/emitter.wasm
+	wasmEmitter []byte
+
 	files = map[string][]byte{
-		"sch1":       schemaV1,
-		"sch1_ok":    schemaV1Ok,
-		"sch1_error": schemaV1Error,
-		"run1":       wasmEcho,
-		"runerror1":  wasmError,
+		"sch1":        schemaV1,
+		"sch1_ok":     schemaV1Ok,
+		"sch1_error":  schemaV1Error,
+		"run1":        wasmEcho,
+		"runerror1":   wasmError,
+		"runsleeper1": wasmSleeper,
+		"runemitter1": wasmEmitter,
 	}
 
 	//go:embed testdata/schema_updated.json
@@ -116,6 +145,353 @@ func TestWasmError(t *testing.T) {
 	sendEvtV1AndValidateError(t, pkg, cli)
 }
 
+func TestJobRetry(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newErrorTestPackage()
+	pkg.Jobs[0].Retry = &pb.RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoffMillis: 50,
+		MaxBackoffMillis:     80,
+		RetryableCodes:       []uint64{500},
+	}
+	addPackageAndFiles(t, cli, pkg)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	err = sendEvtV1(pkg, cli)
+	test.Nil(t, err)
+	// the error result is only sent after the last attempt.
+	res, err := cli.dequeue(pkg.Tenant, "queue_id_1_error")
+	test.Nil(t, err)
+	test.Len(t, res, 1)
+	results, err := cli.getJobExecutions(pkg, 6)
+	test.Nil(t, err)
+	attempts := make([]int, 0)
+	for _, r := range results {
+		if r.TypeResult == strings.ToLower(pb.JobResult_Result.String()) {
+			test.Equals(t, r.Code, 500)
+			attempts = append(attempts, r.Attempt)
+		}
+	}
+	slices.Sort(attempts)
+	test.Equals(t, attempts, []int{1, 2, 3})
+}
+
+func TestResultPayload(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	pkg.Jobs[0].Result.Payload = pb.ResultDef_Envelope
+	addPackageAndFiles(t, cli, pkg)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	u, err := url.Parse(fmt.Sprintf(sendEventURL, pkg.Tenant, pkg.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	evt, err := cli.sendEventV1(u)
+	test.Nil(t, err)
+	res, err := cli.dequeue(pkg.Tenant, "queue_id_1_ok")
+	test.Nil(t, err)
+	test.Len(t, res, 1)
+	test.Equals(t, res[0].Item.Event, "event_id_1_ok")
+	var envelope struct {
+		Tenant  string        `json:"tenant"`
+		Event   string        `json:"event"`
+		Code    uint64        `json:"code"`
+		Attempt uint32        `json:"attempt"`
+		Result  eventTenantV1 `json:"result"`
+		Input   eventTenantV1 `json:"input"`
+	}
+	err = json.Unmarshal(res[0].Item.Data, &envelope)
+	test.Nil(t, err)
+	test.Equals(t, envelope.Tenant, pkg.Tenant)
+	test.Equals(t, envelope.Event, "event_id_1")
+	test.Equals(t, envelope.Code, uint64(0))
+	test.Equals(t, envelope.Attempt, uint32(1))
+	// the Jobicolet echoes the event.
+	test.Equals(t, envelope.Result, evt)
+	test.Equals(t, envelope.Input, evt)
+}
+
+func TestWorkflow(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newWorkflowTestPackage()
+	addPackageAndFiles(t, cli, pkg)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	u, err := url.Parse(fmt.Sprintf(sendEventURL, pkg.Tenant, pkg.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	evt := eventTenantV1{"john", "connor", 50}
+	b, err := json.Marshal(event{[]interface{}{evt}})
+	test.Nil(t, err)
+	ids, err := cli.sendEventWithHeader(u, b, http.Header{})
+	test.Nil(t, err)
+	test.Len(t, ids, 1)
+	var instance *pb.WorkflowInstance
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		instance, err = cli.ctl.WorkflowInstance(ctx, pkg.Tenant, "wf", ids[0])
+		test.Nil(t, err)
+		if instance != nil && instance.Status == pb.WorkflowInstance_Completed {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	test.NotNil(t, instance)
+	test.Equals(t, instance.Status, pb.WorkflowInstance_Completed)
+	runs := make(map[string]*pb.StepRun)
+	for _, r := range instance.Steps {
+		runs[r.Step] = r
+	}
+	// start fans out to fetch and check, and routes to notify on its result. The error of check
+	// is routed to the join.
+	test.Len(t, runs, 5)
+	test.NotNil(t, runs["notify"])
+	test.Equals(t, runs["check"].Code, uint64(500))
+	var joined struct {
+		Fetch eventTenantV1 `json:"fetch"`
+	}
+	err = json.Unmarshal([]byte(runs["join"].Result), &joined)
+	test.Nil(t, err)
+	test.Equals(t, joined.Fetch, evt)
+}
+
+func TestWorkflowCycles(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl)
+	test.Nil(t, err)
+	// steps are found by ID in the state of an instance, so a step routed back would replay its
+	// old run. The ctl rejects the workflows that are not DAGs.
+	cases := []struct {
+		name   string
+		cycle  func(steps []*pb.StepDef)
+		reason string
+	}{
+		{
+			name: "routed back to the first step",
+			cycle: func(steps []*pb.StepDef) {
+				steps[1].Routes = []*pb.RouteDef{{Next: []string{"start"}}}
+			},
+			reason: "the first step cannot be routed to",
+		},
+		{
+			name: "two steps routed to each other",
+			cycle: func(steps []*pb.StepDef) {
+				steps[0].Routes = steps[0].Routes[:1]
+				steps[4].Routes = []*pb.RouteDef{{Next: []string{"skip"}}}
+				steps[5].Routes = []*pb.RouteDef{{Next: []string{"notify"}}}
+			},
+			reason: "is part of a cycle",
+		},
+		{
+			name: "a join routed to itself",
+			cycle: func(steps []*pb.StepDef) {
+				steps[3].Routes = []*pb.RouteDef{{Next: []string{"join"}}}
+			},
+			reason: "is part of a cycle",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			invalid := newWorkflowTestPackage()
+			c.cycle(invalid.Workflows[0].Steps)
+			_, err := cli.ctl.AddPackage(ctx, invalid)
+			test.NotNil(t, err)
+			test.Equals(t, status.Code(err), codes.InvalidArgument)
+			test.Equals(t, strings.Contains(status.Convert(err).Message(), c.reason), true)
+		})
+	}
+}
+
+func TestWorkflowRetention(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	t.Setenv("ctl.workflow.retention", "1s")
+	t.Setenv("ctl.workflow.retention.interval", "100ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newWorkflowTestPackage()
+	addPackageAndFiles(t, cli, pkg)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	u, err := url.Parse(fmt.Sprintf(sendEventURL, pkg.Tenant, pkg.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	b, err := json.Marshal(event{[]interface{}{eventTenantV1{"john", "connor", 50}}})
+	test.Nil(t, err)
+	ids, err := cli.sendEventWithHeader(u, b, http.Header{})
+	test.Nil(t, err)
+	test.Len(t, ids, 1)
+	// the instance is kept while it runs, and removed once the retention elapses after it completed.
+	completed := false
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		instance, err := cli.ctl.WorkflowInstance(ctx, pkg.Tenant, "wf", ids[0])
+		test.Nil(t, err)
+		if instance != nil && instance.Status == pb.WorkflowInstance_Completed {
+			test.NotNil(t, instance.CompletedAt)
+			completed = true
+		}
+		if instance == nil && completed {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("the instance was not removed after it completed (completed: %t)", completed)
+}
+
+func TestEmit(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newPackage(SchemaRefIDs{"sch1", "sch1_ok", "sch1_error"}, "runemitter1")
+	pkg.Jobs = append(pkg.Jobs, &pb.JobDef{
+		Event: &pb.EventDef{
+			ID:            "event_emitted",
+			DataType:      pb.DataType_Json,
+			SupplierQueue: "queue_id_1_ok",
+			Runtime:       "runtime_id_1",
+			Schema:        &pb.SchemaDef{SchemaRef: "sch1"},
+		},
+	})
+	addPackageAndFiles(t, cli, pkg)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	u, err := url.Parse(fmt.Sprintf(sendEventURL, pkg.Tenant, pkg.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	evt, err := cli.sendEventV1(u)
+	test.Nil(t, err)
+	// the error result is queued once the emitted events were queued.
+	res, err := cli.dequeue(pkg.Tenant, "queue_id_1_error")
+	test.Nil(t, err)
+	test.Len(t, res, 1)
+	result := &pb.JobResult{}
+	err = proto.Unmarshal(res[0].Item.Data, result)
+	test.Nil(t, err)
+	test.Equals(t, result.Code, uint64(10*wasm.EmitUnknownEvent+wasm.EmitInvalidEvent))
+	items, err := cli.queue.Dequeue(ctx, pkg.Tenant, "queue_id_1_ok")
+	test.Nil(t, err)
+	test.Len(t, items, 2)
+	for _, i := range items {
+		test.Equals(t, i.Item.Event, "event_emitted")
+		var emitted eventTenantV1
+		err = json.Unmarshal(i.Item.Data, &emitted)
+		test.Nil(t, err)
+		test.Equals(t, emitted, evt)
+	}
+}
+
+func TestRuntimeLimits(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.listener, platform.repo)
+	test.Nil(t, err)
+	// the module never returns.
+	loop := newPackage(SchemaRefIDs{"sch1", "sch1_ok", "sch1_error"}, "runsleeper1")
+	loop.Runtimes[0].Limits = &pb.RuntimeLimits{TimeoutMillis: 100}
+	addPackageAndFiles(t, cli, loop)
+	// the module cannot grow its memory to copy the event.
+	echo := newTestPackage()
+	echo.Tenant = "tenant_2"
+	echo.Runtimes[0].Limits = &pb.RuntimeLimits{MaxMemoryPages: 2}
+	addPackageAndFiles(t, cli, echo)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	err = sendEvtV1(loop, cli)
+	test.Nil(t, err)
+	u, err := url.Parse(fmt.Sprintf(sendEventURL, echo.Tenant, echo.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	big, err := json.Marshal(event{[]interface{}{eventTenantV1{strings.Repeat("john", 100_000), "connor", 50}}})
+	test.Nil(t, err)
+	err = cli.sendEvent(u, big)
+	test.Nil(t, err)
+	waitForLimit(t, cli, loop, "timeout of 100ms exceeded")
+	waitForLimit(t, cli, echo, "memory limit of 2 pages exceeded")
+	// the instance stopped by the limit was replaced.
+	err = sendEvtV1(echo, cli)
+	test.Nil(t, err)
+	res, err := cli.dequeue(echo.Tenant, "queue_id_1_ok")
+	test.Nil(t, err)
+	test.NotEmpty(t, res)
+}
+
 func TestStreamingSchemaUpdate(t *testing.T) {
 	defer goleak.VerifyNone(t)
 	setEnvVars()
@@ -220,6 +596,66 @@ func TestEventErrors(t *testing.T) {
 	test.ErrorIs(t, err, errSend{StatusCode: 400})
 }
 
+func TestBackpressure(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{InMemory: true, RetryAfter: 2 * time.Second})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.repo)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	high, low := uint32(2), uint32(1)
+	pkg.Queues[0].HighWatermark = &high
+	pkg.Queues[0].LowWatermark = &low
+	addPackageAndFiles(t, cli, pkg)
+	err = svcGroup.Start(platform.listener, platform.queue)
+	test.Nil(t, err)
+	url, err := url.Parse(fmt.Sprintf(sendEventURL, pkg.Tenant, pkg.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	// a request with more events than the high watermark never fits in the queue.
+	d := eventTenantV1{"john", "connor", 50}
+	b, err := json.Marshal(event{[]interface{}{d, d, d}})
+	test.Nil(t, err)
+	err = cli.sendEvent(url, b)
+	test.ErrorIs(t, err, errSend{StatusCode: 400})
+	for i := 0; i < 2; i++ {
+		_, err = cli.sendEventV1(url)
+		test.Nil(t, err)
+	}
+	_, err = cli.sendEventV1(url)
+	test.ErrorIs(t, err, errSend{StatusCode: 429, RetryAfter: "2"})
+	stats, err := cli.queue.Stats(ctx, pkg.Tenant, pkg.Queues[0].ID)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Backpressure, true)
+	// a rejected event is still in the queue.
+	items, err := cli.queue.DequeueBatch(ctx, pkg.Tenant, pkg.Queues[0].ID, 1, 0)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	err = cli.queue.Nack(ctx, pkg.Tenant, pkg.Queues[0].ID, "failed", items[0].Receipt)
+	test.Nil(t, err)
+	_, err = cli.sendEventV1(url)
+	test.ErrorIs(t, err, errSend{StatusCode: 429, RetryAfter: "2"})
+	items, err = cli.queue.DequeueBatch(ctx, pkg.Tenant, pkg.Queues[0].ID, 1, 0)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	err = cli.queue.Ack(ctx, pkg.Tenant, pkg.Queues[0].ID, items[0].Receipt)
+	test.Nil(t, err)
+	// the queue drained to the low watermark.
+	_, err = cli.sendEventV1(url)
+	test.Nil(t, err)
+	stats, err = cli.queue.Stats(ctx, pkg.Tenant, pkg.Queues[0].ID)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Backpressure, false)
+}
+
 func TestQueueDown(t *testing.T) {
 	defer goleak.VerifyNone(t)
 	setEnvVars()
@@ -251,11 +687,12 @@ func TestQueueDown(t *testing.T) {
 func TestErroCtl(t *testing.T) {
 	defer goleak.VerifyNone(t)
 	setEnvVars()
-	os.Setenv("http.shutdown.timeout", (10 * time.Microsecond).String())
-	os.Setenv("http.timeout.write", (5 * time.Microsecond).String())
-	os.Setenv("http.timeout.read", (5 * time.Microsecond).String())
-	os.Setenv("http.timeout.idle", (5 * time.Microsecond).String())
-	os.Setenv("http.timeout.handler", (5 * time.Microsecond).String())
+	// the timeouts are restored when the test ends, or the HTTP requests of the tests that run after it fail.
+	t.Setenv("http.shutdown.timeout", (10 * time.Microsecond).String())
+	t.Setenv("http.timeout.write", (5 * time.Microsecond).String())
+	t.Setenv("http.timeout.read", (5 * time.Microsecond).String())
+	t.Setenv("http.timeout.idle", (5 * time.Microsecond).String())
+	t.Setenv("http.timeout.handler", (5 * time.Microsecond).String())
 	ctx, cancel := context.WithCancel(context.Background())
 	platform, err := newPlatformWithTimeout(ctx, 1*time.Second)
 	test.Nil(t, err)
@@ -324,31 +761,1718 @@ func TestErrorRepo(t *testing.T) {
 	test.NotNil(t, err)
 }
 
-func cleanUp(t *testing.T, platform *platform, svcGroup *test.ServiceGroup, cli *testClient) {
-	fail := false
-	if err := svcGroup.WaitUntilStopped(); err != nil {
-		errw := test.ErrService{}
-		if errors.As(err, &errw) {
-			t.Errorf("error while stopping the service %s: %v", errw.Starter.Addr(), errw.Err)
-		} else {
-			t.Errorf("error while stopping %v", err)
-		}
-		fail = true
-	}
-	if cli != nil {
-		if err := cli.close(); err != nil {
-			t.Errorf("error stopping service group %v", err)
-			fail = true
-		}
-	}
-	err := platform.dispose()
-	if err != nil {
-		t.Errorf("error disposing services %v", err)
-		fail = true
-	}
-	if fail {
-		t.FailNow()
+func TestQueueAckNack(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: []byte("{}")}},
+	})
+	test.Nil(t, err)
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	leased, err := cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, leased)
+	err = cli.queue.Nack(ctx, pkg.Tenant, q, "test", items[0].Receipt)
+	test.Nil(t, err)
+	items, err = cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.Nil(t, err)
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.NotNil(t, err)
+	leased, err = cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, leased)
+}
+
+func TestLongPolling(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: pkg.Tenant,
+			Queue:  q,
+			Items: []*pb.QueueItem{
+				{Event: event, Data: []byte("{\"n\":1}")},
+				{Event: event, Data: []byte("{\"n\":2}")},
+				{Event: event, Data: []byte("{\"n\":3}")},
+			},
+		})
+	}()
+	start := time.Now()
+	items, err := cli.queue.DequeueBatch(ctx, pkg.Tenant, q, 2, 10*time.Second)
+	test.Nil(t, err)
+	test.Len(t, items, 2)
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("expected the items as soon as they were queued, waited %s", time.Since(start))
+	}
+	rest, err := cli.queue.DequeueBatch(ctx, pkg.Tenant, q, 2, 0)
+	test.Nil(t, err)
+	test.Len(t, rest, 1)
+	for _, item := range append(items, rest...) {
+		err = cli.queue.Ack(ctx, pkg.Tenant, q, item.Receipt)
+		test.Nil(t, err)
+	}
+	start = time.Now()
+	items, err = cli.queue.DequeueBatch(ctx, pkg.Tenant, q, 0, 300*time.Millisecond)
+	test.Nil(t, err)
+	test.Empty(t, items)
+	if time.Since(start) < 300*time.Millisecond {
+		t.Errorf("expected to wait for the items, waited %s", time.Since(start))
+	}
+}
+
+func TestDeduplication(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackageAndFiles(t, cli, pkg)
+	q := pkg.Jobs[0].Event.SupplierQueue
+	url, err := url.Parse(fmt.Sprintf(sendEventURL, pkg.Tenant, pkg.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	evt, err := json.Marshal(event{[]interface{}{eventTenantV1{"john", "connor", 50}}})
+	test.Nil(t, err)
+	header := http.Header{}
+	header.Set(listener.IdempotencyKeyHeader, "key1")
+	ids, err := cli.sendEventWithHeader(url, evt, header)
+	test.Nil(t, err)
+	test.Len(t, ids, 1)
+	retried, err := cli.sendEventWithHeader(url, evt, header)
+	test.Nil(t, err)
+	test.Equals(t, retried, ids)
+	key := "key1"
+	queued, err := cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: evt, DedupKey: &key}},
+	})
+	test.Nil(t, err)
+	test.Equals(t, queued, ids)
+	header.Set(listener.IdempotencyKeyHeader, "key2")
+	other, err := cli.sendEventWithHeader(url, evt, header)
+	test.Nil(t, err)
+	test.Len(t, other, 1)
+	test.NotEquals(t, other, ids)
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 2)
+	got := []string{items[0].Item.ID, items[1].Item.ID}
+	slices.Sort(got)
+	want := []string{ids[0], other[0]}
+	slices.Sort(want)
+	test.Equals(t, got, want)
+}
+
+func TestPartitionKey(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	pkg.Jobs[0].Event.PartitionKey = strptr("/lastName")
+	addPackageAndFiles(t, cli, pkg)
+	q := pkg.Jobs[0].Event.SupplierQueue
+	url, err := url.Parse(fmt.Sprintf(sendEventURL, pkg.Tenant, pkg.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	evt, err := json.Marshal(event{[]interface{}{
+		eventTenantV1{"john", "connor", 50},
+		eventTenantV1{"sarah", "connor", 45},
+		eventTenantV1{"kyle", "reese", 30},
+	}})
+	test.Nil(t, err)
+	ids, err := cli.sendEventWithHeader(url, evt, http.Header{})
+	test.Nil(t, err)
+	test.Len(t, ids, 3)
+	// the second event of a key is not delivered while the first one is in flight.
+	items, err := cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 2)
+	test.Equals(t, items[0].Item.ID, ids[0])
+	test.Equals(t, items[0].Item.GetPartitionKey(), "connor")
+	test.Equals(t, items[1].Item.ID, ids[2])
+	test.Equals(t, items[1].Item.GetPartitionKey(), "reese")
+	next, err := cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, next)
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.Nil(t, err)
+	next, err = cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, next, 1)
+	test.Equals(t, next[0].Item.ID, ids[1])
+}
+
+func TestResultPartitionKey(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	pkg.Jobs[0].Result.Payload = pb.ResultDef_Result
+	pkg.Jobs[0].Result.Ok.PartitionKey = strptr("/lastName")
+	addPackageAndFiles(t, cli, pkg)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	u, err := url.Parse(fmt.Sprintf(sendEventURL, pkg.Tenant, pkg.Jobs[0].Event.ID))
+	test.Nil(t, err)
+	_, err = cli.sendEventV1(u)
+	test.Nil(t, err)
+	// the Jobicolet echoes the event, so the key of the result comes from it.
+	res, err := cli.dequeue(pkg.Tenant, "queue_id_1_ok")
+	test.Nil(t, err)
+	test.Len(t, res, 1)
+	test.Equals(t, res[0].Item.Event, "event_id_1_ok")
+	test.Equals(t, res[0].Item.GetPartitionKey(), "connor")
+}
+
+func TestFileQueueOrder(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{Dir: t.TempDir()})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	// the items are written within the same second: one by one, in a batch, and a delayed one that is
+	// served after the items visible before it is due.
+	expected := make([]string, 0)
+	queueItems := func(data ...string) {
+		items := make([]*pb.QueueItem, len(data))
+		for i, d := range data {
+			items[i] = &pb.QueueItem{Event: pkg.Jobs[0].Event.ID, Data: []byte(d)}
+		}
+		_, err := cli.queue.Queue(ctx, &pb.QueueRequest{Tenant: pkg.Tenant, Queue: q, Items: items})
+		test.Nil(t, err)
+	}
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: []byte("delayed")}},
+		Delay:  durationpb.New(200 * time.Millisecond),
+	})
+	test.Nil(t, err)
+	for i := 0; i < 20; i++ {
+		queueItems(strconv.Itoa(i))
+		expected = append(expected, strconv.Itoa(i))
+	}
+	time.Sleep(250 * time.Millisecond)
+	expected = append(expected, "delayed")
+	batch := make([]string, 20)
+	for i := range batch {
+		batch[i] = strconv.Itoa(20 + i)
+	}
+	queueItems(batch...)
+	expected = append(expected, batch...)
+	for _, data := range expected {
+		items, err := cli.queue.DequeueBatch(ctx, pkg.Tenant, q, 1, 0)
+		test.Nil(t, err)
+		test.Len(t, items, 1)
+		test.Equals(t, string(items[0].Item.Data), data)
+		err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+		test.Nil(t, err)
+	}
+}
+
+func TestQueueCluster(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	t.Setenv("queue.cluster", "true")
+	ctx, cancel := context.WithCancel(context.Background())
+	option := queuectl.Option{InMemory: true, Cluster: true, RebalanceInterval: 10 * time.Millisecond}
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	node2, err := queuesvc.New(ctx, queuesvc.WithGrpcConn(service.GrpcConn{
+		Listener: platform.conn,
+		Dialer:   platform.conn,
+	}), queuesvc.WithOption(option), queuesvc.WithAddr("queue:2"))
+	test.Nil(t, err)
+	defer node2.Dispose()
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, node2)
+	test.Nil(t, err)
+	environment := &pb.Environment{Services: []*pb.Service{{
+		ID:      client.QueueService,
+		Servers: []*pb.Host{{Ip: "queue", Port: 1}},
+	}}}
+	_, err = cli.ctl.AddEnvironment(ctx, environment)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	// the rebalancing loaded the queues before the package was added, so it arrives as an update.
+	for {
+		stats, err := cli.queue.Stats(ctx, pkg.Tenant, "")
+		test.Nil(t, err)
+		if len(stats) == len(pkg.Queues) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, q := range pkg.Queues {
+		_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: pkg.Tenant,
+			Queue:  q.ID,
+			Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: []byte(q.ID)}},
+		})
+		test.Nil(t, err)
+		// the failed delivery is carried over when the item is moved.
+		items, err := cli.dequeue(pkg.Tenant, q.ID)
+		test.Nil(t, err)
+		test.Len(t, items, 1)
+		err = cli.queue.Nack(ctx, pkg.Tenant, q.ID, "failed", items[0].Receipt)
+		test.Nil(t, err)
+	}
+	// the queues owned by the new node are moved to it.
+	ring := client.NewRing([]string{"queue:1", "queue:2"})
+	var moved, kept []string
+	for _, q := range pkg.Queues {
+		if ring.Node(pkg.Tenant, q.ID) == "queue:2" {
+			moved = append(moved, q.ID)
+		} else {
+			kept = append(kept, q.ID)
+		}
+	}
+	test.NotEmpty(t, moved)
+	test.NotEmpty(t, kept)
+	environment.Services[0].Servers = append(environment.Services[0].Servers, &pb.Host{Ip: "queue", Port: 2})
+	err = cli.ctl.UpdateEnvironment(ctx, environment)
+	test.Nil(t, err)
+	direct, err := client.NewQueueForNode(ctx, platform.conn, "queue:2")
+	test.Nil(t, err)
+	defer direct.Close()
+	for _, q := range moved {
+		for {
+			stats, err := direct.Stats(ctx, pkg.Tenant, q)
+			if err == nil && stats[0].Depth == 1 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	// clients route the requests to the owner of each queue.
+	for _, q := range pkg.Queues {
+		items, err := cli.dequeue(pkg.Tenant, q.ID)
+		test.Nil(t, err)
+		test.Len(t, items, 1)
+		test.Equals(t, string(items[0].Item.Data), q.ID)
+		test.Equals(t, items[0].Attempts, uint32(2))
+		err = cli.queue.Ack(ctx, pkg.Tenant, q.ID, items[0].Receipt)
+		test.Nil(t, err)
+	}
+	stats, err := cli.queue.Stats(ctx, pkg.Tenant, "")
+	test.Nil(t, err)
+	test.Len(t, stats, len(pkg.Queues))
+	for _, st := range stats {
+		test.Equals(t, st.Depth, uint32(0))
+	}
+}
+
+func TestQueueReplication(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	option := queuectl.Option{InMemory: true, Node: "queue:1", Replication: queuectl.ReplicationOption{
+		Replicas:  []string{"queue:2"},
+		Sync:      true,
+		Heartbeat: 10 * time.Millisecond,
+	}}
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	replica, err := queuesvc.New(ctx, queuesvc.WithGrpcConn(service.GrpcConn{
+		Listener: platform.conn,
+		Dialer:   platform.conn,
+	}), queuesvc.WithOption(queuectl.Option{InMemory: true, Node: "queue:2", Replication: queuectl.ReplicationOption{
+		Replica: true,
+	}}), queuesvc.WithAddr("queue:2"))
+	test.Nil(t, err)
+	defer replica.Dispose()
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, replica)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	queue := pkg.Queues[0].ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  queue,
+		Items: []*pb.QueueItem{
+			{Event: pkg.Jobs[0].Event.ID, Data: []byte("1")},
+			{Event: pkg.Jobs[0].Event.ID, Data: []byte("2")},
+		},
+	})
+	test.Nil(t, err)
+	items, err := cli.queue.DequeueBatch(ctx, pkg.Tenant, queue, 1, 0)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	err = cli.queue.Ack(ctx, pkg.Tenant, queue, items[0].Receipt)
+	test.Nil(t, err)
+	direct, err := client.NewQueueForNode(ctx, platform.conn, "queue:2")
+	test.Nil(t, err)
+	defer direct.Close()
+	// the replica rejects clients until it is promoted.
+	_, err = direct.Dequeue(ctx, pkg.Tenant, queue)
+	test.NotNil(t, err)
+	err = direct.Promote(ctx)
+	test.Nil(t, err)
+	replicated, err := direct.Dequeue(ctx, pkg.Tenant, queue)
+	test.Nil(t, err)
+	test.Len(t, replicated, 1)
+	test.Equals(t, string(replicated[0].Item.Data), "2")
+	err = direct.Ack(ctx, pkg.Tenant, queue, replicated[0].Receipt)
+	test.Nil(t, err)
+}
+
+func TestQueueReplicaResync(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	option := queuectl.Option{InMemory: true, Node: "queue:1", Replication: queuectl.ReplicationOption{
+		Replicas:  []string{"queue:2"},
+		Sync:      true,
+		Heartbeat: 10 * time.Millisecond,
+	}}
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	newReplica := func() *queuesvc.Service {
+		replica, err := queuesvc.New(ctx, queuesvc.WithGrpcConn(service.GrpcConn{
+			Listener: platform.conn,
+			Dialer:   platform.conn,
+		}), queuesvc.WithOption(queuectl.Option{InMemory: true, Node: "queue:2", Replication: queuectl.ReplicationOption{
+			Replica: true,
+		}}), queuesvc.WithAddr("queue:2"))
+		test.Nil(t, err)
+		return replica
+	}
+	replica := newReplica()
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, replica)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	queue := pkg.Queues[0].ID
+	send := func(data string) {
+		_, err := cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: pkg.Tenant,
+			Queue:  queue,
+			Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: []byte(data)}},
+		})
+		test.Nil(t, err)
+	}
+	send("1")
+	send("2")
+	// the new replica lost the changes applied by the previous one, so it receives a copy of the queues.
+	replica.Stop()
+	replica.Dispose()
+	err = platform.conn.Close("queue:2")
+	test.Nil(t, err)
+	replica = newReplica()
+	defer replica.Dispose()
+	err = svcGroup.Start(replica)
+	test.Nil(t, err)
+	send("3")
+	direct, err := client.NewQueueForNode(ctx, platform.conn, "queue:2")
+	test.Nil(t, err)
+	defer direct.Close()
+	err = direct.Promote(ctx)
+	test.Nil(t, err)
+	replicated, err := direct.DequeueBatch(ctx, pkg.Tenant, queue, 3, 0)
+	test.Nil(t, err)
+	test.Len(t, replicated, 3)
+	for i, item := range replicated {
+		test.Equals(t, string(item.Item.Data), strconv.Itoa(i+1))
+	}
+}
+
+func TestQueueUnreplicated(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	// the replica is never started.
+	option := queuectl.Option{InMemory: true, Node: "queue:1", Replication: queuectl.ReplicationOption{
+		Replicas:    []string{"queue:2"},
+		Sync:        true,
+		SyncTimeout: 100 * time.Millisecond,
+	}}
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	queue := pkg.Queues[0].ID
+	request := &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  queue,
+		Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: []byte("1"), DedupKey: strptr("key")}},
+	}
+	// the items are queued even if the replica does not confirm them, and sending them again does not duplicate them.
+	ids, err := cli.queue.Queue(ctx, request)
+	test.Nil(t, err)
+	again, err := cli.queue.Queue(ctx, request)
+	test.Nil(t, err)
+	test.Equals(t, again, ids)
+	stats, err := cli.queue.Stats(ctx, pkg.Tenant, queue)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Visible, uint32(1))
+	// the items are acknowledged even if the replica does not confirm it, waiting for it once per request.
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  queue,
+		Items: []*pb.QueueItem{
+			{Event: pkg.Jobs[0].Event.ID, Data: []byte("2")},
+			{Event: pkg.Jobs[0].Event.ID, Data: []byte("3")},
+		},
+	})
+	test.Nil(t, err)
+	items, err := cli.queue.DequeueBatch(ctx, pkg.Tenant, queue, 3, 0)
+	test.Nil(t, err)
+	test.Len(t, items, 3)
+	receipts := make([]string, 0, len(items))
+	for _, i := range items {
+		receipts = append(receipts, i.Receipt)
+	}
+	start := time.Now()
+	err = cli.queue.Ack(ctx, pkg.Tenant, queue, receipts...)
+	test.Nil(t, err)
+	test.Equals(t, time.Since(start) < 3*option.Replication.SyncTimeout, true)
+	stats, err = cli.queue.Stats(ctx, pkg.Tenant, queue)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Visible, uint32(0))
+	test.Equals(t, stats[0].Leased, uint32(0))
+}
+
+func TestDelayedDelivery(t *testing.T) {
+	for name, option := range queueProviders {
+		t.Run(name, func(t *testing.T) {
+			testDelayedDelivery(t, option(t))
+		})
+	}
+}
+
+func testDelayedDelivery(t *testing.T, option queuectl.Option) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: []byte("{}")}},
+		Delay:  durationpb.New(500 * time.Millisecond),
+	})
+	test.Nil(t, err)
+	leased, err := cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, leased)
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.NotNil(t, items[0].Item.NotBefore)
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.Nil(t, err)
+}
+
+func TestPriority(t *testing.T) {
+	for name, option := range queueProviders {
+		t.Run(name, func(t *testing.T) {
+			testPriority(t, option(t))
+		})
+	}
+}
+
+func testPriority(t *testing.T, option queuectl.Option) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	levels := uint32(2)
+	pkg.Queues[0].PriorityLevels = &levels
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items: []*pb.QueueItem{
+			{Event: event, Data: []byte("{\"n\":1}")},
+			{Event: event, Data: []byte("{\"n\":2}"), Priority: 1},
+			{Event: event, Data: []byte("{\"n\":3}"), Priority: 5},
+		},
+	})
+	test.Nil(t, err)
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 3)
+	test.Equals(t, string(items[0].Item.Data), "{\"n\":2}")
+	test.Equals(t, string(items[1].Item.Data), "{\"n\":3}")
+	test.Equals(t, items[1].Item.Priority, uint32(1))
+	test.Equals(t, string(items[2].Item.Data), "{\"n\":1}")
+}
+
+func TestNackDelay(t *testing.T) {
+	for name, option := range queueProviders {
+		t.Run(name, func(t *testing.T) {
+			testNackDelay(t, option(t))
+		})
+	}
+}
+
+func testNackDelay(t *testing.T, option queuectl.Option) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	key := "k"
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items: []*pb.QueueItem{
+			{Event: event, Data: []byte("1"), PartitionKey: &key},
+			{Event: event, Data: []byte("2"), PartitionKey: &key},
+		},
+	})
+	test.Nil(t, err)
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	delay := 300 * time.Millisecond
+	err = cli.queue.NackAfter(ctx, pkg.Tenant, q, "failed", delay, items[0].Receipt)
+	test.Nil(t, err)
+	// the delayed item keeps its partition key blocked, and its receipt is no longer valid.
+	blocked, err := cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, blocked)
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.NotNil(t, err)
+	items, err = cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, string(items[0].Item.Data), "1")
+	test.Equals(t, items[0].Attempts, uint32(2))
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.Nil(t, err)
+	items, err = cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, string(items[0].Item.Data), "2")
+}
+
+func TestSubscribe(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	sub, err := cli.queue.Subscribe(ctx, pkg.Tenant, q, 1)
+	test.Nil(t, err)
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items: []*pb.QueueItem{
+			{Event: event, Data: []byte("{\"n\":1}")},
+			{Event: event, Data: []byte("{\"n\":2}")},
+		},
+	})
+	test.Nil(t, err)
+	first, err := sub.Recv()
+	test.Nil(t, err)
+	test.Equals(t, string(first.Item.Data), "{\"n\":1}")
+	// the second item is held back until the first one is acknowledged.
+	items, err := cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	err = cli.queue.Nack(ctx, pkg.Tenant, q, "", items[0].Receipt)
+	test.Nil(t, err)
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, first.Receipt)
+	test.Nil(t, err)
+	second, err := sub.Recv()
+	test.Nil(t, err)
+	test.Equals(t, string(second.Item.Data), "{\"n\":2}")
+	test.Equals(t, second.Attempts, uint32(1))
+}
+
+func TestLogQueue(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{
+		Log:       true,
+		LogOption: provider.LogOption{SegmentSize: 1024, Sync: provider.SyncAlways},
+		Dir:       t.TempDir(),
+	})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	for i := 0; i < 20; i++ {
+		_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: pkg.Tenant,
+			Queue:  q,
+			Items:  []*pb.QueueItem{{Event: event, Data: []byte(fmt.Sprintf("{\"n\":%d}", i))}},
+		})
+		test.Nil(t, err)
+	}
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 20)
+	for i, item := range items {
+		test.Equals(t, string(item.Item.Data), fmt.Sprintf("{\"n\":%d}", i))
+	}
+	err = cli.queue.Nack(ctx, pkg.Tenant, q, "failed", items[0].Receipt)
+	test.Nil(t, err)
+	for _, item := range items[1:] {
+		err = cli.queue.Ack(ctx, pkg.Tenant, q, item.Receipt)
+		test.Nil(t, err)
+	}
+	items, err = cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, items[0].Attempts, uint32(2))
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.Nil(t, err)
+}
+
+func TestLogQueueCompaction(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	dir := t.TempDir()
+	option := queuectl.Option{
+		Log:       true,
+		LogOption: provider.LogOption{SegmentSize: 1024, Sync: provider.SyncAlways},
+		Dir:       dir,
+	}
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	// the delayed item is not consumed while the rest of the items go through the queue.
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: event, Data: []byte("{\"n\":0}"), NotBefore: timestamppb.New(time.Now().Add(time.Hour))}},
+	})
+	test.Nil(t, err)
+	for i := 1; i <= 100; i++ {
+		_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: pkg.Tenant,
+			Queue:  q,
+			Items:  []*pb.QueueItem{{Event: event, Data: []byte(fmt.Sprintf("{\"n\":%d}", i))}},
+		})
+		test.Nil(t, err)
+		items, err := cli.dequeue(pkg.Tenant, q)
+		test.Nil(t, err)
+		test.Len(t, items, 1)
+		err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+		test.Nil(t, err)
+	}
+	segments, err := filepath.Glob(filepath.Join(dir, "log", pkg.Tenant, q, "*.seg"))
+	test.Nil(t, err)
+	test.Equals(t, len(segments) <= 4, true)
+	// the copied item is loaded by a node that opens the log.
+	node2, err := queuesvc.New(ctx, queuesvc.WithGrpcConn(service.GrpcConn{
+		Listener: platform.conn,
+		Dialer:   platform.conn,
+	}), queuesvc.WithOption(option), queuesvc.WithAddr("queue:2"))
+	test.Nil(t, err)
+	defer node2.Dispose()
+	err = svcGroup.Start(node2)
+	test.Nil(t, err)
+	direct, err := client.NewQueueForNode(ctx, platform.conn, "queue:2")
+	test.Nil(t, err)
+	defer direct.Close()
+	stats, err := direct.Stats(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Depth, uint32(1))
+	test.Equals(t, stats[0].Delayed, uint32(1))
+}
+
+func TestQuarantine(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	dir := t.TempDir()
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{Dir: dir})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: event, Data: []byte("{\"n\":1}")}},
+	})
+	test.Nil(t, err)
+	// a file that cannot be decoded, ahead of the queued item.
+	corrupted := filepath.Join(dir, "data", pkg.Tenant, q, "qdata0000000000000000001-corrupted.q")
+	err = os.WriteFile(corrupted, []byte("corrupted"), 0o600)
+	test.Nil(t, err)
+	err = os.Chtimes(corrupted, time.Unix(1, 0), time.Unix(1, 0))
+	test.Nil(t, err)
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, string(items[0].Item.Data), "{\"n\":1}")
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.Nil(t, err)
+	stats, err := cli.queue.Stats(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Quarantined, uint32(1))
+	test.Equals(t, stats[0].Depth, uint32(0))
+	quarantined, err := cli.queue.Quarantined(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, quarantined, 1)
+	test.Equals(t, string(quarantined[0].Data), "corrupted")
+	test.NotEquals(t, quarantined[0].Error, "")
+	// it cannot be requeued as it is stored, but it can be repaired.
+	err = cli.queue.RequeueQuarantined(ctx, pkg.Tenant, q, quarantined[0].ID, nil)
+	test.NotNil(t, err)
+	err = cli.queue.RequeueQuarantined(ctx, pkg.Tenant, q, quarantined[0].ID, &pb.QueueItem{Event: event, Data: []byte("{\"n\":2}")})
+	test.Nil(t, err)
+	items, err = cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, string(items[0].Item.Data), "{\"n\":2}")
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.Nil(t, err)
+	quarantined, err = cli.queue.Quarantined(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, quarantined)
+	// a deleted item is gone for good.
+	err = os.WriteFile(corrupted, []byte("corrupted"), 0o600)
+	test.Nil(t, err)
+	_, err = cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	quarantined, err = cli.queue.Quarantined(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, quarantined, 1)
+	err = cli.queue.DeleteQuarantined(ctx, pkg.Tenant, q, quarantined[0].ID)
+	test.Nil(t, err)
+	quarantined, err = cli.queue.Quarantined(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, quarantined)
+}
+
+func TestQueueLifecycle(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	dir := t.TempDir()
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{Dir: dir, DrainInterval: 10 * time.Millisecond})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	deleted, drained := pb.QueueDef_Delete, pb.QueueDef_Drain
+	pkg.Queues[1].OnDelete = &deleted
+	pkg.Queues[2].OnDelete = &drained
+	addPackage(t, cli, pkg)
+	event := pkg.Jobs[0].Event.ID
+	for _, q := range pkg.Queues {
+		_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: pkg.Tenant,
+			Queue:  q.ID,
+			Items:  []*pb.QueueItem{{Event: event, Data: []byte(q.ID)}},
+		})
+		test.Nil(t, err)
+	}
+	err = cli.deletePackage(pkg)
+	test.Nil(t, err)
+	audit := func(n int) []string {
+		for {
+			b, err := os.ReadFile(filepath.Join(dir, "audit.log"))
+			if err == nil || !errors.Is(err, os.ErrNotExist) {
+				test.Nil(t, err)
+				if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) == n {
+					return lines
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	records := audit(2)
+	test.Equals(t, strings.Contains(records[0]+records[1], "\"policy\":\"Archive\""), true)
+	test.Equals(t, strings.Contains(records[0]+records[1], "\"policy\":\"Delete\""), true)
+	archives, err := filepath.Glob(filepath.Join(dir, "archive", pkg.Tenant, pkg.Queues[0].ID+"-*.tar.gz"))
+	test.Nil(t, err)
+	test.Len(t, archives, 1)
+	for _, q := range pkg.Queues[:2] {
+		_, err = os.Stat(filepath.Join(dir, "data", pkg.Tenant, q.ID))
+		test.Equals(t, errors.Is(err, os.ErrNotExist), true)
+	}
+	// the draining queue rejects new items until its consumers empty it.
+	draining := pkg.Queues[2].ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  draining,
+		Items:  []*pb.QueueItem{{Event: event, Data: []byte(draining)}},
+	})
+	test.NotNil(t, err)
+	items, err := cli.dequeue(pkg.Tenant, draining)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	err = cli.queue.Ack(ctx, pkg.Tenant, draining, items[0].Receipt)
+	test.Nil(t, err)
+	records = audit(3)
+	test.Equals(t, strings.Contains(records[2], "\"policy\":\"Drain\""), true)
+	// the queues of the package deployed again start empty.
+	err = cli.addPackage(pkg)
+	test.Nil(t, err)
+	for {
+		stats, err := cli.queue.Stats(ctx, pkg.Tenant, "")
+		test.Nil(t, err)
+		if len(stats) == len(pkg.Queues) {
+			for _, st := range stats {
+				test.Equals(t, st.Depth, uint32(0))
+			}
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQueueMigration(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	dir := t.TempDir()
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{Dir: dir})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	key := "k"
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items: []*pb.QueueItem{
+			{Event: event, Data: []byte("1")},
+			{Event: event, Data: []byte("2"), PartitionKey: &key},
+			{Event: event, Data: []byte("3"), NotBefore: timestamppb.New(time.Now().Add(time.Hour))},
+		},
+	})
+	test.Nil(t, err)
+	items, err := cli.queue.DequeueBatch(ctx, pkg.Tenant, q, 1, 0)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	err = cli.queue.Nack(ctx, pkg.Tenant, q, "failed", items[0].Receipt)
+	test.Nil(t, err)
+	option := queuectl.MigrationOption{
+		From: queuectl.Option{Dir: dir},
+		To:   queuectl.Option{Dir: dir, Pebble: true},
+	}
+	migrated, err := queuectl.Migrate(ctx, platform.conn, option)
+	test.Nil(t, err)
+	test.Len(t, migrated, len(pkg.Queues))
+	for _, m := range migrated {
+		if m.Queue == q {
+			test.Equals(t, m.Items, 3)
+			test.Equals(t, m.Resumed, 0)
+		}
+	}
+	// running it again copies nothing.
+	migrated, err = queuectl.Migrate(ctx, platform.conn, option)
+	test.Nil(t, err)
+	for _, m := range migrated {
+		test.Equals(t, m.Resumed, m.Items)
+	}
+	target, err := queuesvc.New(ctx, queuesvc.WithGrpcConn(service.GrpcConn{
+		Listener: platform.conn,
+		Dialer:   platform.conn,
+	}), queuesvc.WithOption(option.To), queuesvc.WithAddr("queue:2"))
+	test.Nil(t, err)
+	defer target.Dispose()
+	err = svcGroup.Start(target)
+	test.Nil(t, err)
+	direct, err := client.NewQueueForNode(ctx, platform.conn, "queue:2")
+	test.Nil(t, err)
+	defer direct.Close()
+	stats, err := direct.Stats(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Visible, uint32(2))
+	test.Equals(t, stats[0].Delayed, uint32(1))
+	peeked, err := direct.Peek(ctx, pkg.Tenant, q, 10)
+	test.Nil(t, err)
+	test.Len(t, peeked, 2)
+	test.Equals(t, string(peeked[0].Item.Data), "1")
+	test.Equals(t, peeked[0].Attempts, uint32(1))
+	test.Len(t, peeked[0].Failures, 1)
+	test.Equals(t, string(peeked[1].Item.Data), "2")
+	test.Equals(t, peeked[1].Item.GetPartitionKey(), key)
+}
+
+func TestQueueEncryption(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	dir := t.TempDir()
+	masterKey := []byte("0123456789abcdef0123456789abcdef")
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{
+		Dir:         dir,
+		Compression: true,
+		Encryption:  queuectl.EncryptionOption{MasterKey: masterKey},
+	})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	data := "{\"email\":\"" + strings.Repeat("someone@example.com ", 20) + "\"}"
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: []byte(data)}},
+	})
+	test.Nil(t, err)
+	stored := 0
+	err = filepath.WalkDir(filepath.Join(dir, "data"), func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		stored++
+		if strings.Contains(string(b), "someone@example.com") {
+			t.Errorf("%s holds the data in plain text", path)
+		}
+		return nil
+	})
+	test.Nil(t, err)
+	test.Equals(t, stored, 1)
+	peeked, err := cli.queue.Peek(ctx, pkg.Tenant, q, 10)
+	test.Nil(t, err)
+	test.Len(t, peeked, 1)
+	test.Equals(t, string(peeked[0].Item.Data), data)
+	test.Equals(t, peeked[0].Item.Encrypted, false)
+	keys, err := os.ReadFile(filepath.Join(dir, "keys.json"))
+	test.Nil(t, err)
+	// a node started with a new master key wraps the data keys again and reads the same items.
+	rotated, err := queuesvc.New(ctx, queuesvc.WithGrpcConn(service.GrpcConn{
+		Listener: platform.conn,
+		Dialer:   platform.conn,
+	}), queuesvc.WithOption(queuectl.Option{
+		Dir:        dir,
+		Encryption: queuectl.EncryptionOption{MasterKey: []byte("fedcba9876543210fedcba9876543210"), PreviousKey: masterKey},
+	}), queuesvc.WithAddr("queue:2"))
+	test.Nil(t, err)
+	defer rotated.Dispose()
+	err = svcGroup.Start(rotated)
+	test.Nil(t, err)
+	direct, err := client.NewQueueForNode(ctx, platform.conn, "queue:2")
+	test.Nil(t, err)
+	defer direct.Close()
+	peeked, err = direct.Peek(ctx, pkg.Tenant, q, 10)
+	test.Nil(t, err)
+	test.Len(t, peeked, 1)
+	test.Equals(t, string(peeked[0].Item.Data), data)
+	rewrapped, err := os.ReadFile(filepath.Join(dir, "keys.json"))
+	test.Nil(t, err)
+	test.NotEquals(t, string(rewrapped), string(keys))
+}
+
+func TestQueueEncryptionUnreadable(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	dir := t.TempDir()
+	option := queuectl.Option{
+		Dir:        dir,
+		Encryption: queuectl.EncryptionOption{MasterKey: []byte("0123456789abcdef0123456789abcdef")},
+	}
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: event, Data: []byte("{\"n\":1}")}},
+	})
+	test.Nil(t, err)
+	// a node that lost the data keys cannot open the item, which is ahead of the one it queues.
+	err = os.Remove(filepath.Join(dir, "keys.json"))
+	test.Nil(t, err)
+	node2, err := queuesvc.New(ctx, queuesvc.WithGrpcConn(service.GrpcConn{
+		Listener: platform.conn,
+		Dialer:   platform.conn,
+	}), queuesvc.WithOption(option), queuesvc.WithAddr("queue:2"))
+	test.Nil(t, err)
+	defer node2.Dispose()
+	err = svcGroup.Start(node2)
+	test.Nil(t, err)
+	direct, err := client.NewQueueForNode(ctx, platform.conn, "queue:2")
+	test.Nil(t, err)
+	defer direct.Close()
+	_, err = direct.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: event, Data: []byte("{\"n\":2}")}},
+	})
+	test.Nil(t, err)
+	items, err := direct.DequeueBatch(ctx, pkg.Tenant, q, 1, 0)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, string(items[0].Item.Data), "{\"n\":2}")
+	peeked, err := direct.Peek(ctx, pkg.Tenant, q, 10)
+	test.Nil(t, err)
+	test.Len(t, peeked, 1)
+	test.Equals(t, peeked[0].Item.Encrypted, true)
+	test.Len(t, peeked[0].Failures, 1)
+}
+
+func TestPebbleQueue(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{
+		Pebble: true,
+		Dir:    t.TempDir(),
+	})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.listener, platform.repo)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	levels := uint32(2)
+	pkg.Queues[0].PriorityLevels = &levels
+	addPackageAndFiles(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items: []*pb.QueueItem{
+			{Event: event, Data: []byte("{\"n\":1}")},
+			{Event: event, Data: []byte("{\"n\":2}"), Priority: 1},
+			{Event: event, Data: []byte("{\"n\":3}")},
+		},
+	})
+	test.Nil(t, err)
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 3)
+	test.Equals(t, string(items[0].Item.Data), "{\"n\":2}")
+	test.Equals(t, string(items[1].Item.Data), "{\"n\":1}")
+	test.Equals(t, string(items[2].Item.Data), "{\"n\":3}")
+	err = cli.queue.Nack(ctx, pkg.Tenant, q, "failed", items[1].Receipt)
+	test.Nil(t, err)
+	for _, item := range []*pb.DequeuedItem{items[0], items[2]} {
+		err = cli.queue.Ack(ctx, pkg.Tenant, q, item.Receipt)
+		test.Nil(t, err)
+	}
+	items, err = cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, string(items[0].Item.Data), "{\"n\":1}")
+	test.Equals(t, items[0].Attempts, uint32(2))
+	err = cli.queue.Ack(ctx, pkg.Tenant, q, items[0].Receipt)
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	_ = sendEvtV1AndValidate(t, pkg, cli)
+}
+
+// queueProviders are the storages the flows of the queues are tested on.
+var queueProviders = map[string]func(t *testing.T) queuectl.Option{
+	"memory": func(_ *testing.T) queuectl.Option { return queuectl.Option{InMemory: true} },
+	"file":   func(t *testing.T) queuectl.Option { return queuectl.Option{Dir: t.TempDir()} },
+	"log":    func(t *testing.T) queuectl.Option { return queuectl.Option{Log: true, Dir: t.TempDir()} },
+	"pebble": func(t *testing.T) queuectl.Option { return queuectl.Option{Pebble: true, Dir: t.TempDir()} },
+}
+
+func TestQueueIntrospection(t *testing.T) {
+	for name, option := range queueProviders {
+		t.Run(name, func(t *testing.T) {
+			testQueueIntrospection(t, option(t))
+		})
+	}
+}
+
+func testQueueIntrospection(t *testing.T, option queuectl.Option) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items: []*pb.QueueItem{
+			{Event: event, Data: []byte("{\"n\":1}")},
+			{Event: event, Data: []byte("{\"n\":2}")},
+			{Event: event, Data: []byte("{\"n\":3}"), NotBefore: timestamppb.New(time.Now().Add(time.Hour))},
+		},
+	})
+	test.Nil(t, err)
+	items, err := cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 2)
+	err = cli.queue.Nack(ctx, pkg.Tenant, q, "failed", items[0].Receipt)
+	test.Nil(t, err)
+	stats, err := cli.queue.Stats(ctx, pkg.Tenant, "")
+	test.Nil(t, err)
+	test.Len(t, stats, len(pkg.Queues))
+	stats, err = cli.queue.Stats(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, stats, 1)
+	test.Equals(t, stats[0].Queue, q)
+	test.Equals(t, stats[0].Depth, uint32(3))
+	test.Equals(t, stats[0].Visible, uint32(1))
+	test.Equals(t, stats[0].Delayed, uint32(1))
+	test.Equals(t, stats[0].Leased, uint32(1))
+	test.NotNil(t, stats[0].OldestAge)
+	test.NotEquals(t, stats[0].Bytes, int64(0))
+	peeked, err := cli.queue.Peek(ctx, pkg.Tenant, q, 10)
+	test.Nil(t, err)
+	test.Len(t, peeked, 1)
+	test.Equals(t, string(peeked[0].Item.Data), string(items[0].Item.Data))
+	test.Equals(t, peeked[0].Attempts, uint32(1))
+	test.Len(t, peeked[0].Failures, 1)
+	_, err = cli.queue.Purge(ctx, pkg.Tenant, q, "")
+	test.NotNil(t, err)
+	purged, err := cli.queue.Purge(ctx, pkg.Tenant, q, q)
+	test.Nil(t, err)
+	test.Equals(t, purged, uint32(3))
+	stats, err = cli.queue.Stats(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Depth, uint32(0))
+	test.Equals(t, stats[0].OldestAge, (*durationpb.Duration)(nil))
+}
+
+func TestBatchQueue(t *testing.T) {
+	providers := map[string]func(t *testing.T) queuectl.Option{
+		"memory": func(_ *testing.T) queuectl.Option { return queuectl.Option{InMemory: true} },
+		"file":   func(t *testing.T) queuectl.Option { return queuectl.Option{Dir: t.TempDir()} },
+		"log": func(t *testing.T) queuectl.Option {
+			return queuectl.Option{Log: true, LogOption: provider.LogOption{SegmentSize: 1024}, Dir: t.TempDir()}
+		},
+		"pebble": func(t *testing.T) queuectl.Option { return queuectl.Option{Pebble: true, Dir: t.TempDir()} },
+	}
+	for name, option := range providers {
+		t.Run(name, func(t *testing.T) {
+			testBatchQueue(t, option(t))
+		})
+	}
+}
+
+func testBatchQueue(t *testing.T, option queuectl.Option) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	addPackage(t, cli, pkg)
+	q := pkg.Queues[0].ID
+	event := pkg.Jobs[0].Event.ID
+	// the batch is larger than a log segment.
+	items := make([]*pb.QueueItem, 40)
+	for i := range items {
+		items[i] = &pb.QueueItem{Event: event, Data: []byte(fmt.Sprintf("{\"n\":%d}", i))}
+	}
+	key := "dup"
+	items[1].DedupKey = &key
+	items[2].DedupKey = &key
+	ids, err := cli.queue.Queue(ctx, &pb.QueueRequest{Tenant: pkg.Tenant, Queue: q, Items: items})
+	test.Nil(t, err)
+	test.Len(t, ids, len(items))
+	test.Equals(t, ids[2], ids[1])
+	stats, err := cli.queue.Stats(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Depth, uint32(len(items)-1))
+	dequeued, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, dequeued, len(items)-1)
+	for i, item := range dequeued {
+		n := i
+		if i > 1 {
+			n++
+		}
+		test.Equals(t, string(item.Item.Data), fmt.Sprintf("{\"n\":%d}", n))
+	}
+}
+
+func TestDeadLetterQueue(t *testing.T) {
+	for name, option := range queueProviders {
+		t.Run(name, func(t *testing.T) {
+			testDeadLetterQueue(t, option(t))
+		})
+	}
+}
+
+func testDeadLetterQueue(t *testing.T, option queuectl.Option) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), option)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	q := pkg.Queues[0].ID
+	dlq := "queue_id_1_dlq"
+	maxAttempts := uint32(1)
+	pkg.Queues[0].MaxAttempts = &maxAttempts
+	pkg.Queues[0].DeadLetterQueue = &dlq
+	pkg.Queues = append(pkg.Queues, &pb.QueueDef{ID: dlq})
+	addPackage(t, cli, pkg)
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: pkg.Jobs[0].Event.ID, Data: []byte("{}")}},
+	})
+	test.Nil(t, err)
+	items, err := cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, items[0].Attempts, uint32(1))
+	err = cli.queue.Nack(ctx, pkg.Tenant, q, "failed", items[0].Receipt)
+	test.Nil(t, err)
+	leased, err := cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, leased)
+	dls, err := cli.queue.DeadLetters(ctx, pkg.Tenant, dlq, 10)
+	test.Nil(t, err)
+	test.Len(t, dls, 1)
+	test.Equals(t, dls[0].SourceQueue, q)
+	test.Len(t, dls[0].Failures, 1)
+	test.Equals(t, dls[0].Failures[0].Reason, "failed")
+	n, err := cli.queue.RequeueDeadLetters(ctx, pkg.Tenant, dlq, nil)
+	test.Nil(t, err)
+	test.Equals(t, n, uint32(1))
+	items, err = cli.dequeue(pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Len(t, items, 1)
+	test.Equals(t, items[0].Attempts, uint32(1))
+	err = cli.queue.Nack(ctx, pkg.Tenant, q, "failed again", items[0].Receipt)
+	test.Nil(t, err)
+	leased, err = cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, leased)
+	n, err = cli.queue.PurgeDeadLetters(ctx, pkg.Tenant, dlq)
+	test.Nil(t, err)
+	test.Equals(t, n, uint32(1))
+	dls, err = cli.queue.DeadLetters(ctx, pkg.Tenant, dlq, 10)
+	test.Nil(t, err)
+	test.Empty(t, dls)
+}
+
+func TestUnsupportedEvent(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatform(ctx)
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue, platform.recorder, platform.repo)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	q := pkg.Queues[0].ID
+	dlq := "queue_id_1_dlq"
+	maxAttempts := uint32(1)
+	pkg.Queues[0].MaxAttempts = &maxAttempts
+	pkg.Queues[0].DeadLetterQueue = &dlq
+	pkg.Queues = append(pkg.Queues, &pb.QueueDef{ID: dlq})
+	addPackageAndFiles(t, cli, pkg)
+	err = svcGroup.Start(platform.executor)
+	test.Nil(t, err)
+	// the executor has no handler for the event, so it rejects it and the queue dead-letters it.
+	_, err = cli.queue.Queue(ctx, &pb.QueueRequest{
+		Tenant: pkg.Tenant,
+		Queue:  q,
+		Items:  []*pb.QueueItem{{Event: "event_unknown", Data: []byte("{}")}},
+	})
+	test.Nil(t, err)
+	var dls []*pb.DeadLetter
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		dls, err = cli.queue.DeadLetters(ctx, pkg.Tenant, dlq, 10)
+		test.Nil(t, err)
+		if len(dls) > 0 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	test.Len(t, dls, 1)
+	test.Equals(t, dls[0].SourceQueue, q)
+	test.Len(t, dls[0].Failures, 1)
+	test.Equals(t, dls[0].Failures[0].Reason, "event event_unknown not supported")
+}
+
+func TestQueueRetention(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	setEnvVars()
+	ctx, cancel := context.WithCancel(context.Background())
+	platform, err := newPlatformWithQueueOption(ctx, *env.Duration("dial.timeout"), queuectl.Option{Dir: t.TempDir(), ExpirationInterval: 10 * time.Millisecond})
+	test.Nil(t, err)
+	svcGroup := test.NewServiceGroup()
+	cli, err := newTestClient(ctx, platform.conn, platform.conn)
+	defer func() {
+		cancel()
+		cleanUp(t, platform, svcGroup, cli)
+	}()
+	test.Nil(t, err)
+	err = svcGroup.Start(platform.ctl, platform.queue)
+	test.Nil(t, err)
+	pkg := newTestPackage()
+	q := pkg.Queues[0].ID
+	dlq := "queue_id_1_dlq"
+	ttl := uint32(1)
+	deadLetterExpired := true
+	pkg.Queues[0].MessageTTLSeconds = &ttl
+	pkg.Queues[0].DeadLetterExpired = &deadLetterExpired
+	pkg.Queues[0].DeadLetterQueue = &dlq
+	maxLength := uint32(2)
+	dropOldest := pb.QueueDef_DropOldest
+	pkg.Queues = append(pkg.Queues,
+		&pb.QueueDef{ID: dlq},
+		&pb.QueueDef{ID: "rejecting", MaxLength: &maxLength},
+		&pb.QueueDef{ID: "dropping", MaxLength: &maxLength, Overflow: &dropOldest})
+	addPackage(t, cli, pkg)
+	event := pkg.Jobs[0].Event.ID
+	queue := func(queue string, n int) error {
+		_, err := cli.queue.Queue(ctx, &pb.QueueRequest{
+			Tenant: pkg.Tenant,
+			Queue:  queue,
+			Items:  []*pb.QueueItem{{Event: event, Data: []byte(fmt.Sprintf("{\"n\":%d}", n))}},
+		})
+		return err
+	}
+	for n := 1; n <= 3; n++ {
+		test.Nil(t, queue("dropping", n))
+	}
+	items, err := cli.queue.Dequeue(ctx, pkg.Tenant, "dropping")
+	test.Nil(t, err)
+	test.Len(t, items, 2)
+	test.Equals(t, string(items[0].Item.Data), "{\"n\":2}")
+	test.Equals(t, string(items[1].Item.Data), "{\"n\":3}")
+	test.Nil(t, queue("rejecting", 1))
+	test.Nil(t, queue("rejecting", 2))
+	test.NotNil(t, queue("rejecting", 3))
+	stats, err := cli.queue.Stats(ctx, pkg.Tenant, "rejecting")
+	test.Nil(t, err)
+	test.Equals(t, stats[0].Depth, uint32(2))
+	test.Nil(t, queue(q, 1))
+	var dls []*pb.DeadLetter
+	for i := 0; i < 100 && len(dls) == 0; i++ {
+		time.Sleep(50 * time.Millisecond)
+		dls, err = cli.queue.DeadLetters(ctx, pkg.Tenant, dlq, 10)
+		test.Nil(t, err)
+	}
+	test.Len(t, dls, 1)
+	test.Equals(t, dls[0].SourceQueue, q)
+	test.Equals(t, dls[0].Failures[0].Reason, "expired")
+	items, err = cli.queue.Dequeue(ctx, pkg.Tenant, q)
+	test.Nil(t, err)
+	test.Empty(t, items)
+}
+
+func cleanUp(t *testing.T, platform *platform, svcGroup *test.ServiceGroup, cli *testClient) {
+	fail := false
+	if err := svcGroup.WaitUntilStopped(); err != nil {
+		errw := test.ErrService{}
+		if errors.As(err, &errw) {
+			t.Errorf("error while stopping the service %s: %v", errw.Starter.Addr(), errw.Err)
+		} else {
+			t.Errorf("error while stopping %v", err)
+		}
+		fail = true
+	}
+	if cli != nil {
+		if err := cli.close(); err != nil {
+			t.Errorf("error stopping service group %v", err)
+			fail = true
+		}
+	}
+	err := platform.dispose()
+	if err != nil {
+		t.Errorf("error disposing services %v", err)
+		fail = true
+	}
+	if fail {
+		t.FailNow()
+	}
+}
+
+// waitForLimit waits for the violation of a limit. The recorder keeps the executions of every tenant
+// together, so the violation is looked up by its description.
+func waitForLimit(t *testing.T, cli *testClient, pkg *pb.JobPackage, desc string) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		results, err := cli.getJobExecutions(pkg, 10)
+		test.Nil(t, err)
+		for _, r := range results {
+			if r.TypeResult == strings.ToLower(pb.JobResult_Limit.String()) && r.ResultString == desc {
+				return
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
 	}
+	t.Fatalf("limit %q not recorded for %s", desc, pkg.Tenant)
 }
 
 func addPackageAndFiles(t *testing.T, cli *testClient, pkg *pb.JobPackage) {
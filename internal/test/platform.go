@@ -55,6 +55,10 @@ func newPlatform(ctx context.Context) (*platform, error) {
 }
 
 func newPlatformWithTimeout(ctx context.Context, dur time.Duration) (*platform, error) {
+	return newPlatformWithQueueOption(ctx, dur, queuectl.Option{InMemory: true})
+}
+
+func newPlatformWithQueueOption(ctx context.Context, dur time.Duration, queueOption queuectl.Option) (*platform, error) {
 	conn := service.NewBufConnWithTimeout(dur)
 	ctl, err := ctl.New(ctx,
 		ctl.WithGrpcConn(service.GrpcConn{
@@ -69,7 +73,7 @@ func newPlatformWithTimeout(ctx context.Context, dur time.Duration) (*platform,
 		service.GrpcConn{
 			Listener: conn,
 			Dialer:   conn,
-		}), queue.WithOption(queuectl.Option{InMemory: true}))
+		}), queue.WithOption(queueOption))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,40 @@
+;; emitter.wasm: the event handler emits its event twice to event_emitted, once to an unknown
+;; event and once an invalid event to event_emitted. The result code is the status of the last
+;; two emits as 10*unknown+invalid and the result string is empty.
+;;
+;; Rebuild it with: wat2wasm emitter.wat -o emitter.wasm
+(module
+  (import "env" "emit" (func $emit (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (global $heap (mut i32) (i32.const 1024))
+
+  ;; "event_emitted" at 16, "unknown" at 29 and "{}" at 36.
+  (data (i32.const 16) "event_emitted" "unknown" "{}")
+
+  (func (export "init"))
+
+  ;; malloc is a bump allocator aligned to 8 bytes that never frees.
+  (func (export "malloc") (param $size i32) (result i32)
+    global.get $heap
+    global.get $heap
+    local.get $size
+    i32.add
+    i32.const 7
+    i32.add
+    i32.const -8
+    i32.and
+    global.set $heap)
+
+  (func (export "free") (param i32))
+
+  ;; event stores {code uint64, string pointer uint64} at $result.
+  (func (export "event") (param $result i32) (param $ptr i32) (param $len i32)
+    (drop (call $emit (i32.const 16) (i32.const 13) (local.get $ptr) (local.get $len)))
+    (drop (call $emit (i32.const 16) (i32.const 13) (local.get $ptr) (local.get $len)))
+    local.get $result
+    (i32.mul (call $emit (i32.const 29) (i32.const 7) (local.get $ptr) (local.get $len)) (i32.const 10))
+    (call $emit (i32.const 16) (i32.const 13) (i32.const 36) (i32.const 2))
+    i32.add
+    i64.extend_i32_u
+    i64.store
+    (i64.store offset=8 (local.get $result) (i64.const 0))))